@@ -10,9 +10,11 @@ import (
 
 	"github.com/yusuffugurlu/go-project/internal/cache"
 	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/fx"
 	"github.com/yusuffugurlu/go-project/internal/process"
 	"github.com/yusuffugurlu/go-project/internal/routes"
 	"github.com/yusuffugurlu/go-project/internal/server"
+	"github.com/yusuffugurlu/go-project/pkg/money"
 	"github.com/yusuffugurlu/go-project/pkg/validator"
 )
 
@@ -22,13 +24,15 @@ func main() {
 
 	logger.InitializeLogger()
 	cfg := config.InitializeConfig()
+	if err := money.SetSupportedCurrencies(cfg.SupportedCurrencies); err != nil {
+		logger.Log.Fatal("Invalid SUPPORTED_CURRENCIES", err)
+	}
 	database.InitializeDb()
 
 	redisClient := cache.NewRedisClient(cfg)
 	if redisClient == nil {
 		logger.Log.Fatal("Failed to initialize Redis client")
 	}
-	defer redisClient.Close()
 
 	cacheService := cache.NewCacheService(redisClient)
 	warmupService := cache.NewWarmupService(cacheService)
@@ -44,8 +48,58 @@ func main() {
 		warmupService.ScheduleWarmup(1 * time.Hour)
 	}()
 
-	process.InitWorkerPool(10)
+	rateProvider, err := fx.NewRateProvider(fx.ProviderOptions{
+		Backend:     cfg.FxProvider,
+		RatesFile:   cfg.FxRatesFile,
+		HTTPURL:     cfg.FxHTTPURL,
+		HTTPTimeout: cfg.FxHTTPTimeout,
+		CacheTTL:    cfg.FxRateCacheTTL,
+	}, database.Db, cacheService)
+	if err != nil {
+		logger.Log.Fatal("Failed to initialize exchange rate provider", err)
+	}
+
+	queue, err := process.NewQueue(process.QueueOptions{
+		Backend:           cfg.QueueBackend,
+		Size:              cfg.QueueSize,
+		VisibilityTimeout: cfg.QueueVisibilityTimeout,
+		PollInterval:      cfg.QueuePollInterval,
+	}, database.Db, redisClient.Client())
+	if err != nil {
+		logger.Log.Fatal("Failed to initialize job queue", err)
+	}
+
+	retryPolicy := process.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.JobMaxAttempts
+	retryPolicy.InitialBackoff = cfg.JobRetryInitialBackoff
+	retryPolicy.MaxBackoff = cfg.JobRetryMaxBackoff
+
+	workerPool := process.NewWorkerPool(queue, retryPolicy, process.WorkerPoolOptions{
+		Workers: cfg.WorkerCount,
+		Pacing:  cfg.JobPacing,
+		Autoscale: process.AutoscaleOptions{
+			Enabled:       cfg.WorkerAutoscale,
+			MinWorkers:    cfg.WorkerMinCount,
+			MaxWorkers:    cfg.WorkerMaxCount,
+			JobsPerWorker: cfg.WorkerJobsPerWorker,
+			Interval:      cfg.WorkerScaleInterval,
+		},
+	})
+	workerPool.Start(context.Background())
 
-	routes.InitRoutes(e, cacheService)
+	scheduler := process.NewScheduler(workerPool, cfg.SchedulerInterval)
+	scheduler.Start(context.Background())
+
+	routes.InitRoutes(e, cfg, cacheService, workerPool, rateProvider)
 	server.StartServer(e)
+
+	server.WaitForShutdown(cfg.ShutdownTimeout,
+		server.ShutdownStep{Name: "HTTP server", Stop: e.Shutdown},
+		server.ShutdownStep{Name: "transaction scheduler", Stop: scheduler.Stop},
+		server.ShutdownStep{Name: "worker pool", Stop: workerPool.Stop},
+		server.ShutdownStep{Name: "cache warm-up scheduler", Stop: warmupService.Stop},
+		server.ShutdownStep{Name: "job queue", Stop: func(ctx context.Context) error { return queue.Close() }},
+		server.ShutdownStep{Name: "Redis client", Stop: func(ctx context.Context) error { return redisClient.Close() }},
+		server.ShutdownStep{Name: "database", Stop: func(ctx context.Context) error { return database.Close() }},
+	)
 }
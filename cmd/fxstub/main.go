@@ -0,0 +1,69 @@
+// Command fxstub serves the rates of a rates file through the exchange rate API the http
+// rate provider expects, so that provider can be run against a local stand-in:
+//
+//	go run ./cmd/fxstub -addr :8090 -rates build/fx/rates.json
+//	FX_PROVIDER=http FX_HTTP_URL=http://localhost:8090 go run cmd/app/main.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/yusuffugurlu/go-project/internal/fx"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "address to listen on")
+	ratesFile := flag.String("rates", "build/fx/rates.json", "rates file to serve")
+	flag.Parse()
+
+	table, err := fx.LoadRateTable(*ratesFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	http.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		base := strings.ToUpper(r.URL.Query().Get("base"))
+		if base == "" {
+			base = table.Base
+		}
+
+		symbols := strings.Split(strings.ToUpper(r.URL.Query().Get("symbols")), ",")
+		if symbols[0] == "" {
+			symbols = []string{table.Base}
+			for currency := range table.Rates {
+				symbols = append(symbols, currency)
+			}
+		}
+
+		response := fx.RateTable{Base: base, Date: table.Date, Rates: map[string]json.Number{}}
+		for _, symbol := range symbols {
+			if symbol == base {
+				continue
+			}
+			rate, err := table.Lookup(base, symbol)
+			if errors.Is(err, fx.ErrRateNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			response.Rates[symbol] = json.Number(rate)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	log.Printf("serving %s rates from %s on %s", table.Base, *ratesFile, *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -2,17 +2,48 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/yusuffugurlu/go-project/config/logger"
 )
 
 type Config struct {
-	AppPort               string
-	AppName               string
-	DatabaseConnectionURL string
-	RedisURL              string
-	RedisPassword         string
+	AppPort                string
+	AppName                string
+	DatabaseConnectionURL  string
+	RedisURL               string
+	RedisPassword          string
+	IdempotencyKeyTTL      time.Duration
+	QueueBackend           string
+	QueueVisibilityTimeout time.Duration
+	QueuePollInterval      time.Duration
+	JobMaxAttempts         int
+	JobRetryInitialBackoff time.Duration
+	JobRetryMaxBackoff     time.Duration
+	ShutdownTimeout        time.Duration
+	WorkerCount            int
+	QueueSize              int
+	JobPacing              time.Duration
+	WorkerAutoscale        bool
+	WorkerMinCount         int
+	WorkerMaxCount         int
+	WorkerJobsPerWorker    int
+	WorkerScaleInterval    time.Duration
+	SchedulerInterval      time.Duration
+	HoldDefaultTTL         time.Duration
+	HoldMaxTTL             time.Duration
+	PaymentRequestTTL      time.Duration
+	PaymentRequestMaxTTL   time.Duration
+	SupportedCurrencies    []string
+	FxProvider             string
+	FxRatesFile            string
+	FxHTTPURL              string
+	FxHTTPTimeout          time.Duration
+	FxRateCacheTTL         time.Duration
+	FxQuoteTTL             time.Duration
 }
 
 func InitializeConfig() *Config {
@@ -27,6 +58,10 @@ func InitializeConfig() *Config {
 		DatabaseConnectionURL: os.Getenv("DATABASE_CONNECTION_URL"),
 		RedisURL:              os.Getenv("REDIS_URL"),
 		RedisPassword:         os.Getenv("REDIS_PASSWORD"),
+		QueueBackend:          os.Getenv("QUEUE_BACKEND"),
+		FxProvider:            os.Getenv("FX_PROVIDER"),
+		FxRatesFile:           os.Getenv("FX_RATES_FILE"),
+		FxHTTPURL:             os.Getenv("FX_HTTP_URL"),
 	}
 
 	if config.AppPort == "" {
@@ -39,7 +74,112 @@ func InitializeConfig() *Config {
 		config.RedisURL = "localhost:6379"
 	}
 
+	if config.QueueBackend == "" {
+		logger.Log.Warn("QUEUE_BACKEND not set, defaulting to postgres")
+		config.QueueBackend = "postgres"
+	}
+
+	if config.FxProvider == "" {
+		logger.Log.Warn("FX_PROVIDER not set, defaulting to database")
+		config.FxProvider = "database"
+	}
+
+	if config.FxRatesFile == "" {
+		config.FxRatesFile = "build/fx/rates.json"
+	}
+
+	config.IdempotencyKeyTTL = durationFromEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	config.QueueVisibilityTimeout = durationFromEnv("QUEUE_VISIBILITY_TIMEOUT", 30*time.Second)
+	config.QueuePollInterval = durationFromEnv("QUEUE_POLL_INTERVAL", 500*time.Millisecond)
+	config.JobMaxAttempts = intFromEnv("JOB_MAX_ATTEMPTS", 5)
+	config.JobRetryInitialBackoff = durationFromEnv("JOB_RETRY_INITIAL_BACKOFF", 1*time.Second)
+	config.JobRetryMaxBackoff = durationFromEnv("JOB_RETRY_MAX_BACKOFF", 1*time.Minute)
+	config.ShutdownTimeout = durationFromEnv("SHUTDOWN_TIMEOUT", 30*time.Second)
+	config.WorkerCount = intFromEnv("WORKER_COUNT", 10)
+	config.QueueSize = intFromEnv("QUEUE_SIZE", 100)
+	config.JobPacing = durationFromEnv("JOB_PACING", 0)
+	config.WorkerAutoscale = boolFromEnv("WORKER_AUTOSCALE", false)
+	config.WorkerMinCount = intFromEnv("WORKER_MIN_COUNT", 1)
+	config.WorkerMaxCount = intFromEnv("WORKER_MAX_COUNT", 50)
+	config.WorkerJobsPerWorker = intFromEnv("WORKER_JOBS_PER_WORKER", 10)
+	config.WorkerScaleInterval = durationFromEnv("WORKER_SCALE_INTERVAL", 10*time.Second)
+	config.SchedulerInterval = durationFromEnv("SCHEDULER_INTERVAL", 10*time.Second)
+	config.HoldDefaultTTL = durationFromEnv("HOLD_DEFAULT_TTL", 7*24*time.Hour)
+	config.HoldMaxTTL = durationFromEnv("HOLD_MAX_TTL", 30*24*time.Hour)
+	config.PaymentRequestTTL = durationFromEnv("PAYMENT_REQUEST_TTL", 7*24*time.Hour)
+	config.PaymentRequestMaxTTL = durationFromEnv("PAYMENT_REQUEST_MAX_TTL", 30*24*time.Hour)
+	config.SupportedCurrencies = listFromEnv("SUPPORTED_CURRENCIES", []string{"USD", "EUR", "TRY"})
+	config.FxHTTPTimeout = durationFromEnv("FX_HTTP_TIMEOUT", 5*time.Second)
+	config.FxRateCacheTTL = durationFromEnv("FX_RATE_CACHE_TTL", 1*time.Minute)
+	config.FxQuoteTTL = durationFromEnv("FX_QUOTE_TTL", 30*time.Second)
+
 	logger.Log.Info("Config initialized using os.Getenv and godotenv")
 
 	return config
 }
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Log.Warnf("Invalid %s %q, defaulting to %s", key, value, fallback)
+		return fallback
+	}
+
+	return duration
+}
+
+func intFromEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Log.Warnf("Invalid %s %q, defaulting to %d", key, value, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+func boolFromEnv(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		logger.Log.Warnf("Invalid %s %q, defaulting to %t", key, value, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+func listFromEnv(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 {
+		logger.Log.Warnf("Invalid %s %q, defaulting to %v", key, value, fallback)
+		return fallback
+	}
+
+	return items
+}
@@ -35,6 +35,14 @@ func (c *CacheService) SetJSON(ctx context.Context, key string, value interface{
 	return c.redisClient.Set(ctx, key, jsonData, expiration)
 }
 
+func (c *CacheService) SetJSONNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return c.redisClient.SetNX(ctx, key, jsonData, expiration)
+}
+
 func (c *CacheService) GetJSON(ctx context.Context, key string, dest interface{}) error {
 	jsonData, err := c.redisClient.Get(ctx, key)
 	if err != nil {
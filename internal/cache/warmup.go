@@ -2,18 +2,23 @@ package cache
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/pkg/money"
 )
 
 type WarmupService struct {
 	cacheService *CacheService
+	stop         chan struct{}
+	stopOnce     sync.Once
 }
 
 func NewWarmupService(cacheService *CacheService) *WarmupService {
 	return &WarmupService{
 		cacheService: cacheService,
+		stop:         make(chan struct{}),
 	}
 }
 
@@ -69,7 +74,7 @@ func (w *WarmupService) WarmupFrequentlyAccessedData(ctx context.Context) error
 		},
 		{
 			Key:        "system:currencies",
-			Value:      []string{"USD", "EUR", "TRY"},
+			Value:      money.SupportedCurrencies(),
 			Expiration: 12 * time.Hour,
 		},
 	}
@@ -80,8 +85,11 @@ func (w *WarmupService) WarmupFrequentlyAccessedData(ctx context.Context) error
 func (w *WarmupService) ScheduleWarmup(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	go func() {
+		defer ticker.Stop()
 		for {
 			select {
+			case <-w.stop:
+				return
 			case <-ticker.C:
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 				if err := w.WarmupFrequentlyAccessedData(ctx); err != nil {
@@ -92,3 +100,8 @@ func (w *WarmupService) ScheduleWarmup(interval time.Duration) {
 		}
 	}()
 }
+
+func (w *WarmupService) Stop(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+	return nil
+}
@@ -2,17 +2,27 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
 	"github.com/yusuffugurlu/go-project/internal/services"
 	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
 	"github.com/yusuffugurlu/go-project/pkg/jwt"
 	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
 )
 
 type BalanceController interface {
 	GetCurrentBalance(e echo.Context) error
+	GetAll(e echo.Context) error
+	GetAccount(e echo.Context) error
+	Open(e echo.Context) error
+	SetDefault(e echo.Context) error
+	Close(e echo.Context) error
+	SetOverdraft(e echo.Context) error
 	GetHistoricalBalances(e echo.Context) error
+	GetBalanceAsOf(e echo.Context) error
 }
 
 type balanceController struct {
@@ -31,7 +41,129 @@ func (b *balanceController) GetCurrentBalance(e echo.Context) error {
 		return appErrors.NewUnauthorized(nil, "invalid user context")
 	}
 
-	balance, err := b.service.GetUserBalance(uint(userClaims.Id))
+	accountID, err := accountIDParam(e)
+	if err != nil {
+		return err
+	}
+
+	balance, err := b.service.GetUserBalance(uint(userClaims.Id), e.QueryParam("currency"), accountID)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, balance)
+}
+
+func (b *balanceController) GetAll(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	balances, err := b.service.ListUserBalances(uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, balances)
+}
+
+func (b *balanceController) Open(e echo.Context) error {
+	var req dtos.OpenBalanceRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	balance, err := b.service.OpenBalance(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, balance)
+}
+
+func (b *balanceController) GetAccount(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid account id")
+	}
+
+	balance, err := b.service.GetAccount(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, balance)
+}
+
+func (b *balanceController) SetDefault(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid account id")
+	}
+
+	balance, err := b.service.SetDefaultAccount(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, balance)
+}
+
+func (b *balanceController) Close(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid account id")
+	}
+
+	balance, err := b.service.CloseAccount(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, balance)
+}
+
+func (b *balanceController) SetOverdraft(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid account id")
+	}
+
+	var req dtos.OverdraftRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	balance, err := b.service.SetOverdraft(uint(id), req)
 	if err != nil {
 		return err
 	}
@@ -45,10 +177,57 @@ func (b *balanceController) GetHistoricalBalances(e echo.Context) error {
 		return appErrors.NewUnauthorized(nil, "invalid user context")
 	}
 
-	historicalBalances, err := b.service.GetHistoricalBalances(uint(userClaims.Id))
+	accountID, err := accountIDParam(e)
+	if err != nil {
+		return err
+	}
+
+	historicalBalances, err := b.service.GetHistoricalBalances(
+		uint(userClaims.Id),
+		e.QueryParam("currency"),
+		accountID,
+		e.QueryParam("from"),
+		e.QueryParam("to"),
+		e.QueryParam("interval"),
+	)
 	if err != nil {
 		return err
 	}
 
 	return response.Success(e, http.StatusOK, historicalBalances)
 }
+
+func (b *balanceController) GetBalanceAsOf(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	accountID, err := accountIDParam(e)
+	if err != nil {
+		return err
+	}
+
+	balance, err := b.service.GetBalanceAsOf(uint(userClaims.Id), e.QueryParam("currency"), accountID, e.QueryParam("date"))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, balance)
+}
+
+// accountIDParam reads the optional account_id query parameter.
+func accountIDParam(e echo.Context) (*uint, error) {
+	value := e.QueryParam("account_id")
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, appErrors.NewBadRequest(err, "invalid account_id")
+	}
+
+	accountID := uint(id)
+	return &accountID, nil
+}
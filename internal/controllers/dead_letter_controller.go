@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+)
+
+type DeadLetterController interface {
+	GetAll(e echo.Context) error
+	GetByID(e echo.Context) error
+	Requeue(e echo.Context) error
+	Discard(e echo.Context) error
+}
+
+type deadLetterController struct {
+	deadLetterService services.DeadLetterService
+}
+
+func NewDeadLetterController(deadLetterService services.DeadLetterService) DeadLetterController {
+	return &deadLetterController{deadLetterService: deadLetterService}
+}
+
+func (d *deadLetterController) GetAll(e echo.Context) error {
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 50 // default for admin
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	deadLetters, err := d.deadLetterService.GetDeadLetters(limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, deadLetters)
+}
+
+func (d *deadLetterController) GetByID(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid dead letter id")
+	}
+
+	deadLetter, err := d.deadLetterService.GetDeadLetter(uint(id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, deadLetter)
+}
+
+func (d *deadLetterController) Requeue(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid dead letter id")
+	}
+
+	job, err := d.deadLetterService.Requeue(uint(id))
+	if err != nil {
+		return err
+	}
+
+	return response.Accepted(e, jobLocation(job.ID), job)
+}
+
+func (d *deadLetterController) Discard(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid dead letter id")
+	}
+
+	if err := d.deadLetterService.Discard(uint(id)); err != nil {
+		return err
+	}
+
+	return response.NoContent(e)
+}
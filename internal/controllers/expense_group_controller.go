@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type ExpenseGroupController interface {
+	Create(e echo.Context) error
+	GetAll(e echo.Context) error
+	GetByID(e echo.Context) error
+	AddMember(e echo.Context) error
+	RemoveMember(e echo.Context) error
+	AddExpense(e echo.Context) error
+	GetExpenses(e echo.Context) error
+	DeleteExpense(e echo.Context) error
+	GetBalances(e echo.Context) error
+	GetSettlements(e echo.Context) error
+	SettleUp(e echo.Context) error
+}
+
+type expenseGroupController struct {
+	groupService services.ExpenseGroupService
+}
+
+func NewExpenseGroupController(groupService services.ExpenseGroupService) ExpenseGroupController {
+	return &expenseGroupController{groupService: groupService}
+}
+
+func (g *expenseGroupController) Create(e echo.Context) error {
+	var req dtos.ExpenseGroupRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	group, err := g.groupService.Create(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, group)
+}
+
+func (g *expenseGroupController) GetAll(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	groups, err := g.groupService.ListForUser(uint(userClaims.Id), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, groups)
+}
+
+func (g *expenseGroupController) GetByID(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	group, err := g.groupService.GetForUser(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, group)
+}
+
+func (g *expenseGroupController) AddMember(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	var req dtos.GroupMemberRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	group, err := g.groupService.AddMember(uint(id), uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, group)
+}
+
+func (g *expenseGroupController) RemoveMember(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	memberID, err := strconv.ParseUint(e.Param("userId"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid user id")
+	}
+
+	if err := g.groupService.RemoveMember(uint(id), uint(userClaims.Id), uint(memberID)); err != nil {
+		return err
+	}
+
+	return response.NoContent(e)
+}
+
+func (g *expenseGroupController) AddExpense(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	var req dtos.ExpenseRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	expense, err := g.groupService.AddExpense(uint(id), uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, expense)
+}
+
+func (g *expenseGroupController) GetExpenses(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	expenses, err := g.groupService.ListExpenses(uint(id), uint(userClaims.Id), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, expenses)
+}
+
+func (g *expenseGroupController) DeleteExpense(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	expenseID, err := strconv.ParseUint(e.Param("expenseId"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid expense id")
+	}
+
+	if err := g.groupService.DeleteExpense(uint(id), uint(userClaims.Id), uint(expenseID)); err != nil {
+		return err
+	}
+
+	return response.NoContent(e)
+}
+
+func (g *expenseGroupController) GetBalances(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	balances, err := g.groupService.GetBalances(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, balances)
+}
+
+func (g *expenseGroupController) GetSettlements(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	settlements, err := g.groupService.ListSettlements(uint(id), uint(userClaims.Id), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, settlements)
+}
+
+// SettleUp pays everything the user owes in the group.
+func (g *expenseGroupController) SettleUp(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid group id")
+	}
+
+	settlements, err := g.groupService.SettleUp(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, settlements)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type FeeController interface {
+	Quote(e echo.Context) error
+	CreateRule(e echo.Context) error
+	GetRules(e echo.Context) error
+	DeactivateRule(e echo.Context) error
+	GetTotals(e echo.Context) error
+}
+
+type feeController struct {
+	feeService services.FeeService
+}
+
+func NewFeeController(feeService services.FeeService) FeeController {
+	return &feeController{feeService: feeService}
+}
+
+func (f *feeController) Quote(e echo.Context) error {
+	var req dtos.FeeQuoteRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	quote, err := f.feeService.Quote(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, quote)
+}
+
+func (f *feeController) CreateRule(e echo.Context) error {
+	var req dtos.FeeRuleRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	rule, err := f.feeService.CreateRule(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, rule)
+}
+
+func (f *feeController) GetRules(e echo.Context) error {
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	activeOnly := e.QueryParam("active") == "true"
+
+	rules, err := f.feeService.ListRules(activeOnly, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, rules)
+}
+
+func (f *feeController) DeactivateRule(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid fee rule id")
+	}
+
+	rule, err := f.feeService.DeactivateRule(uint(id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, rule)
+}
+
+func (f *feeController) GetTotals(e echo.Context) error {
+	totals, err := f.feeService.GetTotals(e.QueryParam("from"), e.QueryParam("to"))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, totals)
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type FxController interface {
+	GetRate(e echo.Context) error
+	CreateQuote(e echo.Context) error
+	GetQuote(e echo.Context) error
+	Convert(e echo.Context) error
+	GetConversions(e echo.Context) error
+	GetConversion(e echo.Context) error
+	PublishRate(e echo.Context) error
+	GetPublishedRates(e echo.Context) error
+}
+
+type fxController struct {
+	fxService services.FxService
+}
+
+func NewFxController(fxService services.FxService) FxController {
+	return &fxController{fxService: fxService}
+}
+
+func (f *fxController) GetRate(e echo.Context) error {
+	base := e.QueryParam("base")
+	quote := e.QueryParam("quote")
+	if base == "" || quote == "" {
+		return appErrors.NewBadRequest(nil, "base and quote currencies are required")
+	}
+
+	rate, err := f.fxService.GetRate(base, quote)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, rate)
+}
+
+func (f *fxController) CreateQuote(e echo.Context) error {
+	var req dtos.FxQuoteRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	quote, err := f.fxService.CreateQuote(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, quote)
+}
+
+func (f *fxController) GetQuote(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid quote id")
+	}
+
+	quote, err := f.fxService.GetQuote(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, quote)
+}
+
+func (f *fxController) Convert(e echo.Context) error {
+	var req dtos.ConversionRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	conversion, err := f.fxService.Convert(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, conversion)
+}
+
+func (f *fxController) GetConversions(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	conversions, err := f.fxService.ListConversions(uint(userClaims.Id), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, conversions)
+}
+
+func (f *fxController) GetConversion(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid conversion id")
+	}
+
+	conversion, err := f.fxService.GetConversion(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, conversion)
+}
+
+func (f *fxController) PublishRate(e echo.Context) error {
+	var req dtos.ExchangeRateRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	rate, err := f.fxService.PublishRate(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, rate)
+}
+
+func (f *fxController) GetPublishedRates(e echo.Context) error {
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	rates, err := f.fxService.ListPublishedRates(limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, rates)
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type HoldController interface {
+	Create(e echo.Context) error
+	GetAll(e echo.Context) error
+	GetByID(e echo.Context) error
+	Capture(e echo.Context) error
+	Release(e echo.Context) error
+}
+
+type holdController struct {
+	holdService services.HoldService
+}
+
+func NewHoldController(holdService services.HoldService) HoldController {
+	return &holdController{holdService: holdService}
+}
+
+func (h *holdController) Create(e echo.Context) error {
+	var req dtos.HoldRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	hold, err := h.holdService.Create(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, hold)
+}
+
+func (h *holdController) GetAll(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	holds, err := h.holdService.ListForUser(uint(userClaims.Id), e.QueryParam("status"), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, holds)
+}
+
+func (h *holdController) GetByID(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid hold id")
+	}
+
+	hold, err := h.holdService.GetForUser(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, hold)
+}
+
+func (h *holdController) Capture(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid hold id")
+	}
+
+	var req dtos.CaptureHoldRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	hold, err := h.holdService.Capture(uint(id), uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, hold)
+}
+
+func (h *holdController) Release(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid hold id")
+	}
+
+	hold, err := h.holdService.Release(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, hold)
+}
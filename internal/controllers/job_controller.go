@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+)
+
+type JobController interface {
+	GetByID(e echo.Context) error
+}
+
+type jobController struct {
+	jobService services.JobService
+}
+
+func NewJobController(jobService services.JobService) JobController {
+	return &jobController{jobService: jobService}
+}
+
+func (j *jobController) GetByID(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid job id")
+	}
+
+	job, err := j.jobService.GetJobForUser(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, job)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+)
+
+type LedgerController interface {
+	CheckInvariants(e echo.Context) error
+	GetPostings(e echo.Context) error
+}
+
+type ledgerController struct {
+	ledgerService services.LedgerService
+}
+
+func NewLedgerController(ledgerService services.LedgerService) LedgerController {
+	return &ledgerController{ledgerService: ledgerService}
+}
+
+func (l *ledgerController) CheckInvariants(e echo.Context) error {
+	report, err := l.ledgerService.CheckInvariants()
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, report)
+}
+
+func (l *ledgerController) GetPostings(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 20 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if offsetStr != "" {
+		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	postings, err := l.ledgerService.GetUserPostings(uint(userClaims.Id), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, postings)
+}
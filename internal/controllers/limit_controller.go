@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type LimitController interface {
+	GetHeadroom(e echo.Context) error
+	GetLimits(e echo.Context) error
+	SetRoleLimit(e echo.Context) error
+	GetUserHeadroom(e echo.Context) error
+	SetUserLimit(e echo.Context) error
+	DeleteUserLimit(e echo.Context) error
+}
+
+type limitController struct {
+	limitService services.LimitService
+}
+
+func NewLimitController(limitService services.LimitService) LimitController {
+	return &limitController{limitService: limitService}
+}
+
+func (l *limitController) GetHeadroom(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	headroom, err := l.limitService.GetHeadroom(uint(userClaims.Id), e.QueryParam("currency"))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, headroom)
+}
+
+func (l *limitController) GetLimits(e echo.Context) error {
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	limits, err := l.limitService.ListLimits(limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, limits)
+}
+
+func (l *limitController) SetRoleLimit(e echo.Context) error {
+	var req dtos.TransactionLimitRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	limit, err := l.limitService.SetRoleLimit(e.Param("role"), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, limit)
+}
+
+func (l *limitController) GetUserHeadroom(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid user id")
+	}
+
+	headroom, err := l.limitService.GetHeadroom(uint(id), e.QueryParam("currency"))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, headroom)
+}
+
+func (l *limitController) SetUserLimit(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid user id")
+	}
+
+	var req dtos.TransactionLimitRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	limit, err := l.limitService.SetUserLimit(uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, limit)
+}
+
+func (l *limitController) DeleteUserLimit(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid user id")
+	}
+
+	if err := l.limitService.DeleteUserLimit(uint(id), e.QueryParam("currency")); err != nil {
+		return err
+	}
+
+	return response.NoContent(e)
+}
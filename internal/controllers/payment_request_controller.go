@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type PaymentRequestController interface {
+	Create(e echo.Context) error
+	GetAll(e echo.Context) error
+	GetByID(e echo.Context) error
+	GetHistory(e echo.Context) error
+	Accept(e echo.Context) error
+	Decline(e echo.Context) error
+	Cancel(e echo.Context) error
+}
+
+type paymentRequestController struct {
+	requestService services.PaymentRequestService
+}
+
+func NewPaymentRequestController(requestService services.PaymentRequestService) PaymentRequestController {
+	return &paymentRequestController{requestService: requestService}
+}
+
+func (p *paymentRequestController) Create(e echo.Context) error {
+	var req dtos.PaymentRequestRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	request, err := p.requestService.Create(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, request)
+}
+
+// GetAll lists the user's payment requests, filtered by the direction (incoming or outgoing)
+// and status query parameters.
+func (p *paymentRequestController) GetAll(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	requests, err := p.requestService.ListForUser(uint(userClaims.Id), e.QueryParam("direction"), e.QueryParam("status"), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, requests)
+}
+
+func (p *paymentRequestController) GetByID(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid payment request id")
+	}
+
+	request, err := p.requestService.GetForUser(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, request)
+}
+
+func (p *paymentRequestController) GetHistory(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid payment request id")
+	}
+
+	history, err := p.requestService.GetHistory(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, history)
+}
+
+func (p *paymentRequestController) Accept(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid payment request id")
+	}
+
+	var req dtos.AcceptPaymentRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	request, err := p.requestService.Accept(uint(id), uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Accepted(e, jobLocation(*request.JobID), request)
+}
+
+func (p *paymentRequestController) Decline(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid payment request id")
+	}
+
+	request, err := p.requestService.Decline(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, request)
+}
+
+func (p *paymentRequestController) Cancel(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid payment request id")
+	}
+
+	request, err := p.requestService.Cancel(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, request)
+}
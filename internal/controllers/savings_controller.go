@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type SavingsController interface {
+	GetProducts(e echo.Context) error
+	GetAccruedInterest(e echo.Context) error
+	CreateProduct(e echo.Context) error
+	GetAllProducts(e echo.Context) error
+	DeactivateProduct(e echo.Context) error
+	SetRate(e echo.Context) error
+	GetRates(e echo.Context) error
+}
+
+type savingsController struct {
+	savingsService services.SavingsService
+}
+
+func NewSavingsController(savingsService services.SavingsService) SavingsController {
+	return &savingsController{savingsService: savingsService}
+}
+
+// GetProducts lists the products savings accounts can be opened with.
+func (s *savingsController) GetProducts(e echo.Context) error {
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	products, err := s.savingsService.ListProducts(true, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, products)
+}
+
+func (s *savingsController) GetAccruedInterest(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid account id")
+	}
+
+	interest, err := s.savingsService.GetAccruedInterest(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, interest)
+}
+
+func (s *savingsController) CreateProduct(e echo.Context) error {
+	var req dtos.SavingsProductRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	product, err := s.savingsService.CreateProduct(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, product)
+}
+
+func (s *savingsController) GetAllProducts(e echo.Context) error {
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	activeOnly := e.QueryParam("active") == "true"
+
+	products, err := s.savingsService.ListProducts(activeOnly, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, products)
+}
+
+func (s *savingsController) DeactivateProduct(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid savings product id")
+	}
+
+	product, err := s.savingsService.DeactivateProduct(uint(id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, product)
+}
+
+func (s *savingsController) SetRate(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid savings product id")
+	}
+
+	var req dtos.InterestRateRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	rate, err := s.savingsService.SetRate(uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, rate)
+}
+
+func (s *savingsController) GetRates(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid savings product id")
+	}
+
+	rates, err := s.savingsService.ListRates(uint(id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, rates)
+}
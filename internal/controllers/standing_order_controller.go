@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type StandingOrderController interface {
+	Create(e echo.Context) error
+	GetAll(e echo.Context) error
+	GetByID(e echo.Context) error
+	GetOccurrences(e echo.Context) error
+	Pause(e echo.Context) error
+	Resume(e echo.Context) error
+	Skip(e echo.Context) error
+	Cancel(e echo.Context) error
+}
+
+type standingOrderController struct {
+	orderService services.StandingOrderService
+}
+
+func NewStandingOrderController(orderService services.StandingOrderService) StandingOrderController {
+	return &standingOrderController{orderService: orderService}
+}
+
+func (s *standingOrderController) Create(e echo.Context) error {
+	var req dtos.StandingOrderRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	order, err := s.orderService.Create(uint(userClaims.Id), req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, order)
+}
+
+func (s *standingOrderController) GetAll(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	orders, err := s.orderService.ListForUser(uint(userClaims.Id), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, orders)
+}
+
+func (s *standingOrderController) GetByID(e echo.Context) error {
+	return s.handle(e, s.orderService.GetForUser)
+}
+
+func (s *standingOrderController) GetOccurrences(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid standing order id")
+	}
+
+	limitStr := e.QueryParam("limit")
+	offsetStr := e.QueryParam("offset")
+
+	limit := 10 // default
+	offset := 0 // default
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	occurrences, err := s.orderService.GetOccurrences(uint(id), uint(userClaims.Id), limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, occurrences)
+}
+
+func (s *standingOrderController) Pause(e echo.Context) error {
+	return s.handle(e, s.orderService.Pause)
+}
+
+func (s *standingOrderController) Resume(e echo.Context) error {
+	return s.handle(e, s.orderService.Resume)
+}
+
+func (s *standingOrderController) Skip(e echo.Context) error {
+	return s.handle(e, s.orderService.Skip)
+}
+
+func (s *standingOrderController) Cancel(e echo.Context) error {
+	return s.handle(e, s.orderService.Cancel)
+}
+
+// handle runs an action on the standing order named in the path on behalf of the current user.
+func (s *standingOrderController) handle(e echo.Context, action func(id uint, userID uint) (*dtos.StandingOrderResponse, error)) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid standing order id")
+	}
+
+	order, err := action(uint(id), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, order)
+}
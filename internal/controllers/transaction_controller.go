@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -19,25 +20,33 @@ type TransactionController interface {
 	Deposit(e echo.Context) error
 	Withdraw(e echo.Context) error
 	Transfer(e echo.Context) error
+	PreviewRecipient(e echo.Context) error
 	Debit(e echo.Context) error
 	GetHistory(e echo.Context) error
 	GetByID(e echo.Context) error
 	GetAllTransactions(e echo.Context) error
+	Reverse(e echo.Context) error
 }
 
 type transactionController struct {
-	service services.TransactionService
+	service     services.TransactionService
+	jobService  services.JobService
+	userService services.UserService
 }
 
-func NewTransactionController() TransactionController {
+func NewTransactionController(jobService services.JobService, userService services.UserService) TransactionController {
 	return &transactionController{
-		service: services.NewTransactionService(),
+		service:     services.NewTransactionService(),
+		jobService:  jobService,
+		userService: userService,
 	}
 }
 
-func NewTransactionControllerWithService(service services.TransactionService) TransactionController {
+func NewTransactionControllerWithService(service services.TransactionService, jobService services.JobService, userService services.UserService) TransactionController {
 	return &transactionController{
-		service: service,
+		service:     service,
+		jobService:  jobService,
+		userService: userService,
 	}
 }
 
@@ -51,18 +60,17 @@ func (t *transactionController) Deposit(e echo.Context) error {
 		return validator.ProcessValidationErrors(err)
 	}
 
-	process.JobQueue <- process.Transaction{
+	job, err := t.jobService.Submit(process.Transaction{
 		Amount: req.Amount,
 		UserId: req.UserId,
 		Type:   process.DepositTransaction,
 		Date:   time.Now(),
+	})
+	if err != nil {
+		return err
 	}
 
-	return response.Success(e, http.StatusOK, map[string]interface{}{
-		"message": "deposit job queued successfully",
-		"amount":  req.Amount,
-		"user_id": req.UserId,
-	})
+	return response.Accepted(e, jobLocation(job.ID), job)
 }
 
 func (t *transactionController) Withdraw(e echo.Context) error {
@@ -75,18 +83,18 @@ func (t *transactionController) Withdraw(e echo.Context) error {
 		return validator.ProcessValidationErrors(err)
 	}
 
-	process.JobQueue <- process.Transaction{
-		Amount: req.Amount,
-		UserId: req.UserId,
-		Type:   process.WithdrawTransaction,
-		Date:   time.Now(),
+	job, err := t.jobService.Submit(process.Transaction{
+		Amount:        req.Amount,
+		UserId:        req.UserId,
+		FromAccountId: req.FromAccountId,
+		Type:          process.WithdrawTransaction,
+		Date:          time.Now(),
+	})
+	if err != nil {
+		return err
 	}
 
-	return response.Success(e, http.StatusOK, map[string]interface{}{
-		"message": "withdraw job queued successfully",
-		"amount":  req.Amount,
-		"user_id": req.UserId,
-	})
+	return response.Accepted(e, jobLocation(job.ID), job)
 }
 
 func (t *transactionController) Transfer(e echo.Context) error {
@@ -104,19 +112,58 @@ func (t *transactionController) Transfer(e echo.Context) error {
 		return appErrors.NewUnauthorized(nil, "invalid user context")
 	}
 
-	process.JobQueue <- process.Transaction{
-		Amount:   float32(req.Amount),
-		UserId:   uint(userClaims.Id),
-		ToUserId: req.ToUserID,
-		Type:     process.TransferTransaction,
-		Date:     time.Now(),
+	// Without a recipient, the transfer moves money between the sender's own accounts.
+	toUserID := req.ToUserID
+	if req.To != "" {
+		if req.ToUserID != 0 {
+			return appErrors.NewBadRequest(nil, "give either to_user_id or to, not both")
+		}
+
+		recipient, err := t.userService.ResolveRecipient(req.To)
+		if err != nil {
+			return err
+		}
+		toUserID = recipient.Id
+	}
+	if toUserID == 0 {
+		toUserID = uint(userClaims.Id)
+	}
+	if toUserID != uint(userClaims.Id) && req.ToAccountID != 0 {
+		return appErrors.NewBadRequest(nil, "to_account_id can only be given for transfers between your own accounts")
+	}
+	if toUserID == uint(userClaims.Id) && req.ToAccountID == 0 {
+		return appErrors.NewBadRequest(nil, "to_account_id is required for transfers between your own accounts")
 	}
 
-	return response.Success(e, http.StatusOK, map[string]interface{}{
-		"message":    "transfer job queued successfully",
-		"amount":     req.Amount,
-		"to_user_id": req.ToUserID,
+	job, err := t.jobService.Submit(process.Transaction{
+		Amount:        req.Amount,
+		UserId:        uint(userClaims.Id),
+		ToUserId:      toUserID,
+		FromAccountId: req.FromAccountID,
+		ToAccountId:   req.ToAccountID,
+		Type:          process.TransferTransaction,
+		Date:          time.Now(),
 	})
+	if err != nil {
+		return err
+	}
+
+	return response.Accepted(e, jobLocation(job.ID), job)
+}
+
+// PreviewRecipient shows who a transfer addressed to the to query parameter would reach.
+func (t *transactionController) PreviewRecipient(e echo.Context) error {
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	preview, err := t.userService.PreviewRecipient(e.QueryParam("to"), uint(userClaims.Id))
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, preview)
 }
 
 func (t *transactionController) GetHistory(e echo.Context) error {
@@ -143,7 +190,12 @@ func (t *transactionController) GetHistory(e echo.Context) error {
 		}
 	}
 
-	transactions, err := t.service.GetTransactionHistory(uint(userClaims.Id), limit, offset)
+	accountID, err := accountIDParam(e)
+	if err != nil {
+		return err
+	}
+
+	transactions, err := t.service.GetTransactionHistory(uint(userClaims.Id), accountID, limit, offset)
 	if err != nil {
 		return err
 	}
@@ -213,15 +265,48 @@ func (t *transactionController) Debit(e echo.Context) error {
 		return appErrors.NewUnauthorized(nil, "invalid user context")
 	}
 
-	process.JobQueue <- process.Transaction{
-		Amount: float32(req.Amount),
-		UserId: uint(userClaims.Id),
-		Type:   process.DebitTransaction,
-		Date:   time.Now(),
+	job, err := t.jobService.Submit(process.Transaction{
+		Amount:      req.Amount,
+		UserId:      uint(userClaims.Id),
+		ToAccountId: req.AccountID,
+		Type:        process.DebitTransaction,
+		Date:        time.Now(),
+	})
+	if err != nil {
+		return err
 	}
 
-	return response.Success(e, http.StatusOK, map[string]interface{}{
-		"message": "debit job queued successfully",
-		"amount":  req.Amount,
-	})
+	return response.Accepted(e, jobLocation(job.ID), job)
+}
+
+func jobLocation(jobID uint) string {
+	return fmt.Sprintf("/api/v1/jobs/%d", jobID)
+}
+
+func (t *transactionController) Reverse(e echo.Context) error {
+	id, err := strconv.ParseUint(e.Param("id"), 10, 32)
+	if err != nil {
+		return appErrors.NewBadRequest(err, "invalid transaction id")
+	}
+
+	var req dtos.ReversalRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	reversal, err := t.service.ReverseTransaction(uint(id), uint(userClaims.Id), userClaims.Role == "admin", req)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(e, reversal)
 }
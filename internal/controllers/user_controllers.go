@@ -9,6 +9,7 @@ import (
 	"github.com/yusuffugurlu/go-project/internal/models"
 	"github.com/yusuffugurlu/go-project/internal/services"
 	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
 	"github.com/yusuffugurlu/go-project/pkg/response"
 	"github.com/yusuffugurlu/go-project/pkg/validator"
 )
@@ -19,6 +20,7 @@ type UserController interface {
 	CreateUser(e echo.Context) error
 	UpdateUser(e echo.Context) error
 	DeleteUser(e echo.Context) error
+	SetHandle(e echo.Context) error
 }
 
 type userController struct {
@@ -115,3 +117,26 @@ func (u *userController) CreateUser(e echo.Context) error {
 
 	return response.Created(e, createdUser)
 }
+
+func (u *userController) SetHandle(e echo.Context) error {
+	var req dtos.SetHandleRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	userClaims, ok := e.Get("user").(*jwt.UserClaims)
+	if !ok {
+		return appErrors.NewUnauthorized(nil, "invalid user context")
+	}
+
+	user, err := u.userService.SetHandle(userClaims.Id, req.Handle)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, user)
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/response"
+	"github.com/yusuffugurlu/go-project/pkg/validator"
+)
+
+type WorkerPoolController interface {
+	GetStatus(e echo.Context) error
+	Update(e echo.Context) error
+}
+
+type workerPoolController struct {
+	workerPoolService services.WorkerPoolService
+}
+
+func NewWorkerPoolController(workerPoolService services.WorkerPoolService) WorkerPoolController {
+	return &workerPoolController{workerPoolService: workerPoolService}
+}
+
+func (w *workerPoolController) GetStatus(e echo.Context) error {
+	return response.Success(e, http.StatusOK, w.workerPoolService.GetStatus())
+}
+
+func (w *workerPoolController) Update(e echo.Context) error {
+	var req dtos.UpdateWorkerPoolRequest
+	if err := e.Bind(&req); err != nil {
+		return appErrors.NewBadRequest(err, "invalid request format")
+	}
+
+	if err := e.Validate(req); err != nil {
+		return validator.ProcessValidationErrors(err)
+	}
+
+	status, err := w.workerPoolService.Update(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Success(e, http.StatusOK, status)
+}
@@ -19,13 +19,65 @@ func InitializeDb() {
 		logger.Log.Fatal("Failed to connect to database ", err)
 	}
 
+	if err := migrateBalancesToPerCurrency(Db); err != nil {
+		logger.Log.Fatal("Failed to migrate balances to per-currency accounts", err)
+	}
+
 	if err := Db.AutoMigrate(
 		&models.User{},
 		&models.Balance{},
 		&models.Transaction{},
-		&models.AuditLog{}); err != nil {
+		&models.AuditLog{},
+		&models.JournalEntry{},
+		&models.Posting{},
+		&models.Job{},
+		&models.QueueMessage{},
+		&models.DeadLetter{},
+		&models.BalanceSnapshot{},
+		&models.ScheduledTransaction{},
+		&models.StandingOrder{},
+		&models.StandingOrderOccurrence{},
+		&models.Hold{},
+		&models.ExchangeRate{},
+		&models.FxQuote{},
+		&models.Conversion{},
+		&models.FeeRule{},
+		&models.TransactionLimit{},
+		&models.SavingsProduct{},
+		&models.InterestRate{},
+		&models.InterestAccrual{},
+		&models.PaymentRequest{},
+		&models.ExpenseGroup{},
+		&models.ExpenseGroupMember{},
+		&models.Expense{},
+		&models.ExpenseShare{},
+		&models.GroupSettlement{}); err != nil {
 		logger.Log.Fatal("Failed to migrate database", err)
 	}
 
+	if err := migrateLegacyFloatAmounts(Db); err != nil {
+		logger.Log.Fatal("Failed to migrate legacy amounts", err)
+	}
+
+	if err := backfillOpeningBalances(Db); err != nil {
+		logger.Log.Fatal("Failed to backfill opening ledger balances", err)
+	}
+
+	if err := backfillBalanceSnapshots(Db); err != nil {
+		logger.Log.Fatal("Failed to backfill balance snapshots", err)
+	}
+
+	if err := migrateBalancesToAccounts(Db); err != nil {
+		logger.Log.Fatal("Failed to migrate balances to named accounts", err)
+	}
+
 	logger.Log.Info("Database connected and migrated successfully!")
-}
\ No newline at end of file
+}
+
+func Close() error {
+	sqlDb, err := Db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDb.Close()
+}
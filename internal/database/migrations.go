@@ -0,0 +1,176 @@
+package database
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+)
+
+// migrateBalancesToPerCurrency replaces the user_id primary key of balances, which allowed a
+// single balance per user, with a surrogate id so a user can hold one balance per currency.
+// It runs before AutoMigrate, which cannot change primary keys.
+func migrateBalancesToPerCurrency(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Balance{}) || db.Migrator().HasColumn(&models.Balance{}, "id") {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE balances DROP CONSTRAINT IF EXISTS balances_pkey").Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE balances ADD COLUMN id bigserial PRIMARY KEY").Error
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Log.Info("Migrated balances to one row per user and currency")
+	return nil
+}
+
+// migrateLegacyFloatAmounts moves amounts stored in the old float "amount" columns into the
+// integer minor-unit columns and drops the float columns afterwards.
+func migrateLegacyFloatAmounts(db *gorm.DB) error {
+	for _, model := range []interface{}{&models.Balance{}, &models.Transaction{}} {
+		if !db.Migrator().HasColumn(model, "amount") {
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+
+		scale := int64(math.Pow10(money.Exponent(money.DefaultCurrency)))
+		err := db.Transaction(func(tx *gorm.DB) error {
+			query := fmt.Sprintf(
+				"UPDATE %s SET amount_minor = ROUND(amount::numeric * ?)::bigint, amount_currency = ? WHERE amount IS NOT NULL",
+				tx.Statement.Quote(table),
+			)
+			if err := tx.Exec(query, scale, money.DefaultCurrency).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(model, "amount")
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate float amounts of %s: %w", table, err)
+		}
+
+		logger.Log.Infof("Migrated legacy float amounts of %s to minor units", table)
+	}
+
+	return nil
+}
+
+// backfillOpeningBalances posts an opening journal entry for every balance that predates the
+// ledger, so that balances and postings agree from the start.
+func backfillOpeningBalances(db *gorm.DB) error {
+	var balances []models.Balance
+	if err := db.Where("amount_minor <> 0").
+		Where("NOT EXISTS (SELECT 1 FROM postings p WHERE p.account = CONCAT('user:', balances.user_id))").
+		Find(&balances).Error; err != nil {
+		return err
+	}
+
+	for _, balance := range balances {
+		entry := &models.JournalEntry{
+			Type: "opening",
+			Postings: []models.Posting{
+				{Account: models.SystemOpeningAccount, Amount: balance.Amount.Negate()},
+				{Account: models.UserAccount(balance.UserId), Amount: balance.Amount},
+			},
+		}
+
+		if err := db.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to post opening balance for user %d: %w", balance.UserId, err)
+		}
+	}
+
+	if len(balances) > 0 {
+		logger.Log.Infof("Posted opening ledger balances for %d accounts", len(balances))
+	}
+
+	return nil
+}
+
+// backfillBalanceSnapshots reconstructs the balance history of accounts that have postings but
+// no snapshots yet, by replaying their postings as a running total.
+func backfillBalanceSnapshots(db *gorm.DB) error {
+	result := db.Exec(`
+		INSERT INTO balance_snapshots (user_id, amount_minor, amount_currency, version, created_at)
+		SELECT CAST(SUBSTRING(p.account FROM 6) AS bigint),
+			SUM(p.amount_minor) OVER (PARTITION BY p.account, p.amount_currency ORDER BY p.id),
+			p.amount_currency, 0, p.created_at
+		FROM postings p
+		WHERE p.account LIKE 'user:%'
+			AND NOT EXISTS (
+				SELECT 1 FROM balance_snapshots s
+				WHERE s.user_id = CAST(SUBSTRING(p.account FROM 6) AS bigint)
+			)`)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Log.Infof("Reconstructed %d balance snapshots from ledger postings", result.RowsAffected)
+	}
+
+	return nil
+}
+
+// migrateBalancesToAccounts turns the single balance a user had per currency into that
+// currency's default account, points existing transactions, holds and snapshots at it, and
+// replaces the one-balance-per-currency index with one default account per currency and
+// unique names among open accounts. Every step is a no-op once it has run.
+func migrateBalancesToAccounts(db *gorm.DB) error {
+	statements := []string{
+		`UPDATE balances b SET is_default = true
+		WHERE b.closed_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM balances d
+				WHERE d.user_id = b.user_id AND d.amount_currency = b.amount_currency AND d.is_default
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM balances o
+				WHERE o.user_id = b.user_id AND o.amount_currency = b.amount_currency AND o.id <> b.id
+			)`,
+		`UPDATE transactions t SET from_account_id = b.id
+		FROM balances b
+		WHERE t.from_account_id IS NULL AND t.from_user_id IS NOT NULL
+			AND b.user_id = t.from_user_id AND b.amount_currency = t.amount_currency AND b.is_default`,
+		`UPDATE transactions t SET to_account_id = b.id
+		FROM balances b
+		WHERE t.to_account_id IS NULL AND t.to_user_id IS NOT NULL
+			AND t.type <> 'conversion'
+			AND b.user_id = t.to_user_id AND b.amount_currency = t.amount_currency AND b.is_default`,
+		`UPDATE transactions t SET to_account_id = b.id
+		FROM conversions c, balances b
+		WHERE t.to_account_id IS NULL AND t.type = 'conversion' AND c.transaction_id = t.id
+			AND b.user_id = c.to_user_id AND b.amount_currency = c.target_currency AND b.is_default`,
+		`UPDATE holds h SET balance_id = b.id
+		FROM balances b
+		WHERE h.balance_id = 0
+			AND b.user_id = h.user_id AND b.amount_currency = h.amount_currency AND b.is_default`,
+		`UPDATE balance_snapshots s SET balance_id = b.id
+		FROM balances b
+		WHERE s.balance_id IS NULL
+			AND b.user_id = s.user_id AND b.amount_currency = s.amount_currency AND b.is_default`,
+		"DROP INDEX IF EXISTS idx_balances_user_currency",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_balances_user_default ON balances (user_id, amount_currency) WHERE is_default",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_balances_user_name ON balances (user_id, amount_currency, name) WHERE closed_at IS NULL",
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+type ExpenseGroupRequest struct {
+	Name     string `json:"name" validate:"required,max=64"`
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+}
+
+// GroupMemberRequest adds a user, given by id or by To (an email address, username or
+// @handle), to a group.
+type GroupMemberRequest struct {
+	UserID uint   `json:"user_id" validate:"required_without=To"`
+	To     string `json:"to" validate:"max=255"`
+}
+
+type ExpenseGroupResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Currency  string `json:"currency"`
+	CreatedBy uint   `json:"created_by"`
+	Members   []uint `json:"members"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ExpenseRequest records an expense PaidBy (the caller if not given) paid for the group. Split
+// is how it is shared:
+//   - equal: evenly between the users of Shares, or all members without shares
+//   - percentage: by the BasisPoints of each share (1% = 100), adding up to 100%
+//   - exact: by the Amount of each share, adding up to the expense amount
+type ExpenseRequest struct {
+	PaidBy      uint                  `json:"paid_by"`
+	Amount      money.Money           `json:"amount" validate:"required,gt=0"`
+	Description string                `json:"description" validate:"required,max=255"`
+	Split       string                `json:"split" validate:"omitempty,oneof=equal percentage exact"`
+	Shares      []ExpenseShareRequest `json:"shares" validate:"dive"`
+}
+
+type ExpenseShareRequest struct {
+	UserID      uint         `json:"user_id" validate:"required"`
+	BasisPoints int64        `json:"basis_points" validate:"min=0,max=10000"`
+	Amount      *money.Money `json:"amount"`
+}
+
+type ExpenseResponse struct {
+	ID          uint                   `json:"id"`
+	GroupID     uint                   `json:"group_id"`
+	PaidBy      uint                   `json:"paid_by"`
+	Amount      money.Money            `json:"amount"`
+	Description string                 `json:"description"`
+	Split       string                 `json:"split"`
+	Shares      []ExpenseShareResponse `json:"shares"`
+	CreatedBy   uint                   `json:"created_by"`
+	CreatedAt   string                 `json:"created_at"`
+}
+
+type ExpenseShareResponse struct {
+	UserID      uint        `json:"user_id"`
+	Amount      money.Money `json:"amount"`
+	BasisPoints int64       `json:"basis_points,omitempty"`
+}
+
+// GroupBalancesResponse shows what each member is owed (negative if they owe) and the fewest
+// transfers that would settle everyone up.
+type GroupBalancesResponse struct {
+	GroupID  uint                `json:"group_id"`
+	Balances []MemberBalance     `json:"balances"`
+	Debts    []GroupDebtResponse `json:"debts"`
+}
+
+type MemberBalance struct {
+	UserID  uint        `json:"user_id"`
+	Balance money.Money `json:"balance"`
+}
+
+type GroupDebtResponse struct {
+	FromUserID uint        `json:"from_user_id"`
+	ToUserID   uint        `json:"to_user_id"`
+	Amount     money.Money `json:"amount"`
+}
+
+type GroupSettlementResponse struct {
+	ID            uint        `json:"id"`
+	GroupID       uint        `json:"group_id"`
+	FromUserID    uint        `json:"from_user_id"`
+	ToUserID      uint        `json:"to_user_id"`
+	Amount        money.Money `json:"amount"`
+	Status        string      `json:"status"`
+	TransactionID *uint       `json:"transaction_id,omitempty"`
+	CreatedAt     string      `json:"created_at"`
+}
@@ -0,0 +1,67 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+// FeeQuoteRequest asks what fee a transaction of Amount would cost.
+type FeeQuoteRequest struct {
+	Type   string      `json:"type" validate:"required,oneof=transfer withdraw conversion"`
+	Amount money.Money `json:"amount" validate:"required,gt=0"`
+}
+
+type FeeQuoteResponse struct {
+	Type   string      `json:"type"`
+	Amount money.Money `json:"amount"`
+	Fee    money.Money `json:"fee"`
+	Total  money.Money `json:"total"`
+	RuleID *uint       `json:"rule_id,omitempty"`
+}
+
+// FeeTierRequest is one band of a tiered fee. Amounts are decimals in the rule's currency;
+// a tier without UpTo applies to any amount and must come last.
+type FeeTierRequest struct {
+	UpTo        string `json:"up_to"`
+	Flat        string `json:"flat"`
+	BasisPoints int64  `json:"basis_points" validate:"min=0,max=10000"`
+}
+
+// FeeRuleRequest adds a rule to the fee schedule. Amounts are decimals in Currency, and
+// percentages are given in basis points (1% = 100). Without a role the rule applies to
+// everyone who has no rule for their own role.
+type FeeRuleRequest struct {
+	TransactionType string           `json:"transaction_type" validate:"required,oneof=transfer withdraw conversion"`
+	Role            string           `json:"role" validate:"omitempty,oneof=user admin"`
+	Currency        string           `json:"currency" validate:"required,iso4217"`
+	Kind            string           `json:"kind" validate:"required,oneof=flat percentage tiered"`
+	Flat            string           `json:"flat"`
+	BasisPoints     int64            `json:"basis_points" validate:"min=0,max=10000"`
+	Tiers           []FeeTierRequest `json:"tiers" validate:"dive"`
+	Min             string           `json:"min"`
+	Max             string           `json:"max"`
+}
+
+type FeeTierResponse struct {
+	UpTo        *money.Money `json:"up_to,omitempty"`
+	Flat        money.Money  `json:"flat"`
+	BasisPoints int64        `json:"basis_points"`
+}
+
+type FeeRuleResponse struct {
+	ID              uint              `json:"id"`
+	TransactionType string            `json:"transaction_type"`
+	Role            string            `json:"role,omitempty"`
+	Currency        string            `json:"currency"`
+	Kind            string            `json:"kind"`
+	Flat            money.Money       `json:"flat"`
+	BasisPoints     int64             `json:"basis_points"`
+	Tiers           []FeeTierResponse `json:"tiers,omitempty"`
+	Min             money.Money       `json:"min"`
+	Max             *money.Money      `json:"max,omitempty"`
+	Active          bool              `json:"active"`
+	CreatedAt       string            `json:"created_at"`
+}
+
+type FeeTotalResponse struct {
+	TransactionType string      `json:"transaction_type"`
+	Count           int64       `json:"count"`
+	Total           money.Money `json:"total"`
+}
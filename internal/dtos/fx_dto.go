@@ -0,0 +1,58 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+// FxQuoteRequest asks what Amount converts to in ToCurrency.
+type FxQuoteRequest struct {
+	Amount     money.Money `json:"amount" validate:"required,gt=0"`
+	ToCurrency string      `json:"to_currency" validate:"required,iso4217"`
+}
+
+// ConversionRequest executes a quote. Without a recipient the user converts into their own
+// balance in the target currency.
+type ConversionRequest struct {
+	QuoteID  uint `json:"quote_id" validate:"required"`
+	ToUserID uint `json:"to_user_id"`
+}
+
+// ExchangeRateRequest publishes a rate for the database provider, effective from AsOf
+// (RFC 3339) or immediately.
+type ExchangeRateRequest struct {
+	Base  string `json:"base" validate:"required,iso4217"`
+	Quote string `json:"quote" validate:"required,iso4217,nefield=Base"`
+	Rate  string `json:"rate" validate:"required"`
+	AsOf  string `json:"as_of"`
+}
+
+type ExchangeRateResponse struct {
+	Base     string `json:"base"`
+	Quote    string `json:"quote"`
+	Rate     string `json:"rate"`
+	Provider string `json:"provider"`
+	AsOf     string `json:"as_of"`
+}
+
+type FxQuoteResponse struct {
+	ID         uint        `json:"id"`
+	Source     money.Money `json:"source"`
+	Target     money.Money `json:"target"`
+	Rate       string      `json:"rate"`
+	Provider   string      `json:"provider"`
+	Status     string      `json:"status"`
+	ExpiresAt  string      `json:"expires_at"`
+	ExecutedAt string      `json:"executed_at,omitempty"`
+	CreatedAt  string      `json:"created_at"`
+}
+
+type ConversionResponse struct {
+	ID            uint        `json:"id"`
+	TransactionID uint        `json:"transaction_id"`
+	QuoteID       uint        `json:"quote_id"`
+	FromUserID    uint        `json:"from_user_id"`
+	ToUserID      uint        `json:"to_user_id"`
+	Source        money.Money `json:"source"`
+	Target        money.Money `json:"target"`
+	Rate          string      `json:"rate"`
+	Provider      string      `json:"provider"`
+	CreatedAt     string      `json:"created_at"`
+}
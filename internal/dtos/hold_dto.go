@@ -0,0 +1,31 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+type HoldRequest struct {
+	Amount      money.Money `json:"amount" validate:"required,gt=0"`
+	ToUserID    uint        `json:"to_user_id"`
+	AccountID   uint        `json:"account_id"`
+	Description string      `json:"description" validate:"max=255"`
+	TTLSeconds  int         `json:"ttl_seconds" validate:"min=0"`
+}
+
+// CaptureHoldRequest captures all of a hold, or only Amount of it if given.
+type CaptureHoldRequest struct {
+	Amount *money.Money `json:"amount"`
+}
+
+type HoldResponse struct {
+	ID            uint        `json:"id"`
+	AccountID     uint        `json:"account_id"`
+	ToUserID      *uint       `json:"to_user_id,omitempty"`
+	Amount        money.Money `json:"amount"`
+	Fee           money.Money `json:"fee"`
+	Captured      money.Money `json:"captured"`
+	Description   string      `json:"description,omitempty"`
+	Status        string      `json:"status"`
+	ExpiresAt     string      `json:"expires_at"`
+	TransactionID *uint       `json:"transaction_id,omitempty"`
+	ClosedAt      string      `json:"closed_at,omitempty"`
+	CreatedAt     string      `json:"created_at"`
+}
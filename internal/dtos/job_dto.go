@@ -0,0 +1,29 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+type JobResponse struct {
+	ID            uint        `json:"id"`
+	Type          string      `json:"type"`
+	Status        string      `json:"status"`
+	UserID        uint        `json:"user_id"`
+	ToUserID      *uint       `json:"to_user_id,omitempty"`
+	FromAccountID *uint       `json:"from_account_id,omitempty"`
+	ToAccountID   *uint       `json:"to_account_id,omitempty"`
+	Amount        money.Money `json:"amount"`
+	FailureReason string      `json:"failure_reason,omitempty"`
+	TransactionID *uint       `json:"transaction_id,omitempty"`
+	CreatedAt     string      `json:"created_at"`
+	UpdatedAt     string      `json:"updated_at"`
+	StartedAt     *string     `json:"started_at,omitempty"`
+	CompletedAt   *string     `json:"completed_at,omitempty"`
+}
+
+type DeadLetterResponse struct {
+	ID        uint         `json:"id"`
+	JobID     *uint        `json:"job_id,omitempty"`
+	Error     string       `json:"error"`
+	Attempts  int          `json:"attempts"`
+	CreatedAt string       `json:"created_at"`
+	Job       *JobResponse `json:"job,omitempty"`
+}
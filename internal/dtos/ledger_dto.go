@@ -0,0 +1,11 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+type PostingResponse struct {
+	ID             uint        `json:"id"`
+	JournalEntryID uint        `json:"journal_entry_id"`
+	Account        string      `json:"account"`
+	Amount         money.Money `json:"amount"`
+	CreatedAt      string      `json:"created_at"`
+}
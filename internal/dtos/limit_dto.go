@@ -0,0 +1,50 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+// TransactionLimitRequest sets the limits of a role or user in one currency. Amounts are
+// decimals in Currency; empty amounts and zero counts mean unlimited.
+type TransactionLimitRequest struct {
+	Currency      string `json:"currency" validate:"required,iso4217"`
+	MaxSingle     string `json:"max_single"`
+	DailyAmount   string `json:"daily_amount"`
+	MonthlyAmount string `json:"monthly_amount"`
+	DailyCount    int64  `json:"daily_count" validate:"min=0"`
+	MonthlyCount  int64  `json:"monthly_count" validate:"min=0"`
+}
+
+type TransactionLimitResponse struct {
+	ID            uint         `json:"id"`
+	Role          string       `json:"role,omitempty"`
+	UserID        *uint        `json:"user_id,omitempty"`
+	Currency      string       `json:"currency"`
+	MaxSingle     *money.Money `json:"max_single,omitempty"`
+	DailyAmount   *money.Money `json:"daily_amount,omitempty"`
+	MonthlyAmount *money.Money `json:"monthly_amount,omitempty"`
+	DailyCount    int64        `json:"daily_count,omitempty"`
+	MonthlyCount  int64        `json:"monthly_count,omitempty"`
+	UpdatedAt     string       `json:"updated_at"`
+}
+
+// LimitWindowResponse shows what was moved out in a day or month and what is left. Limits
+// and remaining headroom are omitted when unlimited.
+type LimitWindowResponse struct {
+	Used           money.Money  `json:"used"`
+	Count          int64        `json:"count"`
+	Limit          *money.Money `json:"limit,omitempty"`
+	Remaining      *money.Money `json:"remaining,omitempty"`
+	CountLimit     *int64       `json:"count_limit,omitempty"`
+	CountRemaining *int64       `json:"count_remaining,omitempty"`
+	ResetsAt       string       `json:"resets_at"`
+}
+
+// LimitHeadroomResponse shows a user's limits in a currency and how much of them is left.
+// Source is "user" for a personal override, "role" for the role's limits and "none" if the
+// user is unlimited.
+type LimitHeadroomResponse struct {
+	Currency  string              `json:"currency"`
+	Source    string              `json:"source"`
+	MaxSingle *money.Money        `json:"max_single,omitempty"`
+	Daily     LimitWindowResponse `json:"daily"`
+	Monthly   LimitWindowResponse `json:"monthly"`
+}
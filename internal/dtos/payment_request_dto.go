@@ -0,0 +1,43 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+// PaymentRequestRequest asks a user, given by id or by To (an email address, username or
+// @handle), to pay Amount.
+type PaymentRequestRequest struct {
+	PayerID    uint        `json:"payer_id" validate:"required_without=To"`
+	To         string      `json:"to" validate:"max=255"`
+	Amount     money.Money `json:"amount" validate:"required,gt=0"`
+	Note       string      `json:"note" validate:"max=255"`
+	TTLSeconds int         `json:"ttl_seconds" validate:"min=0"`
+}
+
+// AcceptPaymentRequest pays a request from FromAccountID, or from the payer's default account
+// in the request's currency.
+type AcceptPaymentRequest struct {
+	FromAccountID uint `json:"from_account_id"`
+}
+
+type PaymentRequestResponse struct {
+	ID            uint        `json:"id"`
+	RequesterID   uint        `json:"requester_id"`
+	PayerID       uint        `json:"payer_id"`
+	Amount        money.Money `json:"amount"`
+	Note          string      `json:"note,omitempty"`
+	Status        string      `json:"status"`
+	ExpiresAt     string      `json:"expires_at"`
+	FromAccountID *uint       `json:"from_account_id,omitempty"`
+	JobID         *uint       `json:"job_id,omitempty"`
+	JobStatus     string      `json:"job_status,omitempty"`
+	TransactionID *uint       `json:"transaction_id,omitempty"`
+	FailureReason string      `json:"failure_reason,omitempty"`
+	ClosedAt      string      `json:"closed_at,omitempty"`
+	CreatedAt     string      `json:"created_at"`
+}
+
+// PaymentRequestEventResponse is one audit-logged state change of a payment request.
+type PaymentRequestEventResponse struct {
+	Action    string `json:"action"`
+	Details   string `json:"details"`
+	CreatedAt string `json:"created_at"`
+}
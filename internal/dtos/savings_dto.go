@@ -0,0 +1,50 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+// SavingsProductRequest creates a savings product earning AnnualBasisPoints (1% = 100) a year
+// from today.
+type SavingsProductRequest struct {
+	Name              string `json:"name" validate:"required,max=64"`
+	Currency          string `json:"currency" validate:"required,iso4217"`
+	AnnualBasisPoints int64  `json:"annual_basis_points" validate:"min=0,max=10000"`
+}
+
+type SavingsProductResponse struct {
+	ID                uint   `json:"id"`
+	Name              string `json:"name"`
+	Currency          string `json:"currency"`
+	AnnualBasisPoints int64  `json:"annual_basis_points"`
+	Active            bool   `json:"active"`
+	CreatedAt         string `json:"created_at"`
+}
+
+// InterestRateRequest changes a product's rate from EffectiveFrom (YYYY-MM-DD, UTC), which
+// must not be in the past. Without a date the rate applies from today.
+type InterestRateRequest struct {
+	AnnualBasisPoints int64  `json:"annual_basis_points" validate:"min=0,max=10000"`
+	EffectiveFrom     string `json:"effective_from"`
+}
+
+type InterestRateResponse struct {
+	ID                uint   `json:"id"`
+	ProductID         uint   `json:"product_id"`
+	AnnualBasisPoints int64  `json:"annual_basis_points"`
+	EffectiveFrom     string `json:"effective_from"`
+	CreatedAt         string `json:"created_at"`
+}
+
+// AccruedInterestResponse shows the interest an account has accrued and that has not been
+// settled yet. Accrued is rounded to minor units the way it will be paid, and is negative if
+// overdraft interest outweighs interest earned.
+type AccruedInterestResponse struct {
+	AccountID            uint        `json:"account_id"`
+	ProductID            *uint       `json:"product_id,omitempty"`
+	AnnualBasisPoints    int64       `json:"annual_basis_points"`
+	OverdraftBasisPoints int64       `json:"overdraft_basis_points,omitempty"`
+	Accrued              money.Money `json:"accrued"`
+	AccruedDays          int64       `json:"accrued_days"`
+	AccruedSince         string      `json:"accrued_since,omitempty"`
+	AccruedThrough       string      `json:"accrued_through,omitempty"`
+	NextCapitalization   string      `json:"next_capitalization"`
+}
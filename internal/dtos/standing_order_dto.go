@@ -0,0 +1,55 @@
+package dtos
+
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
+// StandingOrderRequest repeats a deposit into to_account_id, a withdrawal from
+// from_account_id or a transfer between them, like ScheduledTransactionRequest.
+type StandingOrderRequest struct {
+	Type           string      `json:"type" validate:"required,oneof=deposit withdraw transfer"`
+	ToUserID       uint        `json:"to_user_id"`
+	FromAccountID  uint        `json:"from_account_id"`
+	ToAccountID    uint        `json:"to_account_id"`
+	Amount         money.Money `json:"amount" validate:"required,gt=0"`
+	Description    string      `json:"description" validate:"max=255"`
+	Recurrence     string      `json:"recurrence" validate:"required"` // RRULE ("FREQ=MONTHLY;BYMONTHDAY=1") or cron ("0 9 1 * *")
+	Timezone       string      `json:"timezone"`
+	StartAt        string      `json:"start_at" validate:"required"`
+	EndAt          string      `json:"end_at"`
+	MaxOccurrences *int        `json:"max_occurrences" validate:"omitempty,min=1"`
+	RetryFailed    bool        `json:"retry_failed"`
+	MaxRetries     int         `json:"max_retries" validate:"min=0,max=7"`
+}
+
+type StandingOrderResponse struct {
+	ID              uint        `json:"id"`
+	Type            string      `json:"type"`
+	ToUserID        *uint       `json:"to_user_id,omitempty"`
+	FromAccountID   *uint       `json:"from_account_id,omitempty"`
+	ToAccountID     *uint       `json:"to_account_id,omitempty"`
+	Amount          money.Money `json:"amount"`
+	Description     string      `json:"description,omitempty"`
+	Recurrence      string      `json:"recurrence"`
+	Timezone        string      `json:"timezone"`
+	StartAt         string      `json:"start_at"`
+	EndAt           string      `json:"end_at,omitempty"`
+	MaxOccurrences  *int        `json:"max_occurrences,omitempty"`
+	OccurrenceCount int         `json:"occurrence_count"`
+	NextRunAt       string      `json:"next_run_at,omitempty"`
+	Status          string      `json:"status"`
+	RetryFailed     bool        `json:"retry_failed"`
+	MaxRetries      int         `json:"max_retries"`
+	CreatedAt       string      `json:"created_at"`
+}
+
+type StandingOrderOccurrenceResponse struct {
+	ID            uint   `json:"id"`
+	Sequence      int    `json:"sequence"`
+	Attempt       int    `json:"attempt"`
+	DueAt         string `json:"due_at"`
+	RunAt         string `json:"run_at"`
+	Status        string `json:"status"`
+	JobID         *uint  `json:"job_id,omitempty"`
+	JobStatus     string `json:"job_status,omitempty"`
+	TransactionID *uint  `json:"transaction_id,omitempty"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
@@ -1,29 +1,50 @@
 package dtos
 
+import "github.com/yusuffugurlu/go-project/pkg/money"
+
 type TransactionRequest struct {
-	Amount float64 `json:"amount" validate:"required"`
-	Type   string  `json:"type" validate:"required"` // "deposit" "withdraw" "transfer"
+	Amount money.Money `json:"amount" validate:"required"`
+	Type   string      `json:"type" validate:"required"` // "deposit" "withdraw" "transfer"
 }
 
 type DebitRequest struct {
-	Amount float64 `json:"amount" validate:"required,gt=0"`
+	Amount    money.Money `json:"amount" validate:"required,gt=0"`
+	AccountID uint        `json:"account_id"`
 }
 
+// TransferRequest moves money to another user or, with only a to_account_id, between the
+// sender's own accounts. The recipient is given by to_user_id or by to, which is an email
+// address, a username or an @handle. Accounts that are not given default to the users'
+// default accounts.
 type TransferRequest struct {
-	ToUserID uint    `json:"to_user_id" validate:"required"`
-	Amount   float64 `json:"amount" validate:"required,gt=0"`
+	ToUserID      uint        `json:"to_user_id" validate:"required_without_all=ToAccountID To"`
+	To            string      `json:"to" validate:"max=255"`
+	FromAccountID uint        `json:"from_account_id"`
+	ToAccountID   uint        `json:"to_account_id"`
+	Amount        money.Money `json:"amount" validate:"required,gt=0"`
+}
+
+// ReversalRequest refunds a transaction. Without an amount the whole remaining amount is refunded.
+type ReversalRequest struct {
+	Amount *money.Money `json:"amount"`
+	Reason string       `json:"reason" validate:"required,max=255"`
 }
 
 type TransactionResponse struct {
-	ID         uint          `json:"id"`
-	FromUserID *uint         `json:"from_user_id,omitempty"`
-	ToUserID   *uint         `json:"to_user_id,omitempty"`
-	Amount     float64       `json:"amount"`
-	Type       string        `json:"type"`
-	Status     string        `json:"status"`
-	CreatedAt  string        `json:"created_at"`
-	FromUser   *UserResponse `json:"from_user,omitempty"`
-	ToUser     *UserResponse `json:"to_user,omitempty"`
+	ID             uint          `json:"id"`
+	FromUserID     *uint         `json:"from_user_id,omitempty"`
+	ToUserID       *uint         `json:"to_user_id,omitempty"`
+	FromAccountID  *uint         `json:"from_account_id,omitempty"`
+	ToAccountID    *uint         `json:"to_account_id,omitempty"`
+	Amount         money.Money   `json:"amount"`
+	Type           string        `json:"type"`
+	Status         string        `json:"status"`
+	ReversalOfID   *uint         `json:"reversal_of_id,omitempty"`
+	FeeOfID        *uint         `json:"fee_of_id,omitempty"`
+	RefundedAmount *money.Money  `json:"refunded_amount,omitempty"`
+	CreatedAt      string        `json:"created_at"`
+	FromUser       *UserResponse `json:"from_user,omitempty"`
+	ToUser         *UserResponse `json:"to_user,omitempty"`
 }
 
 type UserResponse struct {
@@ -33,16 +54,77 @@ type UserResponse struct {
 	Balance  *BalanceResponse `json:"balance,omitempty"`
 }
 
+// OpenBalanceRequest opens an account. Without a name it opens the default account of the
+// currency, or returns it if it already exists. A named savings account can be opened with a
+// savings product to earn its interest.
+type OpenBalanceRequest struct {
+	Currency  string `json:"currency" validate:"required,iso4217"`
+	Name      string `json:"name" validate:"max=64"`
+	Kind      string `json:"kind" validate:"omitempty,oneof=checking savings pocket"`
+	ProductID *uint  `json:"product_id"`
+}
+
+// BalanceResponse shows an account's ledger balance and the part of it that can be spent:
+// what is not held, plus any unused overdraft. Amount equals Ledger and is kept for existing
+// clients.
 type BalanceResponse struct {
-	Amount float64 `json:"amount"`
+	ID        uint            `json:"id"`
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind"`
+	Default   bool            `json:"default"`
+	Status    string          `json:"status"`
+	Currency  string          `json:"currency"`
+	Amount    money.Money     `json:"amount"`
+	Available money.Money     `json:"available"`
+	Ledger    money.Money     `json:"ledger"`
+	Held      money.Money     `json:"held"`
+	ProductID *uint           `json:"product_id,omitempty"`
+	Credit    *CreditResponse `json:"credit,omitempty"`
 }
 
+// CreditResponse shows an account's overdraft: its limit, how much of it is in use and how
+// much can still be drawn.
+type CreditResponse struct {
+	Limit             money.Money `json:"limit"`
+	Used              money.Money `json:"used"`
+	Remaining         money.Money `json:"remaining"`
+	AnnualBasisPoints int64       `json:"annual_basis_points"`
+}
+
+// OverdraftRequest sets the credit line of an account. Limit is a decimal in the account's
+// currency; zero removes the overdraft. Interest is given in basis points a year (1% = 100).
+type OverdraftRequest struct {
+	Limit             string `json:"limit" validate:"required"`
+	AnnualBasisPoints int64  `json:"annual_basis_points" validate:"min=0,max=10000"`
+}
+
+// ScheduledTransactionRequest schedules a deposit into to_account_id, a withdrawal from
+// from_account_id or a transfer between them, like TransferRequest. Accounts that are not
+// given default to the users' default accounts.
 type ScheduledTransactionRequest struct {
-	Amount float64 `json:"amount" validate:"required"`
-	Date   string  `json:"date" validate:"required"`
+	Type          string      `json:"type" validate:"required,oneof=deposit withdraw transfer"`
+	ToUserID      uint        `json:"to_user_id"`
+	FromAccountID uint        `json:"from_account_id"`
+	ToAccountID   uint        `json:"to_account_id"`
+	Amount        money.Money `json:"amount" validate:"required,gt=0"`
+	Date          string      `json:"date" validate:"required"`
+}
+
+type ScheduledTransactionResponse struct {
+	ID            uint        `json:"id"`
+	Type          string      `json:"type"`
+	ToUserID      *uint       `json:"to_user_id,omitempty"`
+	FromAccountID *uint       `json:"from_account_id,omitempty"`
+	ToAccountID   *uint       `json:"to_account_id,omitempty"`
+	Amount        money.Money `json:"amount"`
+	ScheduledAt   string      `json:"scheduled_at"`
+	Status        string      `json:"status"`
+	JobID         *uint       `json:"job_id,omitempty"`
+	JobStatus     string      `json:"job_status,omitempty"`
+	CreatedAt     string      `json:"created_at"`
 }
 
 type HistoricalBalanceResponse struct {
-	Date   string  `json:"date"`
-	Amount float64 `json:"amount"`
+	Date   string      `json:"date"`
+	Amount money.Money `json:"amount"`
 }
@@ -17,3 +17,19 @@ type AuthenticateUserRequest struct {
 	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+// SetHandleRequest sets the caller's payment handle, with or without the leading @. An empty
+// handle removes it.
+type SetHandleRequest struct {
+	Handle string `json:"handle" validate:"max=31"`
+}
+
+// RecipientPreviewResponse shows who a transfer addressed to To would reach, masked so it
+// confirms the recipient without disclosing their details.
+type RecipientPreviewResponse struct {
+	To     string `json:"to"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Handle string `json:"handle,omitempty"`
+	Self   bool   `json:"self"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+type WorkerPoolResponse struct {
+	Workers    int    `json:"workers"`
+	Busy       int    `json:"busy"`
+	QueueDepth int64  `json:"queue_depth"`
+	Pacing     string `json:"pacing"`
+	Autoscale  bool   `json:"autoscale"`
+	MinWorkers int    `json:"min_workers"`
+	MaxWorkers int    `json:"max_workers"`
+}
+
+type UpdateWorkerPoolRequest struct {
+	Workers   *int  `json:"workers" validate:"omitempty,min=1"`
+	Autoscale *bool `json:"autoscale"`
+}
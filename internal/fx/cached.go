@@ -0,0 +1,51 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+)
+
+// cachedProvider caches another provider's rates in Redis for ttl. Cache failures are logged
+// and fall through to the provider.
+type cachedProvider struct {
+	next         RateProvider
+	cacheService *cache.CacheService
+	ttl          time.Duration
+}
+
+func NewCachedProvider(next RateProvider, cacheService *cache.CacheService, ttl time.Duration) RateProvider {
+	return &cachedProvider{next: next, cacheService: cacheService, ttl: ttl}
+}
+
+// CacheKey is the cache key of a pair's rate.
+func CacheKey(base, quote string) string {
+	return fmt.Sprintf("fx:rate:%s:%s", base, quote)
+}
+
+func (c *cachedProvider) Name() string {
+	return c.next.Name()
+}
+
+func (c *cachedProvider) Rate(ctx context.Context, base, quote string) (Rate, error) {
+	key := CacheKey(base, quote)
+
+	var rate Rate
+	if err := c.cacheService.GetJSON(ctx, key, &rate); err == nil {
+		return rate, nil
+	}
+
+	rate, err := c.next.Rate(ctx, base, quote)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	if err := c.cacheService.SetJSON(ctx, key, rate, c.ttl); err != nil {
+		logger.Log.Warnf("Failed to cache %s/%s exchange rate: %v", base, quote, err)
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,52 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+// databaseProvider serves the latest rates published to the exchange_rates table. A pair
+// without a rate of its own is served from the rate of the opposite direction.
+type databaseProvider struct {
+	rateRepo repositories.ExchangeRateRepository
+}
+
+func NewDatabaseProvider(rateRepo repositories.ExchangeRateRepository) RateProvider {
+	return &databaseProvider{rateRepo: rateRepo}
+}
+
+func (d *databaseProvider) Name() string {
+	return DatabaseRateProvider
+}
+
+func (d *databaseProvider) Rate(ctx context.Context, base, quote string) (Rate, error) {
+	now := time.Now()
+
+	rate, err := d.rateRepo.GetLatest(base, quote, now)
+	if err == nil {
+		return d.toRate(rate), nil
+	}
+	if appErrors.GetStatusCode(err) != http.StatusNotFound {
+		return Rate{}, err
+	}
+
+	inverse, err := d.rateRepo.GetLatest(quote, base, now)
+	if err != nil {
+		if appErrors.GetStatusCode(err) == http.StatusNotFound {
+			return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+		}
+		return Rate{}, err
+	}
+
+	return d.toRate(inverse).Inverse()
+}
+
+func (d *databaseProvider) toRate(rate *models.ExchangeRate) Rate {
+	return Rate{Base: rate.Base, Quote: rate.Quote, Value: NormalizeRate(rate.Rate), Provider: d.Name(), AsOf: rate.AsOf}
+}
@@ -0,0 +1,83 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpProvider fetches rates from an exchange rate API answering
+//
+//	GET {baseURL}/latest?base=EUR&symbols=TRY
+//
+// with a RateTable. cmd/fxstub serves this API from a rates file for local testing.
+type httpProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPProvider(baseURL string, timeout time.Duration) (RateProvider, error) {
+	if baseURL == "" {
+		return nil, errors.New("http exchange rate provider requires a URL")
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate provider URL %q: %w", baseURL, err)
+	}
+
+	return &httpProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (h *httpProvider) Name() string {
+	return HTTPRateProvider
+}
+
+func (h *httpProvider) Rate(ctx context.Context, base, quote string) (Rate, error) {
+	query := url.Values{"base": {base}, "symbols": {quote}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.baseURL+"/latest?"+query.Encode(), nil)
+	if err != nil {
+		return Rate{}, fmt.Errorf("failed to build exchange rate request: %w", err)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Rate{}, fmt.Errorf("exchange rate request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Rate{}, fmt.Errorf("failed to read exchange rate response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Rate{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+	case resp.StatusCode != http.StatusOK:
+		return Rate{}, fmt.Errorf("exchange rate API returned %s", resp.Status)
+	}
+
+	table, err := parseRateTable(body)
+	if err != nil {
+		return Rate{}, fmt.Errorf("invalid exchange rate response: %w", err)
+	}
+
+	value, err := table.Lookup(base, quote)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	asOf := time.Now()
+	if date, err := time.Parse(time.DateOnly, table.Date); err == nil {
+		asOf = date
+	}
+
+	return Rate{Base: base, Quote: quote, Value: value, Provider: h.Name(), AsOf: asOf}, nil
+}
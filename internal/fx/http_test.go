@@ -0,0 +1,93 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPProviderRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		base    string
+		quote   string
+		want    Rate
+		wantErr error
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			body:   `{"base": "EUR", "date": "2025-01-02", "rates": {"TRY": "35.12"}}`,
+			base:   "EUR",
+			quote:  "TRY",
+			want:   Rate{Base: "EUR", Quote: "TRY", Value: "35.12", Provider: HTTPRateProvider, AsOf: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:   "crossed through the table base",
+			status: http.StatusOK,
+			body:   `{"base": "USD", "date": "2025-01-02", "rates": {"EUR": 0.92, "TRY": 34.1}}`,
+			base:   "EUR",
+			quote:  "TRY",
+			want:   Rate{Base: "EUR", Quote: "TRY", Value: "37.065217391304", Provider: HTTPRateProvider, AsOf: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "pair missing from the table",
+			status:  http.StatusOK,
+			body:    `{"base": "EUR", "rates": {"USD": "1.08"}}`,
+			base:    "EUR",
+			quote:   "TRY",
+			wantErr: ErrRateNotFound,
+		},
+		{name: "not found", status: http.StatusNotFound, body: `{"error": "unknown symbol"}`, base: "EUR", quote: "XXX", wantErr: ErrRateNotFound},
+		{name: "server error", status: http.StatusInternalServerError, body: `{"error": "boom"}`, base: "EUR", quote: "TRY"},
+		{name: "unavailable", status: http.StatusServiceUnavailable, body: ``, base: "EUR", quote: "TRY"},
+		{name: "malformed body", status: http.StatusOK, body: `<html>`, base: "EUR", quote: "TRY"},
+		{name: "missing base", status: http.StatusOK, body: `{"rates": {"TRY": "35.12"}}`, base: "EUR", quote: "TRY", wantErr: ErrInvalidRate},
+		{name: "negative rate", status: http.StatusOK, body: `{"base": "EUR", "rates": {"TRY": "-35.12"}}`, base: "EUR", quote: "TRY", wantErr: ErrInvalidRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/latest" || r.URL.Query().Get("base") != tt.base || r.URL.Query().Get("symbols") != tt.quote {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider, err := NewHTTPProvider(server.URL+"/", time.Second)
+			if err != nil {
+				t.Fatalf("NewHTTPProvider() unexpected error: %v", err)
+			}
+
+			got, err := provider.Rate(context.Background(), tt.base, tt.quote)
+			// Without a wanted rate the call fails: with wantErr, or otherwise as unavailable,
+			// which callers tell apart from a missing rate.
+			if tt.want == (Rate{}) {
+				if err == nil {
+					t.Fatalf("Rate() = %+v, want an error", got)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Rate() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantErr == nil && errors.Is(err, ErrRateNotFound) {
+					t.Fatalf("Rate() error = %v, want the provider to be reported unavailable", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rate() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Rate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
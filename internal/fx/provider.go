@@ -0,0 +1,75 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	StaticRateProvider   = "static"
+	DatabaseRateProvider = "database"
+	HTTPRateProvider     = "http"
+)
+
+// RateProvider looks up exchange rates. Rate returns an error wrapping ErrRateNotFound if the
+// provider has no rate for the pair; any other error means the provider is unavailable.
+type RateProvider interface {
+	Name() string
+	Rate(ctx context.Context, base, quote string) (Rate, error)
+}
+
+type ProviderOptions struct {
+	Backend     string
+	RatesFile   string
+	HTTPURL     string
+	HTTPTimeout time.Duration
+	CacheTTL    time.Duration
+}
+
+// NewRateProvider builds the configured provider. Unless CacheTTL is zero its rates are
+// cached in Redis, so every instance sees the same rate for a pair within the TTL.
+func NewRateProvider(opts ProviderOptions, db *gorm.DB, cacheService *cache.CacheService) (RateProvider, error) {
+	var (
+		provider RateProvider
+		err      error
+	)
+
+	switch opts.Backend {
+	case StaticRateProvider:
+		provider, err = NewStaticProvider(opts.RatesFile)
+	case DatabaseRateProvider:
+		provider = NewDatabaseProvider(repositories.NewExchangeRateRepository(db))
+	case HTTPRateProvider:
+		provider, err = NewHTTPProvider(opts.HTTPURL, opts.HTTPTimeout)
+	default:
+		return nil, fmt.Errorf("unknown exchange rate provider %q", opts.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.CacheTTL > 0 && cacheService != nil {
+		provider = NewCachedProvider(provider, cacheService, opts.CacheTTL)
+	}
+
+	return provider, nil
+}
+
+// crossRate derives base/quote from two rates against a common currency: if one unit of the
+// common currency buys baseRate of base and quoteRate of quote, base/quote is quoteRate/baseRate.
+func crossRate(baseRate, quoteRate string) (string, error) {
+	b, err := ParseRate(baseRate)
+	if err != nil {
+		return "", err
+	}
+	q, err := ParseRate(quoteRate)
+	if err != nil {
+		return "", err
+	}
+	return FormatRate(q.Quo(q, b)), nil
+}
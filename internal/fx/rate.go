@@ -0,0 +1,104 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+// rateDecimals is the precision rates are stored and quoted with.
+const rateDecimals = 12
+
+var (
+	ErrRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidRate  = errors.New("invalid exchange rate")
+)
+
+// Rate is the price of one unit of Base in units of Quote, e.g. EUR/TRY 35.12.
+type Rate struct {
+	Base     string    `json:"base"`
+	Quote    string    `json:"quote"`
+	Value    string    `json:"rate"`
+	Provider string    `json:"provider"`
+	AsOf     time.Time `json:"as_of"`
+}
+
+// ParseRate parses a positive decimal rate such as "35.1234" without going through floats.
+func ParseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || strings.ContainsAny(value, "/eE") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, value)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q must be positive", ErrInvalidRate, value)
+	}
+	return rate, nil
+}
+
+// FormatRate formats a rate with up to rateDecimals decimals, dropping trailing zeros.
+func FormatRate(rate *big.Rat) string {
+	value := rate.FloatString(rateDecimals)
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
+
+// NormalizeRate formats a stored rate the way FormatRate does, e.g. the "35.120000000000"
+// read back from a numeric column as "35.12". Unparseable values are returned unchanged.
+func NormalizeRate(value string) string {
+	rate, err := ParseRate(value)
+	if err != nil {
+		return value
+	}
+	return FormatRate(rate)
+}
+
+// Inverse returns the rate for the opposite direction, Quote/Base.
+func (r Rate) Inverse() (Rate, error) {
+	value, err := ParseRate(r.Value)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{
+		Base:     r.Quote,
+		Quote:    r.Base,
+		Value:    FormatRate(new(big.Rat).Inv(value)),
+		Provider: r.Provider,
+		AsOf:     r.AsOf,
+	}, nil
+}
+
+// Convert converts an amount in Base into Quote. The result is rounded down to the minor
+// unit of Quote, so a conversion never pays out more than the rate gives.
+func (r Rate) Convert(amount money.Money) (money.Money, error) {
+	if !strings.EqualFold(amount.Currency, r.Base) {
+		return money.Money{}, fmt.Errorf("%w: %s amount for a %s/%s rate", money.ErrCurrencyMismatch, amount.Currency, r.Base, r.Quote)
+	}
+
+	value, err := ParseRate(r.Value)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	converted := new(big.Rat).SetInt64(amount.Minor)
+	converted.Mul(converted, value)
+
+	shift := money.Exponent(r.Quote) - money.Exponent(r.Base)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(shift, -shift))), nil))
+	if shift >= 0 {
+		converted.Mul(converted, scale)
+	} else {
+		converted.Quo(converted, scale)
+	}
+
+	minor := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !minor.IsInt64() {
+		return money.Money{}, money.ErrOverflow
+	}
+
+	return money.New(minor.Int64(), r.Quote), nil
+}
@@ -0,0 +1,124 @@
+package fx
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    Rate
+		amount  money.Money
+		want    money.Money
+		wantErr error
+	}{
+		{name: "same exponent", rate: Rate{Base: "EUR", Quote: "USD", Value: "1.08"}, amount: money.New(1000, "EUR"), want: money.New(1080, "USD")},
+		{name: "case-insensitive base", rate: Rate{Base: "eur", Quote: "USD", Value: "1.08"}, amount: money.New(1000, "EUR"), want: money.New(1080, "USD")},
+		{name: "USD to JPY drops two places", rate: Rate{Base: "USD", Quote: "JPY", Value: "150.5"}, amount: money.New(1000, "USD"), want: money.New(1505, "JPY")},
+		{name: "JPY to USD adds two places", rate: Rate{Base: "JPY", Quote: "USD", Value: "0.0066"}, amount: money.New(1500, "JPY"), want: money.New(990, "USD")},
+		{name: "USD to KWD adds one place", rate: Rate{Base: "USD", Quote: "KWD", Value: "0.30712"}, amount: money.New(1000, "USD"), want: money.New(3071, "KWD")},
+		{name: "rounds down", rate: Rate{Base: "EUR", Quote: "USD", Value: "1.1"}, amount: money.New(333, "EUR"), want: money.New(366, "USD")},
+		{name: "rounds down after the exponent shift", rate: Rate{Base: "USD", Quote: "JPY", Value: "150.5"}, amount: money.New(199, "USD"), want: money.New(299, "JPY")},
+		{name: "less than a minor unit", rate: Rate{Base: "JPY", Quote: "USD", Value: "0.0066"}, amount: money.New(1, "JPY"), want: money.New(0, "USD")},
+		{name: "overflow", rate: Rate{Base: "EUR", Quote: "USD", Value: "2"}, amount: money.New(math.MaxInt64, "EUR"), wantErr: money.ErrOverflow},
+		{name: "overflow from the exponent shift", rate: Rate{Base: "JPY", Quote: "USD", Value: "1"}, amount: money.New(math.MaxInt64/10, "JPY"), wantErr: money.ErrOverflow},
+		{name: "currency mismatch", rate: Rate{Base: "USD", Quote: "JPY", Value: "150.5"}, amount: money.New(1000, "EUR"), wantErr: money.ErrCurrencyMismatch},
+		{name: "invalid rate", rate: Rate{Base: "EUR", Quote: "USD", Value: "abc"}, amount: money.New(1000, "EUR"), wantErr: ErrInvalidRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rate.Convert(tt.amount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Convert(%s) error = %v, want %v", tt.amount, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert(%s) unexpected error: %v", tt.amount, err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert(%s) = %+v, want %+v", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInverse(t *testing.T) {
+	asOf := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr error
+	}{
+		{value: "4", want: "0.25"},
+		{value: "0.25", want: "4"},
+		{value: "35", want: "0.028571428571"},
+		{value: "150.5", want: "0.006644518272"},
+		{value: "0", wantErr: ErrInvalidRate},
+		{value: "abc", wantErr: ErrInvalidRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rate := Rate{Base: "EUR", Quote: "TRY", Value: tt.value, Provider: StaticRateProvider, AsOf: asOf}
+			got, err := rate.Inverse()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Inverse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Inverse() unexpected error: %v", err)
+			}
+
+			want := Rate{Base: "TRY", Quote: "EUR", Value: tt.want, Provider: StaticRateProvider, AsOf: asOf}
+			if got != want {
+				t.Errorf("Inverse() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCrossRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseRate  string
+		quoteRate string
+		want      string
+		wantErr   bool
+	}{
+		{name: "base is the common currency", baseRate: "1", quoteRate: "0.92", want: "0.92"},
+		{name: "quote is the common currency", baseRate: "0.5", quoteRate: "1", want: "2"},
+		{name: "crossed", baseRate: "0.92", quoteRate: "34.1", want: "37.065217391304"},
+		{name: "rounded to nearest", baseRate: "0.0066", quoteRate: "1", want: "151.515151515152"},
+		{name: "invalid base rate", baseRate: "abc", quoteRate: "1", wantErr: true},
+		{name: "zero quote rate", baseRate: "1", quoteRate: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := crossRate(tt.baseRate, tt.quoteRate)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRate) {
+					t.Fatalf("crossRate(%q, %q) error = %v, want %v", tt.baseRate, tt.quoteRate, err, ErrInvalidRate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("crossRate(%q, %q) unexpected error: %v", tt.baseRate, tt.quoteRate, err)
+			}
+			if got != tt.want {
+				t.Errorf("crossRate(%q, %q) = %q, want %q", tt.baseRate, tt.quoteRate, got, tt.want)
+			}
+		})
+	}
+}
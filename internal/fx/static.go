@@ -0,0 +1,117 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// RateTable is a set of rates against one base currency, as read from a rates file and as
+// served by the HTTP provider's API:
+//
+//	{"base": "USD", "date": "2025-01-01", "rates": {"EUR": "0.92", "TRY": 34.1}}
+//
+// Rates may be given as strings or numbers.
+type RateTable struct {
+	Base  string                 `json:"base"`
+	Date  string                 `json:"date,omitempty"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+// Lookup returns base/quote from the table, crossing through the table's base currency if
+// neither side is the base.
+func (t *RateTable) Lookup(base, quote string) (string, error) {
+	rateOf := func(currency string) (string, bool) {
+		if strings.EqualFold(currency, t.Base) {
+			return "1", true
+		}
+		rate, ok := t.Rates[strings.ToUpper(currency)]
+		return rate.String(), ok
+	}
+
+	baseRate, ok := rateOf(base)
+	if !ok {
+		return "", fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+	}
+	quoteRate, ok := rateOf(quote)
+	if !ok {
+		return "", fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+	}
+
+	return crossRate(baseRate, quoteRate)
+}
+
+// staticProvider serves rates from a JSON file read once at start-up.
+type staticProvider struct {
+	table *RateTable
+	asOf  time.Time
+}
+
+func NewStaticProvider(path string) (RateProvider, error) {
+	table, err := LoadRateTable(path)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf := time.Now()
+	if table.Date != "" {
+		if date, err := time.Parse(time.DateOnly, table.Date); err == nil {
+			asOf = date
+		}
+	}
+
+	return &staticProvider{table: table, asOf: asOf}, nil
+}
+
+func (s *staticProvider) Name() string {
+	return StaticRateProvider
+}
+
+func (s *staticProvider) Rate(ctx context.Context, base, quote string) (Rate, error) {
+	value, err := s.table.Lookup(base, quote)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{Base: base, Quote: quote, Value: value, Provider: s.Name(), AsOf: s.asOf}, nil
+}
+
+// LoadRateTable reads a rates file.
+func LoadRateTable(path string) (*RateTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	table, err := parseRateTable(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rates file %s: %w", path, err)
+	}
+
+	return table, nil
+}
+
+func parseRateTable(data []byte) (*RateTable, error) {
+	var table RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+	if table.Base == "" {
+		return nil, fmt.Errorf("%w: base currency is required", ErrInvalidRate)
+	}
+
+	table.Base = strings.ToUpper(table.Base)
+	rates := make(map[string]json.Number, len(table.Rates))
+	for currency, rate := range table.Rates {
+		if _, err := ParseRate(rate.String()); err != nil {
+			return nil, fmt.Errorf("%s: %w", currency, err)
+		}
+		rates[strings.ToUpper(currency)] = rate
+	}
+	table.Rates = rates
+
+	return &table, nil
+}
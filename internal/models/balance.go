@@ -1,12 +1,94 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	AccountKindChecking = "checking"
+	AccountKindSavings  = "savings"
+	AccountKindPocket   = "pocket"
+)
+
+const (
+	AccountStatusOpen   = "open"
+	AccountStatusClosed = "closed"
+)
+
+const DefaultAccountName = "main"
+
+// Balance is one of a user's accounts. A user can hold several named accounts per currency;
+// one of them is the default account of the currency, which receives incoming money and is
+// used whenever no account is given. At most one default per user and currency and unique
+// names among open accounts are enforced by the idx_balances_user_default and
+// idx_balances_user_name indexes.
 type Balance struct {
-	UserId        uint `gorm:"primaryKey"`
-	Amount        float64
+	Id            uint        `gorm:"primaryKey"`
+	UserId        uint        `gorm:"not null;index"`
+	Name          string      `gorm:"not null;default:'main'"`
+	Kind          string      `gorm:"not null;default:'checking'"`
+	IsDefault     bool        `gorm:"not null;default:false"`
+	Amount        money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	HeldMinor     int64       `gorm:"not null;default:0"`
+	Version       uint64      `gorm:"not null;default:0"`
+	ClosedAt      *time.Time
 	LastUpdatedAt time.Time
 	Date          time.Time `json:"date"`
+	// SavingsProductId is the product a savings account earns interest under, and
+	// InterestAccruedThrough the last day interest has been accrued for.
+	SavingsProductId       *uint      `gorm:"index;default:null"`
+	InterestAccruedThrough *time.Time `gorm:"type:date"`
+	// OverdraftLimitMinor is how far below zero the account may go, and
+	// OverdraftBasisPoints the annual interest charged on the overdrawn balance.
+	OverdraftLimitMinor  int64 `gorm:"not null;default:0"`
+	OverdraftBasisPoints int64 `gorm:"not null;default:0"`
 
 	User *User `gorm:"foreignKey:UserId;references:Id;constraint:OnDelete:CASCADE"`
 }
+
+// Held returns the part of the ledger balance reserved by active holds.
+func (b *Balance) Held() money.Money {
+	return money.New(b.HeldMinor, b.Amount.Currency)
+}
+
+// Available returns the ledger balance minus held funds plus the overdraft limit, i.e. what
+// can still be spent.
+func (b *Balance) Available() money.Money {
+	return money.New(b.Amount.Minor-b.HeldMinor+b.OverdraftLimitMinor, b.Amount.Currency)
+}
+
+// OverdraftLimit returns the credit line of the account.
+func (b *Balance) OverdraftLimit() money.Money {
+	return money.New(b.OverdraftLimitMinor, b.Amount.Currency)
+}
+
+// OverdraftUsed returns how much of the credit line is in use: how far the ledger balance
+// minus held funds is below zero.
+func (b *Balance) OverdraftUsed() money.Money {
+	return money.New(max(b.HeldMinor-b.Amount.Minor, 0), b.Amount.Currency)
+}
+
+// OverdraftRemaining returns how much of the credit line can still be drawn. It is zero if
+// the limit was lowered below what is in use.
+func (b *Balance) OverdraftRemaining() money.Money {
+	return money.New(max(b.OverdraftLimitMinor-b.OverdraftUsed().Minor, 0), b.Amount.Currency)
+}
+
+// EarnsInterest reports whether interest accrues on the account, on a positive balance under a
+// savings product or on an overdrawn balance at the overdraft rate.
+func (b *Balance) EarnsInterest() bool {
+	return b.SavingsProductId != nil || b.OverdraftBasisPoints > 0
+}
+
+func (b *Balance) IsClosed() bool {
+	return b.ClosedAt != nil
+}
+
+func (b *Balance) Status() string {
+	if b.IsClosed() {
+		return AccountStatusClosed
+	}
+	return AccountStatusOpen
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+// BalanceSnapshot records a balance right after a mutation, so the balance at any point in
+// time is the latest snapshot taken at or before it.
+type BalanceSnapshot struct {
+	Id        uint        `gorm:"primaryKey"`
+	UserId    uint        `gorm:"not null;index:idx_balance_snapshots_user_time,priority:1"`
+	BalanceId *uint       `gorm:"index:idx_balance_snapshots_balance_time,priority:1;default:null"`
+	Amount    money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	Version   uint64      `gorm:"not null;default:0"`
+	CreatedAt time.Time   `gorm:"not null;index:idx_balance_snapshots_user_time,priority:2;index:idx_balance_snapshots_balance_time,priority:2"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// DeadLetter is a job that ran out of attempts, or a queue message that could not be decoded
+// into a job at all, in which case JobId is nil.
+type DeadLetter struct {
+	Id        uint   `gorm:"primaryKey"`
+	JobId     *uint  `gorm:"uniqueIndex"`
+	Payload   string `gorm:"type:jsonb;not null"`
+	Error     string `gorm:"not null"`
+	Attempts  int    `gorm:"not null"`
+	CreatedAt time.Time
+
+	Job *Job `gorm:"foreignKey:JobId"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ExchangeRate is a rate published for the database rate provider: one unit of Base buys Rate
+// units of Quote from AsOf on. Rates are kept as history; the latest one per pair applies.
+type ExchangeRate struct {
+	Id        uint      `gorm:"primaryKey"`
+	Base      string    `gorm:"type:varchar(3);not null;index:idx_exchange_rates_pair"`
+	Quote     string    `gorm:"type:varchar(3);not null;index:idx_exchange_rates_pair"`
+	Rate      string    `gorm:"type:numeric(24,12);not null"`
+	AsOf      time.Time `gorm:"not null;index:idx_exchange_rates_pair"`
+	CreatedAt time.Time
+}
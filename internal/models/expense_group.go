@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	SplitTypeEqual      = "equal"
+	SplitTypePercentage = "percentage"
+	SplitTypeExact      = "exact"
+)
+
+const SettlementStatusCompleted = "completed"
+
+// ExpenseGroupAuditEntity is the audit log entity type of changes to an expense group.
+const ExpenseGroupAuditEntity = "expense_group"
+
+// ExpenseGroup is a set of users sharing costs in one currency.
+type ExpenseGroup struct {
+	Id        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	Currency  string `gorm:"not null"`
+	CreatedBy uint   `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Members []ExpenseGroupMember `gorm:"foreignKey:GroupId;constraint:OnDelete:CASCADE"`
+}
+
+type ExpenseGroupMember struct {
+	Id        uint `gorm:"primaryKey"`
+	GroupId   uint `gorm:"not null;uniqueIndex:idx_expense_group_members_group_user,priority:1"`
+	UserId    uint `gorm:"not null;index;uniqueIndex:idx_expense_group_members_group_user,priority:2"`
+	CreatedAt time.Time
+}
+
+// Expense is a cost PaidBy paid for the group, split between members by its shares. The
+// shares always add up to Amount.
+type Expense struct {
+	Id          uint        `gorm:"primaryKey"`
+	GroupId     uint        `gorm:"not null;index"`
+	PaidBy      uint        `gorm:"not null"`
+	Amount      money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	Description string      `gorm:"not null"`
+	SplitType   string      `gorm:"not null"`
+	CreatedBy   uint        `gorm:"not null"`
+	CreatedAt   time.Time
+
+	Shares []ExpenseShare `gorm:"foreignKey:ExpenseId;constraint:OnDelete:CASCADE"`
+}
+
+// ExpenseShare is what UserId owes of an expense. BasisPoints is the user's percentage of a
+// percentage split (1% = 100).
+type ExpenseShare struct {
+	Id          uint  `gorm:"primaryKey"`
+	ExpenseId   uint  `gorm:"not null;index"`
+	UserId      uint  `gorm:"not null;index"`
+	AmountMinor int64 `gorm:"not null"`
+	BasisPoints int64 `gorm:"not null;default:0"`
+}
+
+// GroupSettlement is a transfer made to settle a debt within a group. It is recorded in the
+// same transaction as the transfer, so it is always completed.
+type GroupSettlement struct {
+	Id            uint        `gorm:"primaryKey"`
+	GroupId       uint        `gorm:"not null;index"`
+	FromUserId    uint        `gorm:"not null"`
+	ToUserId      uint        `gorm:"not null"`
+	Amount        money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	Status        string      `gorm:"not null;index"`
+	TransactionId *uint       `gorm:"default:null"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Debt is an amount, in minor units, FromUserId owes ToUserId.
+type Debt struct {
+	FromUserId  uint
+	ToUserId    uint
+	AmountMinor int64
+}
+
+// SplitEqual divides totalMinor between userIds as evenly as possible; the minor units left
+// over go one each to the first users.
+func SplitEqual(totalMinor int64, userIds []uint) []ExpenseShare {
+	shares := make([]ExpenseShare, len(userIds))
+	if len(userIds) == 0 {
+		return shares
+	}
+
+	base := totalMinor / int64(len(userIds))
+	remainder := totalMinor % int64(len(userIds))
+	for i, userId := range userIds {
+		shares[i] = ExpenseShare{UserId: userId, AmountMinor: base}
+		if int64(i) < remainder {
+			shares[i].AmountMinor++
+		}
+	}
+	return shares
+}
+
+// SplitPercentage divides totalMinor by the shares' BasisPoints, which must add up to 100%.
+// Amounts are rounded down and the minor units left over go to the shares with the largest
+// rounding loss, so the shares add up to totalMinor.
+func SplitPercentage(totalMinor int64, shares []ExpenseShare) ([]ExpenseShare, error) {
+	var sum int64
+	for _, share := range shares {
+		if share.BasisPoints <= 0 {
+			return nil, fmt.Errorf("the share of user %d must be positive", share.UserId)
+		}
+		sum += share.BasisPoints
+	}
+	if sum != basisPointsPerUnit {
+		return nil, fmt.Errorf("percentages add up to %d basis points, not %d", sum, basisPointsPerUnit)
+	}
+
+	split := make([]ExpenseShare, len(shares))
+	losses := make([]int64, len(shares))
+	allocated := int64(0)
+	for i, share := range shares {
+		exact := totalMinor * share.BasisPoints
+		split[i] = ExpenseShare{UserId: share.UserId, BasisPoints: share.BasisPoints, AmountMinor: exact / basisPointsPerUnit}
+		losses[i] = exact % basisPointsPerUnit
+		allocated += split[i].AmountMinor
+	}
+
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return losses[order[a]] > losses[order[b]] })
+	for i := int64(0); i < totalMinor-allocated; i++ {
+		split[order[i]].AmountMinor++
+	}
+
+	return split, nil
+}
+
+// CheckExactSplit checks that exact shares are positive and add up to totalMinor.
+func CheckExactSplit(totalMinor int64, shares []ExpenseShare) error {
+	var sum int64
+	for _, share := range shares {
+		if share.AmountMinor <= 0 {
+			return fmt.Errorf("the share of user %d must be positive", share.UserId)
+		}
+		sum += share.AmountMinor
+	}
+	if sum != totalMinor {
+		return fmt.Errorf("shares add up to %d minor units, not the %d of the expense", sum, totalMinor)
+	}
+	return nil
+}
+
+// SimplifyDebts turns net balances (positive for users who are owed money, negative for users
+// who owe) into as few debts as it greedily can: the largest debtor pays the largest creditor
+// until one of them is settled, which takes at most one debt fewer than there are users with
+// a balance. Ties are broken by user id, so the result is deterministic.
+func SimplifyDebts(net map[uint]int64) []Debt {
+	type balance struct {
+		userId uint
+		minor  int64
+	}
+
+	var creditors, debtors []balance
+	for userId, minor := range net {
+		if minor > 0 {
+			creditors = append(creditors, balance{userId, minor})
+		} else if minor < 0 {
+			debtors = append(debtors, balance{userId, -minor})
+		}
+	}
+
+	byAmount := func(balances []balance) func(a, b int) bool {
+		return func(a, b int) bool {
+			if balances[a].minor != balances[b].minor {
+				return balances[a].minor > balances[b].minor
+			}
+			return balances[a].userId < balances[b].userId
+		}
+	}
+
+	var debts []Debt
+	for len(creditors) > 0 && len(debtors) > 0 {
+		sort.Slice(creditors, byAmount(creditors))
+		sort.Slice(debtors, byAmount(debtors))
+
+		amount := min(creditors[0].minor, debtors[0].minor)
+		debts = append(debts, Debt{FromUserId: debtors[0].userId, ToUserId: creditors[0].userId, AmountMinor: amount})
+
+		creditors[0].minor -= amount
+		debtors[0].minor -= amount
+		if creditors[0].minor == 0 {
+			creditors = creditors[1:]
+		}
+		if debtors[0].minor == 0 {
+			debtors = debtors[1:]
+		}
+	}
+
+	return debts
+}
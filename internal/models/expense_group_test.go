@@ -0,0 +1,169 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitEqual(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		userIds []uint
+		want    []int64
+	}{
+		{name: "divides evenly", total: 999, userIds: []uint{1, 2, 3}, want: []int64{333, 333, 333}},
+		{name: "remainder goes to the first users", total: 1000, userIds: []uint{1, 2, 3}, want: []int64{334, 333, 333}},
+		{name: "less than one unit each", total: 2, userIds: []uint{4, 5, 6}, want: []int64{1, 1, 0}},
+		{name: "single user", total: 1234, userIds: []uint{9}, want: []int64{1234}},
+		{name: "zero total", total: 0, userIds: []uint{1, 2}, want: []int64{0, 0}},
+		{name: "no users", total: 1000, userIds: nil, want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := SplitEqual(tt.total, tt.userIds)
+			if len(shares) != len(tt.want) {
+				t.Fatalf("SplitEqual() returned %d shares, want %d", len(shares), len(tt.want))
+			}
+			for i, share := range shares {
+				if share.UserId != tt.userIds[i] || share.AmountMinor != tt.want[i] {
+					t.Errorf("share %d = user %d %d, want user %d %d", i, share.UserId, share.AmountMinor, tt.userIds[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitPercentage(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int64
+		basisPoints []int64
+		want        []int64
+		wantErr     bool
+	}{
+		{name: "exact halves", total: 100, basisPoints: []int64{5000, 5000}, want: []int64{50, 50}},
+		{name: "thirds give the remainder to the largest loss", total: 1000, basisPoints: []int64{3333, 3333, 3334}, want: []int64{333, 333, 334}},
+		{name: "uneven split", total: 10001, basisPoints: []int64{2500, 7500}, want: []int64{2500, 7501}},
+		{name: "equal losses go to the first share", total: 1, basisPoints: []int64{5000, 5000}, want: []int64{1, 0}},
+		{name: "whole amount", total: 4321, basisPoints: []int64{10000}, want: []int64{4321}},
+		{name: "below 100%", total: 1000, basisPoints: []int64{5000, 4999}, wantErr: true},
+		{name: "above 100%", total: 1000, basisPoints: []int64{5000, 5001}, wantErr: true},
+		{name: "zero share", total: 1000, basisPoints: []int64{10000, 0}, wantErr: true},
+		{name: "negative share", total: 1000, basisPoints: []int64{10100, -100}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := make([]ExpenseShare, len(tt.basisPoints))
+			for i, basisPoints := range tt.basisPoints {
+				shares[i] = ExpenseShare{UserId: uint(i + 1), BasisPoints: basisPoints}
+			}
+
+			split, err := SplitPercentage(tt.total, shares)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("SplitPercentage() = %+v, want an error", split)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitPercentage() unexpected error: %v", err)
+			}
+
+			var sum int64
+			for i, share := range split {
+				if share.UserId != shares[i].UserId || share.BasisPoints != shares[i].BasisPoints || share.AmountMinor != tt.want[i] {
+					t.Errorf("share %d = %+v, want user %d %d", i, share, shares[i].UserId, tt.want[i])
+				}
+				sum += share.AmountMinor
+			}
+			if sum != tt.total {
+				t.Errorf("shares add up to %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestCheckExactSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		amounts []int64
+		wantErr bool
+	}{
+		{name: "adds up", total: 1000, amounts: []int64{250, 750}},
+		{name: "short", total: 1000, amounts: []int64{250, 749}, wantErr: true},
+		{name: "over", total: 1000, amounts: []int64{250, 751}, wantErr: true},
+		{name: "zero share", total: 1000, amounts: []int64{1000, 0}, wantErr: true},
+		{name: "negative share", total: 1000, amounts: []int64{1100, -100}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := make([]ExpenseShare, len(tt.amounts))
+			for i, amount := range tt.amounts {
+				shares[i] = ExpenseShare{UserId: uint(i + 1), AmountMinor: amount}
+			}
+
+			if err := CheckExactSplit(tt.total, shares); (err != nil) != tt.wantErr {
+				t.Errorf("CheckExactSplit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSimplifyDebts(t *testing.T) {
+	tests := []struct {
+		name string
+		net  map[uint]int64
+		want []Debt
+	}{
+		{name: "no balances", net: nil, want: nil},
+		{name: "all settled", net: map[uint]int64{1: 0, 2: 0}, want: nil},
+		{
+			name: "one debt",
+			net:  map[uint]int64{1: 100, 2: -100},
+			want: []Debt{{FromUserId: 2, ToUserId: 1, AmountMinor: 100}},
+		},
+		{
+			name: "largest debtor pays first",
+			net:  map[uint]int64{1: 300, 2: -100, 3: -200},
+			want: []Debt{
+				{FromUserId: 3, ToUserId: 1, AmountMinor: 200},
+				{FromUserId: 2, ToUserId: 1, AmountMinor: 100},
+			},
+		},
+		{
+			name: "ties broken by user id",
+			net:  map[uint]int64{2: 100, 1: 100, 3: -200},
+			want: []Debt{
+				{FromUserId: 3, ToUserId: 1, AmountMinor: 100},
+				{FromUserId: 3, ToUserId: 2, AmountMinor: 100},
+			},
+		},
+		{
+			name: "settled users are left out",
+			net:  map[uint]int64{1: 50, 2: 0, 3: -50},
+			want: []Debt{{FromUserId: 3, ToUserId: 1, AmountMinor: 50}},
+		},
+		{
+			name: "at most one debt fewer than users",
+			net:  map[uint]int64{1: 400, 2: 100, 3: -250, 4: -250},
+			want: []Debt{
+				{FromUserId: 3, ToUserId: 1, AmountMinor: 250},
+				{FromUserId: 4, ToUserId: 1, AmountMinor: 150},
+				{FromUserId: 4, ToUserId: 2, AmountMinor: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SimplifyDebts(tt.net); !slices.Equal(got, tt.want) {
+				t.Errorf("SimplifyDebts(%v) = %+v, want %+v", tt.net, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const SystemFeesAccount = "system:fees"
+
+const (
+	FeeKindFlat       = "flat"
+	FeeKindPercentage = "percentage"
+	FeeKindTiered     = "tiered"
+)
+
+// basisPointsPerUnit is the number of basis points in 100%.
+const basisPointsPerUnit = 10000
+
+// FeeTier is one band of a tiered fee. It applies to amounts up to UpToMinor, or to any
+// amount if UpToMinor is 0.
+type FeeTier struct {
+	UpToMinor   int64 `json:"up_to_minor"`
+	FlatMinor   int64 `json:"flat_minor"`
+	BasisPoints int64 `json:"basis_points"`
+}
+
+// FeeRule prices one transaction type in one currency, for one role or, if Role is empty,
+// for everyone. Flat, minimum and maximum amounts are in minor units of Currency; a
+// MaxMinor of 0 means the fee is not capped.
+//
+// A flat rule charges FlatMinor. A percentage rule charges BasisPoints of the amount plus
+// FlatMinor. A tiered rule charges the flat amount and basis points of the first tier the
+// whole amount fits in; Tiers are sorted by UpToMinor with the unbounded tier last.
+type FeeRule struct {
+	Id              uint      `gorm:"primaryKey"`
+	TransactionType string    `gorm:"not null;index:idx_fee_rules_lookup,priority:1"`
+	Currency        string    `gorm:"not null;index:idx_fee_rules_lookup,priority:2"`
+	Role            string    `gorm:"not null;default:''"`
+	Kind            string    `gorm:"not null"`
+	FlatMinor       int64     `gorm:"not null;default:0"`
+	BasisPoints     int64     `gorm:"not null;default:0"`
+	Tiers           []FeeTier `gorm:"serializer:json"`
+	MinMinor        int64     `gorm:"not null;default:0"`
+	MaxMinor        int64     `gorm:"not null;default:0"`
+	Active          bool      `gorm:"not null;default:true"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Fee returns the fee the rule charges on amount. Percentages are rounded half up to the
+// currency's minor unit. It returns money.ErrOverflow if the fee is too large for an amount,
+// e.g. a large flat fee plus a percentage of a large amount without a maximum.
+func (r *FeeRule) Fee(amount money.Money) (money.Money, error) {
+	flat, basisPoints := r.FlatMinor, r.BasisPoints
+	switch r.Kind {
+	case FeeKindFlat:
+		basisPoints = 0
+	case FeeKindTiered:
+		flat, basisPoints = 0, 0
+		for _, tier := range r.Tiers {
+			if tier.UpToMinor == 0 || amount.Minor <= tier.UpToMinor {
+				flat, basisPoints = tier.FlatMinor, tier.BasisPoints
+				break
+			}
+		}
+	}
+
+	fee := percentOf(amount.Minor, basisPoints)
+	fee.Add(fee, big.NewInt(flat))
+	if fee.Cmp(big.NewInt(r.MinMinor)) < 0 {
+		fee.SetInt64(r.MinMinor)
+	}
+	if r.MaxMinor > 0 && fee.Cmp(big.NewInt(r.MaxMinor)) > 0 {
+		fee.SetInt64(r.MaxMinor)
+	}
+	if !fee.IsInt64() {
+		return money.Money{}, money.ErrOverflow
+	}
+
+	return money.New(fee.Int64(), amount.Currency), nil
+}
+
+// percentOf returns basisPoints of minor, rounded half up. It works on big integers so large
+// amounts cannot overflow.
+func percentOf(minor, basisPoints int64) *big.Int {
+	if basisPoints == 0 {
+		return new(big.Int)
+	}
+
+	product := new(big.Int).Mul(big.NewInt(minor), big.NewInt(basisPoints))
+	product.Add(product, big.NewInt(basisPointsPerUnit/2))
+	return product.Quo(product, big.NewInt(basisPointsPerUnit))
+}
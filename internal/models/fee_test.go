@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+func TestFeeRuleFee(t *testing.T) {
+	tiers := []FeeTier{
+		{UpToMinor: 10000, FlatMinor: 100},
+		{UpToMinor: 100000, BasisPoints: 100},
+		{FlatMinor: 500, BasisPoints: 50},
+	}
+
+	tests := []struct {
+		name    string
+		rule    FeeRule
+		amount  int64
+		want    int64
+		wantErr bool
+	}{
+		{name: "flat ignores basis points", rule: FeeRule{Kind: FeeKindFlat, FlatMinor: 150, BasisPoints: 100}, amount: 10000, want: 150},
+		{name: "percentage plus flat", rule: FeeRule{Kind: FeeKindPercentage, FlatMinor: 30, BasisPoints: 150}, amount: 10000, want: 180},
+		{name: "percentage rounds down below half", rule: FeeRule{Kind: FeeKindPercentage, BasisPoints: 125}, amount: 1234, want: 15},
+		{name: "percentage rounds half up", rule: FeeRule{Kind: FeeKindPercentage, BasisPoints: 5}, amount: 1000, want: 1},
+		{name: "percentage just below half", rule: FeeRule{Kind: FeeKindPercentage, BasisPoints: 5}, amount: 999, want: 0},
+		{name: "minimum", rule: FeeRule{Kind: FeeKindPercentage, BasisPoints: 100, MinMinor: 50}, amount: 1000, want: 50},
+		{name: "maximum", rule: FeeRule{Kind: FeeKindPercentage, BasisPoints: 100, MaxMinor: 2500}, amount: 1000000, want: 2500},
+		{name: "zero maximum is uncapped", rule: FeeRule{Kind: FeeKindPercentage, BasisPoints: 100}, amount: 1000000, want: 10000},
+		{name: "no overflow on large amounts", rule: FeeRule{Kind: FeeKindPercentage, BasisPoints: 10000}, amount: math.MaxInt64, want: math.MaxInt64},
+		{name: "first tier", rule: FeeRule{Kind: FeeKindTiered, FlatMinor: 999, Tiers: tiers}, amount: 5000, want: 100},
+		{name: "tier bound is inclusive", rule: FeeRule{Kind: FeeKindTiered, Tiers: tiers}, amount: 10000, want: 100},
+		{name: "second tier", rule: FeeRule{Kind: FeeKindTiered, Tiers: tiers}, amount: 10001, want: 100},
+		{name: "second tier percentage", rule: FeeRule{Kind: FeeKindTiered, Tiers: tiers}, amount: 50000, want: 500},
+		{name: "unbounded tier", rule: FeeRule{Kind: FeeKindTiered, Tiers: tiers}, amount: 200000, want: 1500},
+		{name: "tiered capped", rule: FeeRule{Kind: FeeKindTiered, Tiers: tiers, MaxMinor: 1000}, amount: 200000, want: 1000},
+		{name: "past the last bounded tier", rule: FeeRule{Kind: FeeKindTiered, Tiers: tiers[:1]}, amount: 5000000, want: 0},
+		{name: "past the last bounded tier with minimum", rule: FeeRule{Kind: FeeKindTiered, Tiers: tiers[:1], MinMinor: 25}, amount: 5000000, want: 25},
+		{name: "flat plus percentage overflows", rule: FeeRule{Kind: FeeKindPercentage, FlatMinor: math.MaxInt64, BasisPoints: 1}, amount: 10000, wantErr: true},
+		{name: "percentage of large amount plus flat overflows", rule: FeeRule{Kind: FeeKindPercentage, FlatMinor: 1, BasisPoints: 10000}, amount: math.MaxInt64, wantErr: true},
+		{name: "tier flat plus percentage overflows", rule: FeeRule{Kind: FeeKindTiered, Tiers: []FeeTier{{FlatMinor: math.MaxInt64 - 1, BasisPoints: 100}}}, amount: 1000, wantErr: true},
+		{name: "maximum caps an overflowing fee", rule: FeeRule{Kind: FeeKindPercentage, FlatMinor: math.MaxInt64, BasisPoints: 1, MaxMinor: 2500}, amount: 10000, want: 2500},
+		{name: "largest flat fee", rule: FeeRule{Kind: FeeKindFlat, FlatMinor: math.MaxInt64}, amount: 10000, want: math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Fee(money.New(tt.amount, "EUR"))
+			if tt.wantErr {
+				if !errors.Is(err, money.ErrOverflow) {
+					t.Fatalf("Fee(%d) = %+v, %v, want %v", tt.amount, got, err, money.ErrOverflow)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fee(%d) unexpected error: %v", tt.amount, err)
+			}
+			if got != money.New(tt.want, "EUR") {
+				t.Errorf("Fee(%d) = %+v, want %d EUR", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const SystemFxAccount = "system:fx"
+
+const (
+	FxQuoteStatusOpen     = "open"
+	FxQuoteStatusExecuted = "executed"
+	FxQuoteStatusExpired  = "expired"
+)
+
+// FxQuote fixes the rate for converting Source into Target until ExpiresAt. A quote is
+// executed at most once.
+type FxQuote struct {
+	Id         uint        `gorm:"primaryKey"`
+	UserId     uint        `gorm:"not null;index"`
+	Source     money.Money `gorm:"embedded;embeddedPrefix:source_"`
+	Target     money.Money `gorm:"embedded;embeddedPrefix:target_"`
+	Rate       string      `gorm:"type:numeric(24,12);not null"`
+	Provider   string      `gorm:"not null"`
+	Status     string      `gorm:"not null;index"`
+	ExpiresAt  time.Time   `gorm:"not null"`
+	ExecutedAt *time.Time
+	CreatedAt  time.Time
+}
+
+func (q *FxQuote) IsExpired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+// CurrentStatus reports an open quote past its expiry as expired. Quotes are not swept, so
+// the stored status of an unused quote stays open.
+func (q *FxQuote) CurrentStatus(now time.Time) string {
+	if q.Status == FxQuoteStatusOpen && q.IsExpired(now) {
+		return FxQuoteStatusExpired
+	}
+	return q.Status
+}
+
+// Conversion records an executed quote: the conversion transaction debited Source from the
+// user and credited Target to the recipient at Rate.
+type Conversion struct {
+	Id            uint        `gorm:"primaryKey"`
+	TransactionId uint        `gorm:"not null;uniqueIndex"`
+	QuoteId       uint        `gorm:"not null;uniqueIndex"`
+	UserId        uint        `gorm:"not null;index"`
+	ToUserId      uint        `gorm:"not null;index"`
+	Source        money.Money `gorm:"embedded;embeddedPrefix:source_"`
+	Target        money.Money `gorm:"embedded;embeddedPrefix:target_"`
+	Rate          string      `gorm:"type:numeric(24,12);not null"`
+	Provider      string      `gorm:"not null"`
+	CreatedAt     time.Time
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+// Hold reserves part of a user's balance until it is captured, released or expires. A hold is
+// captured at most once; whatever is not captured goes back to the available balance. The fee
+// for the full amount is quoted and reserved along with it when the hold is placed.
+type Hold struct {
+	Id            uint        `gorm:"primaryKey"`
+	UserId        uint        `gorm:"not null;index"`
+	BalanceId     uint        `gorm:"not null;default:0"`
+	ToUserId      *uint       `gorm:"default:null"`
+	Amount        money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	FeeMinor      int64       `gorm:"not null;default:0"`
+	CapturedMinor int64       `gorm:"not null;default:0"`
+	Description   string
+	Status        string    `gorm:"not null;index"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	TransactionId *uint     `gorm:"default:null"`
+	ClosedAt      *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (h *Hold) Fee() money.Money {
+	return money.New(h.FeeMinor, h.Amount.Currency)
+}
+
+// Reserved is what the hold takes out of the available balance: its amount and its fee.
+func (h *Hold) Reserved() money.Money {
+	return money.New(h.Amount.Minor+h.FeeMinor, h.Amount.Currency)
+}
+
+// TransactionType is the type of transaction capturing the hold makes.
+func (h *Hold) TransactionType() string {
+	if h.ToUserId != nil {
+		return TransactionTypeTransfer
+	}
+	return TransactionTypeWithdraw
+}
+
+func (h *Hold) Captured() money.Money {
+	return money.New(h.CapturedMinor, h.Amount.Currency)
+}
+
+func (h *Hold) IsExpired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
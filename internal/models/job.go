@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	JobStatusQueued     = "queued"
+	JobStatusProcessing = "processing"
+	JobStatusCompleted  = "completed"
+	JobStatusFailed     = "failed"
+)
+
+type Job struct {
+	Id            uint        `gorm:"primaryKey"`
+	Type          string      `gorm:"not null"`
+	Status        string      `gorm:"not null;index"`
+	UserId        uint        `gorm:"not null;index"`
+	ToUserId      *uint       `gorm:"default:null"`
+	FromAccountId *uint       `gorm:"default:null"`
+	ToAccountId   *uint       `gorm:"default:null"`
+	Amount        money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	FailureReason string
+	TransactionId *uint   `gorm:"default:null"`
+	Reference     *string `gorm:"uniqueIndex;default:null"`
+	StartedAt     *time.Time
+	CompletedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Transaction *Transaction `gorm:"foreignKey:TransactionId"`
+}
+
+func NewJob(jobType string, userId uint, toUserId *uint, amount money.Money) *Job {
+	return &Job{
+		Type:     jobType,
+		Status:   JobStatusQueued,
+		UserId:   userId,
+		ToUserId: toUserId,
+		Amount:   amount,
+	}
+}
+
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed
+}
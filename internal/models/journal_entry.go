@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrImmutableLedger = errors.New("ledger records are immutable")
+
+type JournalEntry struct {
+	Id            uint   `gorm:"primaryKey"`
+	TransactionId *uint  `gorm:"index;default:null"`
+	Type          string `gorm:"not null"`
+	CreatedAt     time.Time
+
+	Postings    []Posting    `gorm:"foreignKey:JournalEntryId"`
+	Transaction *Transaction `gorm:"foreignKey:TransactionId"`
+}
+
+// Validate checks that the entry has at least two legs and that they sum to zero per currency.
+func (j *JournalEntry) Validate() error {
+	if len(j.Postings) < 2 {
+		return fmt.Errorf("journal entry needs at least two postings, got %d", len(j.Postings))
+	}
+
+	totals := make(map[string]int64)
+	for _, posting := range j.Postings {
+		if posting.Account == "" {
+			return errors.New("posting account is required")
+		}
+		if posting.Amount.IsZero() {
+			return fmt.Errorf("posting to %s has a zero amount", posting.Account)
+		}
+		totals[posting.Amount.Currency] += posting.Amount.Minor
+	}
+
+	for currency, total := range totals {
+		if total != 0 {
+			return fmt.Errorf("journal entry is unbalanced by %d minor units of %s", total, currency)
+		}
+	}
+
+	return nil
+}
+
+func (j *JournalEntry) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutableLedger
+}
+
+func (j *JournalEntry) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutableLedger
+}
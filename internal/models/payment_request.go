@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	PaymentRequestStatusPending   = "pending"
+	PaymentRequestStatusAccepted  = "accepted"
+	PaymentRequestStatusPaid      = "paid"
+	PaymentRequestStatusDeclined  = "declined"
+	PaymentRequestStatusCancelled = "cancelled"
+	PaymentRequestStatusExpired   = "expired"
+)
+
+// PaymentRequestAuditEntity is the audit log entity type of payment request state changes.
+const PaymentRequestAuditEntity = "payment_request"
+
+// PaymentRequestJobReferencePrefix starts the Job.Reference of every payment request transfer.
+const PaymentRequestJobReferencePrefix = "payment_request:"
+
+// PaymentRequest asks PayerId to send Amount to RequesterId. Accepting it creates a transfer
+// job, which is enqueued afterwards and marked by SubmittedAt; once the job completes the
+// request is paid, and if it fails the request is pending again so the payer can retry before
+// it expires.
+type PaymentRequest struct {
+	Id            uint        `gorm:"primaryKey"`
+	RequesterId   uint        `gorm:"not null;index"`
+	PayerId       uint        `gorm:"not null;index"`
+	Amount        money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	Note          string
+	Status        string    `gorm:"not null;index"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	FromAccountId *uint     `gorm:"default:null"`
+	JobId         *uint     `gorm:"default:null"`
+	TransactionId *uint     `gorm:"default:null"`
+	FailureReason string
+	SubmittedAt   *time.Time
+	Attempts      int `gorm:"not null;default:0"`
+	ClosedAt      *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Job *Job `gorm:"foreignKey:JobId"`
+}
+
+func (p *PaymentRequest) IsExpired(now time.Time) bool {
+	return !now.Before(p.ExpiresAt)
+}
+
+// IsParty reports whether userId is the requester or the payer of the request.
+func (p *PaymentRequest) IsParty(userId uint) bool {
+	return p.RequesterId == userId || p.PayerId == userId
+}
+
+// JobReference is the Job.Reference of the transfer created when the request is accepted for
+// the Attempts-th time.
+func (p *PaymentRequest) JobReference() string {
+	return fmt.Sprintf("%s%d:%d", PaymentRequestJobReferencePrefix, p.Id, p.Attempts)
+}
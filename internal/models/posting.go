@@ -0,0 +1,36 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+)
+
+const (
+	SystemExternalAccount = "system:external"
+	SystemOpeningAccount  = "system:opening"
+)
+
+// Posting is one leg of a journal entry. A positive amount credits the account (money in),
+// a negative amount debits it (money out).
+type Posting struct {
+	Id             uint        `gorm:"primaryKey"`
+	JournalEntryId uint        `gorm:"index;not null"`
+	Account        string      `gorm:"index;not null"`
+	Amount         money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	CreatedAt      time.Time
+}
+
+func UserAccount(userId uint) string {
+	return fmt.Sprintf("user:%d", userId)
+}
+
+func (p *Posting) BeforeUpdate(tx *gorm.DB) error {
+	return ErrImmutableLedger
+}
+
+func (p *Posting) BeforeDelete(tx *gorm.DB) error {
+	return ErrImmutableLedger
+}
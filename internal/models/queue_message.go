@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type QueueMessage struct {
+	Id          uint      `gorm:"primaryKey"`
+	Queue       string    `gorm:"not null;index:idx_queue_messages_ready,priority:1"`
+	Payload     string    `gorm:"type:jsonb;not null"`
+	Attempts    int       `gorm:"not null;default:0"`
+	AvailableAt time.Time `gorm:"not null;index:idx_queue_messages_ready,priority:2"`
+	LockedUntil *time.Time
+	CreatedAt   time.Time
+}
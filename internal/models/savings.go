@@ -0,0 +1,82 @@
+package models
+
+import (
+	"math/big"
+	"time"
+)
+
+const SystemInterestAccount = "system:interest"
+
+const (
+	// InterestMicrosPerMinor is the precision daily accruals are kept in: millionths of a
+	// minor unit, so interest on small balances is not lost to rounding day by day.
+	InterestMicrosPerMinor = 1_000_000
+	// InterestDaysPerYear is the day count convention of annual rates (actual/365).
+	InterestDaysPerYear = 365
+)
+
+// SavingsProduct is a savings account offering. Accounts opened with a product earn its
+// interest rate; deactivating a product only stops new accounts from being opened with it.
+type SavingsProduct struct {
+	Id        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"`
+	Currency  string `gorm:"not null"`
+	Active    bool   `gorm:"not null;default:true"`
+	CreatedAt time.Time
+}
+
+// InterestRate is the annual rate of a product from EffectiveFrom, a UTC date, until the next
+// rate of the product takes effect.
+type InterestRate struct {
+	Id                uint      `gorm:"primaryKey"`
+	ProductId         uint      `gorm:"not null;uniqueIndex:idx_interest_rates_product_date,priority:1"`
+	AnnualBasisPoints int64     `gorm:"not null"`
+	EffectiveFrom     time.Time `gorm:"type:date;not null;uniqueIndex:idx_interest_rates_product_date,priority:2"`
+	CreatedAt         time.Time
+}
+
+// InterestAccrual is the interest an account earned on one UTC day, on its closing balance of
+// that day at the rate in effect then. On an overdrawn balance it is the overdraft interest
+// charged, and AmountMicros is negative. Accruals are settled by capitalization, which links
+// them to the interest transaction; ones that round to nothing are capitalized without one.
+type InterestAccrual struct {
+	Id                uint       `gorm:"primaryKey"`
+	BalanceId         uint       `gorm:"not null;uniqueIndex:idx_interest_accruals_balance_date,priority:1"`
+	Date              time.Time  `gorm:"type:date;not null;uniqueIndex:idx_interest_accruals_balance_date,priority:2"`
+	PrincipalMinor    int64      `gorm:"not null"`
+	AnnualBasisPoints int64      `gorm:"not null"`
+	AmountMicros      int64      `gorm:"not null"`
+	CapitalizedAt     *time.Time `gorm:"index"`
+	TransactionId     *uint      `gorm:"index;default:null"`
+	CreatedAt         time.Time
+}
+
+// DailyInterestMicros returns one day's interest on principalMinor at an annual rate of
+// basisPoints, in millionths of a minor unit and rounded down. Overdrawn balances earn nothing.
+func DailyInterestMicros(principalMinor, basisPoints int64) int64 {
+	if principalMinor <= 0 || basisPoints <= 0 {
+		return 0
+	}
+
+	interest := new(big.Int).Mul(big.NewInt(principalMinor), big.NewInt(basisPoints))
+	interest.Mul(interest, big.NewInt(InterestMicrosPerMinor))
+	return interest.Quo(interest, big.NewInt(basisPointsPerUnit*InterestDaysPerYear)).Int64()
+}
+
+// InterestMinor rounds accrued micros half up to minor units.
+func InterestMinor(micros int64) int64 {
+	return (micros + InterestMicrosPerMinor/2) / InterestMicrosPerMinor
+}
+
+// UTCDay returns the start of t's day in UTC.
+func UTCDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NextCapitalization returns when interest accrued up to now is paid out: the start of the
+// next UTC month.
+func NextCapitalization(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+}
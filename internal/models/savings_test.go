@@ -0,0 +1,101 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyInterestMicros(t *testing.T) {
+	tests := []struct {
+		name        string
+		principal   int64
+		basisPoints int64
+		want        int64
+	}{
+		{name: "5% on 1000.00", principal: 100000, basisPoints: 500, want: 13698630},
+		{name: "1% on one minor unit", principal: 1, basisPoints: 100, want: 27},
+		{name: "full year rate", principal: 365, basisPoints: 10000, want: 1000000},
+		{name: "large principal", principal: 1_000_000_000_000_000, basisPoints: 10000, want: 2739726027397260273},
+		{name: "zero principal", principal: 0, basisPoints: 500, want: 0},
+		{name: "overdrawn principal", principal: -100000, basisPoints: 500, want: 0},
+		{name: "zero rate", principal: 100000, basisPoints: 0, want: 0},
+		{name: "negative rate", principal: 100000, basisPoints: -5, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DailyInterestMicros(tt.principal, tt.basisPoints); got != tt.want {
+				t.Errorf("DailyInterestMicros(%d, %d) = %d, want %d", tt.principal, tt.basisPoints, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterestMinor(t *testing.T) {
+	tests := []struct {
+		micros int64
+		want   int64
+	}{
+		{micros: 0, want: 0},
+		{micros: 499999, want: 0},
+		{micros: 500000, want: 1},
+		{micros: 1499999, want: 1},
+		{micros: 1500000, want: 2},
+		{micros: 13698630, want: 14},
+		// Thirty daily accruals of 5% on 1000.00 are rounded once, not day by day (30 * 14).
+		{micros: 30 * 13698630, want: 411},
+	}
+
+	for _, tt := range tests {
+		if got := InterestMinor(tt.micros); got != tt.want {
+			t.Errorf("InterestMinor(%d) = %d, want %d", tt.micros, got, tt.want)
+		}
+	}
+}
+
+func TestInterestDates(t *testing.T) {
+	istanbul := time.FixedZone("TRT", 3*60*60)
+
+	tests := []struct {
+		name                   string
+		now                    time.Time
+		wantDay                time.Time
+		wantNextCapitalization time.Time
+	}{
+		{
+			name:                   "mid month",
+			now:                    time.Date(2024, 5, 17, 13, 45, 0, 0, time.UTC),
+			wantDay:                time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC),
+			wantNextCapitalization: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:                   "local time on the previous UTC day",
+			now:                    time.Date(2024, 1, 2, 1, 0, 0, 0, istanbul),
+			wantDay:                time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantNextCapitalization: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:                   "end of year",
+			now:                    time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
+			wantDay:                time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			wantNextCapitalization: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:                   "start of month",
+			now:                    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantDay:                time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantNextCapitalization: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UTCDay(tt.now); !got.Equal(tt.wantDay) || got.Location() != time.UTC {
+				t.Errorf("UTCDay(%s) = %s, want %s", tt.now, got, tt.wantDay)
+			}
+			if got := NextCapitalization(tt.now); !got.Equal(tt.wantNextCapitalization) {
+				t.Errorf("NextCapitalization(%s) = %s, want %s", tt.now, got, tt.wantNextCapitalization)
+			}
+		})
+	}
+}
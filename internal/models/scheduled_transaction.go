@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	ScheduledStatusScheduled  = "scheduled"
+	ScheduledStatusSubmitting = "submitting"
+	ScheduledStatusSubmitted  = "submitted"
+	ScheduledStatusCancelled  = "cancelled"
+)
+
+// ScheduledTransaction is a deposit, withdrawal or transfer that becomes a job once it is due.
+// It moves to submitting when its job is created and to submitted once the job is enqueued.
+// Accounts that are not given default to the users' default accounts.
+type ScheduledTransaction struct {
+	Id            uint        `gorm:"primaryKey"`
+	UserId        uint        `gorm:"not null;index"`
+	ToUserId      *uint       `gorm:"default:null"`
+	FromAccountId *uint       `gorm:"default:null"`
+	ToAccountId   *uint       `gorm:"default:null"`
+	Type          string      `gorm:"not null"`
+	Amount        money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	ScheduledAt   time.Time   `gorm:"not null;index"`
+	Status        string      `gorm:"not null;index"`
+	JobId         *uint       `gorm:"default:null"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Job *Job `gorm:"foreignKey:JobId"`
+}
+
+// JobReference is the Job.Reference of the job created for this scheduled transaction.
+func (s *ScheduledTransaction) JobReference() string {
+	return fmt.Sprintf("scheduled:%d", s.Id)
+}
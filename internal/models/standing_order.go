@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"github.com/yusuffugurlu/go-project/pkg/recurrence"
+)
+
+const (
+	StandingOrderStatusActive    = "active"
+	StandingOrderStatusPaused    = "paused"
+	StandingOrderStatusCompleted = "completed"
+	StandingOrderStatusCancelled = "cancelled"
+
+	// StandingOrderRetryDelay is how long a failed occurrence waits before it is retried.
+	StandingOrderRetryDelay = 24 * time.Hour
+)
+
+// StandingOrder repeats a deposit, withdrawal or transfer following an RRULE or cron
+// expression. Each due date produces a StandingOrderOccurrence. Accounts that are not given
+// default to the users' default accounts.
+type StandingOrder struct {
+	Id              uint        `gorm:"primaryKey"`
+	UserId          uint        `gorm:"not null;index"`
+	ToUserId        *uint       `gorm:"default:null"`
+	FromAccountId   *uint       `gorm:"default:null"`
+	ToAccountId     *uint       `gorm:"default:null"`
+	Type            string      `gorm:"not null"`
+	Amount          money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+	Description     string
+	Recurrence      string    `gorm:"not null"`
+	Timezone        string    `gorm:"not null;default:'UTC'"`
+	StartAt         time.Time `gorm:"not null"`
+	EndAt           *time.Time
+	MaxOccurrences  *int
+	OccurrenceCount int        `gorm:"not null;default:0"`
+	NextRunAt       *time.Time `gorm:"index"`
+	Status          string     `gorm:"not null;index"`
+	RetryFailed     bool       `gorm:"not null;default:false"`
+	MaxRetries      int        `gorm:"not null;default:0"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (o *StandingOrder) Schedule() (recurrence.Schedule, error) {
+	loc, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", o.Timezone, err)
+	}
+	return recurrence.Parse(o.Recurrence, o.StartAt.In(loc))
+}
+
+// NextOccurrence returns the first due date after the given time that is still within the
+// order's end date and occurrence limit, or nil if the order has run its course.
+func (o *StandingOrder) NextOccurrence(after time.Time) (*time.Time, error) {
+	if o.MaxOccurrences != nil && o.OccurrenceCount >= *o.MaxOccurrences {
+		return nil, nil
+	}
+
+	schedule, err := o.Schedule()
+	if err != nil {
+		return nil, err
+	}
+
+	var next time.Time
+	if after.Before(o.StartAt) {
+		next = recurrence.First(schedule, o.StartAt)
+	} else {
+		next = schedule.Next(after)
+	}
+
+	if next.IsZero() || (o.EndAt != nil && next.After(*o.EndAt)) {
+		return nil, nil
+	}
+	return &next, nil
+}
+
+// ShouldRetry reports whether a failed occurrence on its given attempt gets another one.
+func (o *StandingOrder) ShouldRetry(attempt int) bool {
+	if o.Status == StandingOrderStatusCancelled || o.Status == StandingOrderStatusPaused {
+		return false
+	}
+	return o.RetryFailed && attempt <= o.MaxRetries
+}
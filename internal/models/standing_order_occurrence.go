@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	OccurrenceStatusPending    = "pending"
+	OccurrenceStatusSubmitting = "submitting"
+	OccurrenceStatusSubmitted  = "submitted"
+	OccurrenceStatusCompleted  = "completed"
+	OccurrenceStatusFailed     = "failed"
+	OccurrenceStatusSkipped    = "skipped"
+)
+
+// StandingOrderOccurrence is one execution attempt of a standing order. A failed occurrence
+// that is retried the next day gets a new row with the same sequence and a higher attempt.
+type StandingOrderOccurrence struct {
+	Id              uint      `gorm:"primaryKey"`
+	StandingOrderId uint      `gorm:"not null;uniqueIndex:idx_occurrence_attempt,priority:1"`
+	Sequence        int       `gorm:"not null;uniqueIndex:idx_occurrence_attempt,priority:2"`
+	Attempt         int       `gorm:"not null;default:1;uniqueIndex:idx_occurrence_attempt,priority:3"`
+	DueAt           time.Time `gorm:"not null"`
+	RunAt           time.Time `gorm:"not null;index"`
+	Status          string    `gorm:"not null;index"`
+	JobId           *uint     `gorm:"default:null"`
+	TransactionId   *uint     `gorm:"default:null"`
+	FailureReason   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	StandingOrder *StandingOrder `gorm:"foreignKey:StandingOrderId;constraint:OnDelete:CASCADE"`
+	Job           *Job           `gorm:"foreignKey:JobId"`
+}
+
+// JobReference is the Job.Reference of the job created for this occurrence.
+func (o *StandingOrderOccurrence) JobReference() string {
+	return fmt.Sprintf("standing:%d:%d:%d", o.StandingOrderId, o.Sequence, o.Attempt)
+}
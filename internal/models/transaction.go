@@ -1,16 +1,69 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	TransactionTypeDeposit  = "deposit"
+	TransactionTypeWithdraw = "withdraw"
+	TransactionTypeTransfer = "transfer"
+	TransactionTypeDebit    = "debit"
+	TransactionTypeReversal = "reversal"
+	// TransactionTypeConversion debits Amount from FromUser and credits its equivalent in
+	// another currency to ToUser; the rate and target amount are on the Conversion record.
+	TransactionTypeConversion = "conversion"
+	// TransactionTypeFee charges a fee for the transaction FeeOf to the account FromAccount.
+	TransactionTypeFee = "fee"
+	// TransactionTypeInterest pays interest accrued on a savings account to ToAccount.
+	TransactionTypeInterest = "interest"
+	// TransactionTypeOverdraftInterest charges interest on an overdrawn FromAccount.
+	TransactionTypeOverdraftInterest = "overdraft_interest"
+)
+
+const (
+	TransactionStatusCompleted         = "completed"
+	TransactionStatusPartiallyReversed = "partially_reversed"
+	TransactionStatusReversed          = "reversed"
+)
 
 type Transaction struct {
-	Id         uint  `gorm:"primaryKey"`
-	FromUserId *uint `gorm:"default:null"`
-	ToUserId   *uint `gorm:"default:null"`
-	Amount     float64
+	Id         uint        `gorm:"primaryKey"`
+	FromUserId *uint       `gorm:"default:null"`
+	ToUserId   *uint       `gorm:"default:null"`
+	Amount     money.Money `gorm:"embedded;embeddedPrefix:amount_"`
 	Type       string
 	Status     string
-	CreatedAt  time.Time
+	JobId      *uint `gorm:"uniqueIndex;default:null"`
+	// ReversalOfId links a reversal to the transaction it (partly) refunds.
+	ReversalOfId *uint `gorm:"index;default:null"`
+	// RefundedMinor is how much of Amount has been refunded by reversals so far.
+	RefundedMinor int64 `gorm:"not null;default:0"`
+	// FromAccountId and ToAccountId are the accounts of FromUser and ToUser the money moved
+	// out of and into.
+	FromAccountId *uint `gorm:"index;default:null"`
+	ToAccountId   *uint `gorm:"index;default:null"`
+	// FeeOfId links a fee to the transaction it was charged for.
+	FeeOfId   *uint `gorm:"index;default:null"`
+	CreatedAt time.Time
 
 	FromUser *User `gorm:"foreignKey:FromUserId"`
 	ToUser   *User `gorm:"foreignKey:ToUserId"`
 }
+
+// Refundable returns the part of the amount that has not been refunded yet.
+func (t *Transaction) Refundable() money.Money {
+	return money.New(t.Amount.Minor-t.RefundedMinor, t.Amount.Currency)
+}
+
+// IsReversible reports whether the transaction can still be (partly) reversed. Reversals
+// themselves cannot be reversed, and neither can conversions, which would have to be undone
+// at a new rate.
+func (t *Transaction) IsReversible() bool {
+	if t.Type == TransactionTypeReversal || t.Type == TransactionTypeConversion {
+		return false
+	}
+	return t.Status == TransactionStatusCompleted || t.Status == TransactionStatusPartiallyReversed
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	LimitMaxSingle     = "max_single"
+	LimitDailyAmount   = "daily_amount"
+	LimitMonthlyAmount = "monthly_amount"
+	LimitDailyCount    = "daily_count"
+	LimitMonthlyCount  = "monthly_count"
+)
+
+// LimitedTransactionTypes are the transactions that count against a user's limits: money
+// leaving the user. Transfers and conversions between a user's own accounts do not count.
+var LimitedTransactionTypes = []string{TransactionTypeTransfer, TransactionTypeWithdraw, TransactionTypeConversion}
+
+// TransactionLimit caps what users can move out in one currency. A limit either applies to
+// everyone with Role, or, if UserId is set, overrides the role's limit for one user. Zero
+// means unlimited. Daily and monthly windows are calendar days and months in UTC.
+type TransactionLimit struct {
+	Id             uint   `gorm:"primaryKey"`
+	Role           string `gorm:"not null;default:'';uniqueIndex:idx_transaction_limits_scope,priority:1"`
+	UserId         uint   `gorm:"not null;default:0;uniqueIndex:idx_transaction_limits_scope,priority:2"`
+	Currency       string `gorm:"not null;uniqueIndex:idx_transaction_limits_scope,priority:3"`
+	MaxSingleMinor int64  `gorm:"not null;default:0"`
+	DailyMinor     int64  `gorm:"not null;default:0"`
+	MonthlyMinor   int64  `gorm:"not null;default:0"`
+	DailyCount     int64  `gorm:"not null;default:0"`
+	MonthlyCount   int64  `gorm:"not null;default:0"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// LimitUsage is what a user has moved out in one currency in the current windows.
+type LimitUsage struct {
+	DailyMinor   int64
+	DailyCount   int64
+	MonthlyMinor int64
+	MonthlyCount int64
+}
+
+// LimitBreach describes the limit a transaction would exceed.
+type LimitBreach struct {
+	Limit     string
+	Allowed   int64
+	Used      int64
+	Requested int64
+	ResetsAt  *time.Time
+}
+
+// IsAmount reports whether the breached limit caps an amount rather than a count.
+func (b *LimitBreach) IsAmount() bool {
+	return b.Limit != LimitDailyCount && b.Limit != LimitMonthlyCount
+}
+
+// Breach returns the first limit that moving amount on top of usage would exceed, or nil if
+// it stays within all of them.
+func (l *TransactionLimit) Breach(amount money.Money, usage LimitUsage, now time.Time) *LimitBreach {
+	dayEnd, monthEnd := LimitWindowEnds(now)
+
+	switch {
+	case l.MaxSingleMinor > 0 && amount.Minor > l.MaxSingleMinor:
+		return &LimitBreach{Limit: LimitMaxSingle, Allowed: l.MaxSingleMinor, Requested: amount.Minor}
+	case l.DailyMinor > 0 && usage.DailyMinor+amount.Minor > l.DailyMinor:
+		return &LimitBreach{Limit: LimitDailyAmount, Allowed: l.DailyMinor, Used: usage.DailyMinor, Requested: amount.Minor, ResetsAt: &dayEnd}
+	case l.MonthlyMinor > 0 && usage.MonthlyMinor+amount.Minor > l.MonthlyMinor:
+		return &LimitBreach{Limit: LimitMonthlyAmount, Allowed: l.MonthlyMinor, Used: usage.MonthlyMinor, Requested: amount.Minor, ResetsAt: &monthEnd}
+	case l.DailyCount > 0 && usage.DailyCount+1 > l.DailyCount:
+		return &LimitBreach{Limit: LimitDailyCount, Allowed: l.DailyCount, Used: usage.DailyCount, Requested: 1, ResetsAt: &dayEnd}
+	case l.MonthlyCount > 0 && usage.MonthlyCount+1 > l.MonthlyCount:
+		return &LimitBreach{Limit: LimitMonthlyCount, Allowed: l.MonthlyCount, Used: usage.MonthlyCount, Requested: 1, ResetsAt: &monthEnd}
+	}
+
+	return nil
+}
+
+// LimitWindowStarts returns the start of the current UTC day and month.
+func LimitWindowStarts(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return day, month
+}
+
+// LimitWindowEnds returns when the current UTC day and month end and their limits reset.
+func LimitWindowEnds(now time.Time) (time.Time, time.Time) {
+	day, month := LimitWindowStarts(now)
+	return day.AddDate(0, 0, 1), month.AddDate(0, 1, 0)
+}
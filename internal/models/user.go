@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/yusuffugurlu/go-project/pkg/money"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -15,8 +16,22 @@ type User struct {
 	Role         string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	// Handle is the user's shareable payment handle, stored in lower case without the
+	// leading @.
+	Handle *string `gorm:"uniqueIndex"`
 
-	Balance *Balance `gorm:"foreignKey:UserId"`
+	Balances []Balance `gorm:"foreignKey:UserId"`
+}
+
+// BalanceIn returns the user's default account in the given currency, or nil if it is not
+// loaded or the user has none.
+func (u *User) BalanceIn(currency string) *Balance {
+	for i := range u.Balances {
+		if u.Balances[i].IsDefault && u.Balances[i].Amount.Currency == currency {
+			return &u.Balances[i]
+		}
+	}
+	return nil
 }
 
 func (u *User) HashPassword() error {
@@ -37,7 +52,10 @@ func (u *User) CheckPassword(password string) bool {
 func (u *User) AfterCreate(tx *gorm.DB) (err error) {
 	balance := Balance{
 		UserId:        u.Id,
-		Amount:        0.0,
+		Name:          DefaultAccountName,
+		Kind:          AccountKindChecking,
+		IsDefault:     true,
+		Amount:        money.Zero(money.DefaultCurrency),
 		LastUpdatedAt: time.Now(),
 	}
 
@@ -0,0 +1,83 @@
+package process
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// memoryQueue keeps jobs in a buffered channel. Nothing survives a restart, so it is only
+// meant for development and tests.
+type memoryQueue struct {
+	jobs   chan *Delivery
+	nextId atomic.Uint64
+	closed chan struct{}
+	once   sync.Once
+}
+
+func NewMemoryQueue(size int) Queue {
+	return &memoryQueue{
+		jobs:   make(chan *Delivery, size),
+		closed: make(chan struct{}),
+	}
+}
+
+func (m *memoryQueue) Enqueue(ctx context.Context, job Transaction) error {
+	return m.push(&Delivery{
+		Id:  strconv.FormatUint(m.nextId.Add(1), 10),
+		Job: job,
+	})
+}
+
+func (m *memoryQueue) Dequeue(ctx context.Context) (*Delivery, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-m.closed:
+		return nil, ErrQueueClosed
+	case delivery := <-m.jobs:
+		delivery.Attempts++
+		return delivery, nil
+	}
+}
+
+func (m *memoryQueue) Ack(ctx context.Context, delivery *Delivery) error {
+	return nil
+}
+
+func (m *memoryQueue) Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error {
+	if delay <= 0 {
+		return m.push(delivery)
+	}
+
+	time.AfterFunc(delay, func() {
+		_ = m.push(delivery)
+	})
+	return nil
+}
+
+func (m *memoryQueue) Len(ctx context.Context) (int64, error) {
+	return int64(len(m.jobs)), nil
+}
+
+func (m *memoryQueue) Close() error {
+	m.once.Do(func() { close(m.closed) })
+	return nil
+}
+
+func (m *memoryQueue) push(delivery *Delivery) error {
+	select {
+	case <-m.closed:
+		return ErrQueueClosed
+	default:
+	}
+
+	select {
+	case m.jobs <- delivery:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
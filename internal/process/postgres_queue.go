@@ -0,0 +1,126 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// postgresQueue stores jobs in the queue_messages table. Consumers claim rows with
+// FOR UPDATE SKIP LOCKED and lease them for the visibility timeout; a row is deleted on Ack,
+// and a lease that expires without Ack makes the row visible again.
+type postgresQueue struct {
+	db                *gorm.DB
+	name              string
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+}
+
+func NewPostgresQueue(db *gorm.DB, name string, visibilityTimeout, pollInterval time.Duration) Queue {
+	return &postgresQueue{
+		db:                db,
+		name:              name,
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      pollInterval,
+	}
+}
+
+func (p *postgresQueue) Enqueue(ctx context.Context, job Transaction) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	message := &models.QueueMessage{
+		Queue:       p.name,
+		Payload:     string(payload),
+		AvailableAt: time.Now(),
+	}
+	return p.db.WithContext(ctx).Create(message).Error
+}
+
+func (p *postgresQueue) Dequeue(ctx context.Context) (*Delivery, error) {
+	for {
+		delivery, err := p.claim(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if delivery != nil {
+			return delivery, nil
+		}
+
+		if err := sleepContext(ctx, p.pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *postgresQueue) Ack(ctx context.Context, delivery *Delivery) error {
+	return p.db.WithContext(ctx).Delete(&models.QueueMessage{}, "id = ?", delivery.Id).Error
+}
+
+func (p *postgresQueue) Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error {
+	return p.db.WithContext(ctx).Model(&models.QueueMessage{}).
+		Where("id = ?", delivery.Id).
+		Updates(map[string]interface{}{
+			"locked_until": nil,
+			"available_at": time.Now().Add(delay),
+		}).Error
+}
+
+func (p *postgresQueue) Len(ctx context.Context) (int64, error) {
+	var count int64
+	err := p.db.WithContext(ctx).Model(&models.QueueMessage{}).Where("queue = ?", p.name).Count(&count).Error
+	return count, err
+}
+
+func (p *postgresQueue) Close() error {
+	return nil
+}
+
+func (p *postgresQueue) claim(ctx context.Context) (*Delivery, error) {
+	var message models.QueueMessage
+
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("queue = ? AND available_at <= ?", p.name, now).
+			Where("locked_until IS NULL OR locked_until < ?", now).
+			Order("id").
+			First(&message).Error; err != nil {
+			return err
+		}
+
+		lockedUntil := now.Add(p.visibilityTimeout)
+		message.Attempts++
+		message.LockedUntil = &lockedUntil
+
+		return tx.Model(&message).Updates(map[string]interface{}{
+			"attempts":     message.Attempts,
+			"locked_until": message.LockedUntil,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &Delivery{
+		Id:       strconv.FormatUint(uint64(message.Id), 10),
+		Attempts: message.Attempts,
+	}
+	if err := json.Unmarshal([]byte(message.Payload), &delivery.Job); err != nil {
+		delivery.Payload = message.Payload
+		delivery.DecodeErr = err
+	}
+
+	return delivery, nil
+}
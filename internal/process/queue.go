@@ -0,0 +1,91 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQueueFull   = errors.New("job queue is full")
+	ErrQueueClosed = errors.New("job queue is closed")
+)
+
+const transactionQueueName = "transactions"
+
+const (
+	MemoryQueueBackend   = "memory"
+	PostgresQueueBackend = "postgres"
+	RedisQueueBackend    = "redis"
+)
+
+// Delivery is a job handed to a consumer. It must be acknowledged with Ack once processed,
+// or handed back with Nack; unacknowledged deliveries of durable backends are redelivered.
+// A message that cannot be decoded is delivered with DecodeErr set and its raw Payload, so
+// it can be dead-lettered instead of being redelivered forever.
+type Delivery struct {
+	Id        string
+	Job       Transaction
+	Attempts  int
+	Payload   string
+	DecodeErr error
+}
+
+type Queue interface {
+	Enqueue(ctx context.Context, job Transaction) error
+	Dequeue(ctx context.Context) (*Delivery, error)
+	Ack(ctx context.Context, delivery *Delivery) error
+	Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error
+	Len(ctx context.Context) (int64, error)
+	Close() error
+}
+
+type QueueOptions struct {
+	Backend           string
+	Size              int
+	VisibilityTimeout time.Duration
+	PollInterval      time.Duration
+}
+
+func validateBackend(backend string) error {
+	switch backend {
+	case MemoryQueueBackend, PostgresQueueBackend, RedisQueueBackend:
+		return nil
+	default:
+		return fmt.Errorf("unknown queue backend %q", backend)
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func NewQueue(opts QueueOptions, db *gorm.DB, redisClient *redis.Client) (Queue, error) {
+	if err := validateBackend(opts.Backend); err != nil {
+		return nil, err
+	}
+
+	switch opts.Backend {
+	case PostgresQueueBackend:
+		return NewPostgresQueue(db, transactionQueueName, opts.VisibilityTimeout, opts.PollInterval), nil
+	case RedisQueueBackend:
+		if redisClient == nil {
+			return nil, errors.New("redis queue backend requires a redis client")
+		}
+		return NewRedisStreamQueue(redisClient, "queue:"+transactionQueueName, opts.VisibilityTimeout, opts.PollInterval)
+	default:
+		return NewMemoryQueue(opts.Size), nil
+	}
+}
@@ -0,0 +1,231 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisEnvelope struct {
+	Job      Transaction `json:"job"`
+	Attempts int         `json:"attempts"`
+	Origin   string      `json:"origin,omitempty"`
+}
+
+// redisStreamQueue uses a Redis stream with a consumer group. Entries stay pending until
+// acknowledged; entries left pending longer than the visibility timeout (e.g. by a crashed
+// consumer) are claimed by another consumer. Delayed retries wait in a sorted set until due.
+type redisStreamQueue struct {
+	client            *redis.Client
+	stream            string
+	delayed           string
+	group             string
+	consumer          string
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+}
+
+func NewRedisStreamQueue(client *redis.Client, stream string, visibilityTimeout, pollInterval time.Duration) (Queue, error) {
+	hostname, _ := os.Hostname()
+	q := &redisStreamQueue{
+		client:            client,
+		stream:            stream,
+		delayed:           stream + ":delayed",
+		group:             stream + ":workers",
+		consumer:          fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      pollInterval,
+	}
+
+	err := client.XGroupCreateMkStream(context.Background(), q.stream, q.group, "0").Err()
+	if err != nil && !redis.HasErrorPrefix(err, "BUSYGROUP") {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+func (r *redisStreamQueue) Enqueue(ctx context.Context, job Transaction) error {
+	return r.add(ctx, redisEnvelope{Job: job})
+}
+
+func (r *redisStreamQueue) Dequeue(ctx context.Context) (*Delivery, error) {
+	for {
+		if err := r.promoteDelayed(ctx); err != nil {
+			return nil, err
+		}
+
+		messages, _, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   r.stream,
+			Group:    r.group,
+			Consumer: r.consumer,
+			MinIdle:  r.visibilityTimeout,
+			Start:    "0-0",
+			Count:    1,
+		}).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) > 0 {
+			deliveries, err := r.deliveryCount(ctx, messages[0].ID)
+			if err != nil {
+				return nil, err
+			}
+			return r.delivery(messages[0], deliveries), nil
+		}
+
+		streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.group,
+			Consumer: r.consumer,
+			Streams:  []string{r.stream, ">"},
+			Count:    1,
+			Block:    r.pollInterval,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, stream := range streams {
+			if len(stream.Messages) > 0 {
+				return r.delivery(stream.Messages[0], 1), nil
+			}
+		}
+	}
+}
+
+func (r *redisStreamQueue) Ack(ctx context.Context, delivery *Delivery) error {
+	pipe := r.client.TxPipeline()
+	pipe.XAck(ctx, r.stream, r.group, delivery.Id)
+	pipe.XDel(ctx, r.stream, delivery.Id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisStreamQueue) Nack(ctx context.Context, delivery *Delivery, delay time.Duration) error {
+	envelope := redisEnvelope{Job: delivery.Job, Attempts: delivery.Attempts, Origin: delivery.Id}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	if delay > 0 {
+		pipe.ZAdd(ctx, r.delayed, redis.Z{Score: float64(time.Now().Add(delay).UnixMilli()), Member: payload})
+	} else {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: r.stream, Values: map[string]interface{}{"payload": payload}})
+	}
+	pipe.XAck(ctx, r.stream, r.group, delivery.Id)
+	pipe.XDel(ctx, r.stream, delivery.Id)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisStreamQueue) Len(ctx context.Context) (int64, error) {
+	pipe := r.client.Pipeline()
+	streamLen := pipe.XLen(ctx, r.stream)
+	delayedLen := pipe.ZCard(ctx, r.delayed)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return streamLen.Val() + delayedLen.Val(), nil
+}
+
+func (r *redisStreamQueue) Close() error {
+	return nil
+}
+
+func (r *redisStreamQueue) add(ctx context.Context, envelope redisEnvelope) error {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream,
+		Values: map[string]interface{}{"payload": payload},
+	}).Err()
+}
+
+func (r *redisStreamQueue) promoteDelayed(ctx context.Context) error {
+	due, err := r.client.ZRangeByScore(ctx, r.delayed, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, member := range due {
+		removed, err := r.client.ZRem(ctx, r.delayed, member).Result()
+		if err != nil {
+			return err
+		}
+		// Another consumer promoted it first.
+		if removed == 0 {
+			continue
+		}
+
+		if err := r.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: r.stream,
+			Values: map[string]interface{}{"payload": member},
+		}).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliveryCount is how many times the stream entry has been handed to a consumer, including
+// claims of entries whose consumer died before acknowledging them.
+func (r *redisStreamQueue) deliveryCount(ctx context.Context, id string) (int, error) {
+	pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: r.stream,
+		Group:  r.group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 1, nil
+	}
+	return int(pending[0].RetryCount), nil
+}
+
+// delivery decodes a stream entry. Attempts carried over from earlier entries of the same job
+// (see Nack) are added to the deliveries of this entry.
+func (r *redisStreamQueue) delivery(message redis.XMessage, deliveries int) *Delivery {
+	delivery := &Delivery{Id: message.ID, Attempts: deliveries}
+
+	raw, ok := message.Values["payload"].(string)
+	if !ok {
+		delivery.DecodeErr = fmt.Errorf("stream entry %s has no payload", message.ID)
+		return delivery
+	}
+
+	var envelope redisEnvelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		delivery.Payload = raw
+		delivery.DecodeErr = err
+		return delivery
+	}
+
+	delivery.Job = envelope.Job
+	delivery.Attempts += envelope.Attempts
+	return delivery
+}
@@ -0,0 +1,55 @@
+package process
+
+import (
+	"time"
+
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     1 * time.Minute,
+		Multiplier:     2,
+	}
+}
+
+// Backoff returns how long to wait before the given attempt (1-based) is retried.
+func (r RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(r.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= r.Multiplier
+		if time.Duration(backoff) >= r.MaxBackoff {
+			return r.MaxBackoff
+		}
+	}
+	return time.Duration(backoff)
+}
+
+func (r RetryPolicy) ShouldRetry(err error, attempt int) bool {
+	return attempt < r.MaxAttempts && IsRetryable(err)
+}
+
+// IsRetryable reports whether an error is transient. Business errors such as insufficient
+// funds or a missing balance would fail the same way again and are never retried.
+func IsRetryable(err error) bool {
+	appErr, ok := appErrors.AsAppError(err)
+	if !ok {
+		return false
+	}
+
+	switch appErr.Code {
+	case appErrors.ErrCodeDatabaseError, appErrors.ErrCodeServiceUnavailable:
+		return true
+	default:
+		return false
+	}
+}
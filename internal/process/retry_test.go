@@ -0,0 +1,104 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := DefaultRetryPolicy()
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 6, want: 32 * time.Second},
+		{attempt: 7, want: time.Minute},
+		{attempt: 50, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+
+	fractional := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 1.5}
+	if got, want := fractional.Backoff(3), 225*time.Millisecond; got != want {
+		t.Errorf("Backoff(3) with multiplier 1.5 = %s, want %s", got, want)
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := DefaultRetryPolicy()
+
+	tests := []struct {
+		name    string
+		err     error
+		attempt int
+		want    bool
+	}{
+		{name: "database error", err: appErrors.NewDatabaseError(errors.New("connection reset"), "failed to update balance"), attempt: 1, want: true},
+		{name: "service unavailable", err: appErrors.NewServiceUnavailable(nil, "ledger unavailable"), attempt: 4, want: true},
+		{name: "wrapped database error", err: fmt.Errorf("transfer: %w", appErrors.NewDatabaseError(nil, "deadlock")), attempt: 1, want: true},
+		{name: "last attempt", err: appErrors.NewDatabaseError(nil, "deadlock"), attempt: 5, want: false},
+		{name: "business error", err: appErrors.NewBadRequest(nil, "insufficient funds"), attempt: 1, want: false},
+		{name: "not found", err: appErrors.NewNotFound(nil, "balance not found"), attempt: 1, want: false},
+		{name: "plain error", err: errors.New("boom"), attempt: 1, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.ShouldRetry(tt.err, tt.attempt); got != tt.want {
+				t.Errorf("ShouldRetry(%v, %d) = %v, want %v", tt.err, tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRetryPolicyThroughQueue hands a failing job back to the memory queue with the policy's
+// backoff until the policy gives up, the way a queue-level retry does.
+func TestRetryPolicyThroughQueue(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond, Multiplier: 2}
+	queue := NewMemoryQueue(1)
+	defer queue.Close()
+
+	if err := queue.Enqueue(context.Background(), Transaction{JobId: 1, Type: DepositTransaction, UserId: 1}); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+
+	failure := appErrors.NewDatabaseError(nil, "deadlock")
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		delivery, err := queue.Dequeue(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("Dequeue() of attempt %d unexpected error: %v", attempt, err)
+		}
+		if delivery.Attempts != attempt {
+			t.Fatalf("delivery attempts = %d, want %d", delivery.Attempts, attempt)
+		}
+
+		if !policy.ShouldRetry(failure, delivery.Attempts) {
+			if attempt != policy.MaxAttempts {
+				t.Fatalf("gave up after %d attempts, want %d", attempt, policy.MaxAttempts)
+			}
+			break
+		}
+
+		if err := queue.Nack(context.Background(), delivery, policy.Backoff(delivery.Attempts)); err != nil {
+			t.Fatalf("Nack() unexpected error: %v", err)
+		}
+		if depth, _ := queue.Len(context.Background()); depth != 0 {
+			t.Fatalf("job was redelivered before its backoff, queue depth = %d", depth)
+		}
+	}
+}
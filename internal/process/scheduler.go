@@ -0,0 +1,392 @@
+package process
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+)
+
+const (
+	schedulerBatchSize = 100
+	// submitGracePeriod is how long a claimed scheduled transaction may go without its job
+	// being confirmed as enqueued before another scheduler resubmits it.
+	submitGracePeriod = 1 * time.Minute
+)
+
+// Scheduler turns due scheduled transactions and standing order occurrences into jobs on the
+// worker pool, releases holds whose TTL has passed, settles and expires payment requests and
+// accrues and settles interest on savings and overdraft accounts. Claiming is done in the
+// database, so any number of instances can run a scheduler without executing anything twice,
+// and nothing is lost across restarts.
+type Scheduler struct {
+	scheduledRepo repositories.ScheduledTransactionRepository
+	orderRepo     repositories.StandingOrderRepository
+	holdRepo      repositories.HoldRepository
+	requestRepo   repositories.PaymentRequestRepository
+	savingsRepo   repositories.SavingsRepository
+	jobRepo       repositories.JobRepository
+	workerPool    *WorkerPool
+	interval      time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewScheduler(workerPool *WorkerPool, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		scheduledRepo: repositories.NewScheduledTransactionRepository(database.Db),
+		orderRepo:     repositories.NewStandingOrderRepository(database.Db),
+		holdRepo:      repositories.NewHoldRepository(database.Db),
+		requestRepo:   repositories.NewPaymentRequestRepository(database.Db),
+		savingsRepo:   repositories.NewSavingsRepository(database.Db),
+		jobRepo:       repositories.NewJobRepository(database.Db),
+		workerPool:    workerPool,
+		interval:      interval,
+	}
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.run(runCtx)
+	logger.Log.Infof("Transaction scheduler started, polling every %s", s.interval)
+}
+
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.resubmitStuck()
+		s.submitDue()
+		s.runStandingOrders()
+		s.expireHolds()
+		s.runPaymentRequests()
+		s.accrueInterest()
+		s.capitalizeInterest()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) submitDue() {
+	for {
+		claimed, err := s.scheduledRepo.ClaimDue(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to claim due scheduled transactions: %v", err)
+			return
+		}
+
+		for i := range claimed {
+			s.submit(&claimed[i], claimed[i].Job)
+		}
+
+		if len(claimed) < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) resubmitStuck() {
+	claimed, err := s.scheduledRepo.ClaimStuck(time.Now().Add(-submitGracePeriod), schedulerBatchSize)
+	if err != nil {
+		logger.Log.Errorf("Failed to claim stuck scheduled transactions: %v", err)
+		return
+	}
+
+	for i := range claimed {
+		scheduled := &claimed[i]
+		if scheduled.JobId == nil {
+			continue
+		}
+
+		job, err := s.jobRepo.GetByID(*scheduled.JobId)
+		if err != nil {
+			logger.Log.Errorf("Failed to load job of scheduled transaction %d: %v", scheduled.Id, err)
+			continue
+		}
+
+		logger.Log.Warnf("Resubmitting job %d of scheduled transaction %d", job.Id, scheduled.Id)
+		s.submit(scheduled, job)
+	}
+}
+
+// submit enqueues the job of a claimed scheduled transaction. If that fails the transaction
+// stays in submitting and is picked up again once the grace period has passed; enqueueing a
+// job twice is harmless because workers skip jobs that already finished.
+func (s *Scheduler) submit(scheduled *models.ScheduledTransaction, job *models.Job) {
+	tx := transactionFromJob(job)
+	tx.Date = scheduled.ScheduledAt
+
+	if err := s.workerPool.SubmitJob(tx); err != nil {
+		logger.Log.Errorf("Failed to submit scheduled transaction %d, will retry: %v", scheduled.Id, err)
+		return
+	}
+
+	if err := s.scheduledRepo.MarkSubmitted(scheduled.Id); err != nil {
+		logger.Log.Errorf("Failed to mark scheduled transaction %d as submitted: %v", scheduled.Id, err)
+	}
+}
+
+// runStandingOrders records the outcome of finished occurrences, creates occurrences for due
+// standing orders and submits the ones whose run time has come.
+func (s *Scheduler) runStandingOrders() {
+	s.settleOccurrences()
+	s.materializeOccurrences()
+	s.resubmitStuckOccurrences()
+	s.submitDueOccurrences()
+}
+
+func (s *Scheduler) settleOccurrences() {
+	for {
+		settled, err := s.orderRepo.SettleFinished(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to settle standing order occurrences: %v", err)
+			return
+		}
+
+		for _, occurrence := range settled {
+			if occurrence.Status == models.OccurrenceStatusFailed {
+				logger.Log.Warnf("Occurrence %d of standing order %d failed: %s", occurrence.Sequence, occurrence.StandingOrderId, occurrence.FailureReason)
+			}
+		}
+
+		if len(settled) < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+// materializeOccurrences keeps going until no order is due. Each pass creates one occurrence
+// per due order, so an order that fell behind, e.g. while every instance was down, is still due
+// after a short pass and catches up on its missed occurrences one pass at a time.
+func (s *Scheduler) materializeOccurrences() {
+	for {
+		count, err := s.orderRepo.MaterializeDue(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to create due standing order occurrences: %v", err)
+			return
+		}
+
+		if count == 0 {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) submitDueOccurrences() {
+	for {
+		claimed, err := s.orderRepo.ClaimPendingOccurrences(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to claim pending standing order occurrences: %v", err)
+			return
+		}
+
+		for i := range claimed {
+			s.submitOccurrence(&claimed[i], claimed[i].Job)
+		}
+
+		if len(claimed) < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) resubmitStuckOccurrences() {
+	claimed, err := s.orderRepo.ClaimStuckOccurrences(time.Now().Add(-submitGracePeriod), schedulerBatchSize)
+	if err != nil {
+		logger.Log.Errorf("Failed to claim stuck standing order occurrences: %v", err)
+		return
+	}
+
+	for i := range claimed {
+		occurrence := &claimed[i]
+		if occurrence.JobId == nil {
+			continue
+		}
+
+		job, err := s.jobRepo.GetByID(*occurrence.JobId)
+		if err != nil {
+			logger.Log.Errorf("Failed to load job of standing order occurrence %d: %v", occurrence.Id, err)
+			continue
+		}
+
+		logger.Log.Warnf("Resubmitting job %d of standing order occurrence %d", job.Id, occurrence.Id)
+		s.submitOccurrence(occurrence, job)
+	}
+}
+
+func (s *Scheduler) submitOccurrence(occurrence *models.StandingOrderOccurrence, job *models.Job) {
+	tx := transactionFromJob(job)
+	tx.Date = occurrence.RunAt
+
+	if err := s.workerPool.SubmitJob(tx); err != nil {
+		logger.Log.Errorf("Failed to submit standing order occurrence %d, will retry: %v", occurrence.Id, err)
+		return
+	}
+
+	if err := s.orderRepo.MarkOccurrenceSubmitted(occurrence.Id); err != nil {
+		logger.Log.Errorf("Failed to mark standing order occurrence %d as submitted: %v", occurrence.Id, err)
+	}
+}
+
+func (s *Scheduler) expireHolds() {
+	for {
+		count, err := s.holdRepo.ExpireDue(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to expire holds: %v", err)
+			return
+		}
+
+		if count > 0 {
+			logger.Log.Infof("Released %d expired holds", count)
+		}
+
+		if count < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+// runPaymentRequests resubmits transfers of accepted payment requests that never reached the
+// worker pool, records the outcome of finished ones and expires pending requests nobody
+// answered in time.
+func (s *Scheduler) runPaymentRequests() {
+	s.resubmitPaymentRequests()
+	s.settlePaymentRequests()
+	s.expirePaymentRequests()
+}
+
+func (s *Scheduler) resubmitPaymentRequests() {
+	claimed, err := s.requestRepo.ClaimUnsubmitted(time.Now().Add(-submitGracePeriod), schedulerBatchSize)
+	if err != nil {
+		logger.Log.Errorf("Failed to claim unsubmitted payment requests: %v", err)
+		return
+	}
+
+	for i := range claimed {
+		request := &claimed[i]
+		if request.Job == nil {
+			continue
+		}
+
+		logger.Log.Warnf("Resubmitting job %d of payment request %d", request.Job.Id, request.Id)
+		if err := s.workerPool.SubmitJob(transactionFromJob(request.Job)); err != nil {
+			logger.Log.Errorf("Failed to submit payment request %d, will retry: %v", request.Id, err)
+			continue
+		}
+
+		if err := s.requestRepo.MarkSubmitted(request.Id, time.Now()); err != nil {
+			logger.Log.Errorf("Failed to mark payment request %d as submitted: %v", request.Id, err)
+		}
+	}
+}
+
+func (s *Scheduler) settlePaymentRequests() {
+	for {
+		settled, err := s.requestRepo.SettleFinished(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to settle payment requests: %v", err)
+			return
+		}
+
+		for _, request := range settled {
+			if request.Status == models.PaymentRequestStatusPending {
+				logger.Log.Warnf("Transfer for payment request %d failed: %s", request.Id, request.FailureReason)
+			}
+		}
+
+		if len(settled) < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) expirePaymentRequests() {
+	for {
+		count, err := s.requestRepo.ExpireDue(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to expire payment requests: %v", err)
+			return
+		}
+
+		if count > 0 {
+			logger.Log.Infof("Expired %d payment requests", count)
+		}
+
+		if count < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) accrueInterest() {
+	for {
+		count, err := s.savingsRepo.AccrueDue(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to accrue interest: %v", err)
+			return
+		}
+
+		if count < schedulerBatchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) capitalizeInterest() {
+	for {
+		count, err := s.savingsRepo.CapitalizeDue(time.Now(), schedulerBatchSize)
+		if err != nil {
+			logger.Log.Errorf("Failed to capitalize interest: %v", err)
+			return
+		}
+
+		if count > 0 {
+			logger.Log.Infof("Paid interest on %d savings accounts", count)
+		}
+
+		if count < schedulerBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package process
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+)
+
+// prefetchPerWorker bounds how many dequeued jobs may wait in the sequencer per worker.
+const prefetchPerWorker = 2
+
+type sequencedState int
+
+const (
+	sequencedWaiting sequencedState = iota
+	sequencedReady
+	sequencedRunning
+	sequencedRetrying
+)
+
+type sequencedJob struct {
+	delivery   *Delivery
+	accounts   []string
+	state      sequencedState
+	retryDelay time.Duration
+	timer      *time.Timer
+}
+
+// sequencer hands dequeued jobs to workers so that jobs touching the same account run one at
+// a time in the order they were dequeued, while jobs for different accounts run in parallel.
+// A job waits in one lane per account it touches and becomes ready once it heads all of them.
+// Lanes are appended to in a single global order, so a transfer waiting on two accounts can
+// never deadlock with another one waiting on the same accounts in reverse.
+//
+// A job that is retried keeps its place at the head of its lanes until it finally succeeds or
+// fails, so later jobs for the same account cannot overtake it.
+type sequencer struct {
+	mu     sync.Mutex
+	lanes  map[string][]*sequencedJob
+	jobs   map[string]*sequencedJob
+	ready  chan *Delivery
+	slots  chan struct{}
+	closed bool
+}
+
+func newSequencer(window int) *sequencer {
+	return &sequencer{
+		lanes: make(map[string][]*sequencedJob),
+		jobs:  make(map[string]*sequencedJob),
+		ready: make(chan *Delivery, window),
+		slots: make(chan struct{}, window),
+	}
+}
+
+// acquire blocks until there is room for another job.
+func (s *sequencer) acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *sequencer) releaseSlot() {
+	<-s.slots
+}
+
+// add places a delivery in the lanes of its accounts. A redelivery of a job the sequencer
+// already holds (e.g. after its queue lease expired during a retry backoff) is ignored.
+func (s *sequencer) add(delivery *Delivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[delivery.Id]; ok || s.closed {
+		s.releaseSlot()
+		return
+	}
+
+	job := &sequencedJob{
+		delivery: delivery,
+		accounts: jobAccounts(delivery.Job),
+	}
+	s.jobs[delivery.Id] = job
+	for _, account := range job.accounts {
+		s.lanes[account] = append(s.lanes[account], job)
+	}
+
+	s.promote(job)
+}
+
+// begin marks a ready delivery as running. It returns false if the delivery is no longer held
+// or the sequencer was closed.
+func (s *sequencer) begin(delivery *Delivery) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[delivery.Id]
+	if !ok || s.closed || job.state != sequencedReady {
+		return false
+	}
+	job.state = sequencedRunning
+	return true
+}
+
+func (s *sequencer) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// retry runs the delivery again after delay without giving up its place in its lanes. The
+// delay only starts once the current run has finished, so the job is never handed to another
+// worker while it is still running.
+func (s *sequencer) retry(delivery *Delivery, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[delivery.Id]
+	if !ok || s.closed {
+		return
+	}
+
+	job.state = sequencedRetrying
+	job.retryDelay = delay
+}
+
+// finish ends the run of a delivery. A delivery scheduled for a retry is handed out again once
+// its delay has passed; any other delivery is removed, releasing the next job of each of its
+// accounts. Deliveries that are not running are left alone.
+func (s *sequencer) finish(delivery *Delivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[delivery.Id]
+	if !ok {
+		return
+	}
+
+	if job.state == sequencedRetrying {
+		if job.timer == nil && !s.closed {
+			job.timer = time.AfterFunc(job.retryDelay, func() { s.rerun(job) })
+		}
+		return
+	}
+	if job.state != sequencedRunning {
+		return
+	}
+
+	delete(s.jobs, delivery.Id)
+	s.releaseSlot()
+
+	for _, account := range job.accounts {
+		lane := removeSequenced(s.lanes[account], job)
+		if len(lane) == 0 {
+			delete(s.lanes, account)
+			continue
+		}
+		s.lanes[account] = lane
+		if !s.closed {
+			s.promote(lane[0])
+		}
+	}
+}
+
+func (s *sequencer) rerun(job *sequencedJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || job.state != sequencedRetrying {
+		return
+	}
+	job.timer = nil
+	job.delivery.Attempts++
+	job.state = sequencedReady
+	s.ready <- job.delivery
+}
+
+// close stops handing out jobs and returns the deliveries that have not been processed, so
+// they can be handed back to the queue. Running deliveries are included if requested.
+func (s *sequencer) close(includeRunning bool) []*Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	pending := make([]*Delivery, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if job.timer != nil {
+			job.timer.Stop()
+		}
+		if job.state == sequencedRunning && !includeRunning {
+			continue
+		}
+		pending = append(pending, job.delivery)
+	}
+	return pending
+}
+
+func (s *sequencer) promote(job *sequencedJob) {
+	if job.state != sequencedWaiting {
+		return
+	}
+	for _, account := range job.accounts {
+		if s.lanes[account][0] != job {
+			return
+		}
+	}
+
+	job.state = sequencedReady
+	s.ready <- job.delivery
+}
+
+func removeSequenced(lane []*sequencedJob, job *sequencedJob) []*sequencedJob {
+	for i, queued := range lane {
+		if queued == job {
+			return append(lane[:i], lane[i+1:]...)
+		}
+	}
+	return lane
+}
+
+func jobAccounts(job Transaction) []string {
+	accounts := []string{models.UserAccount(job.UserId)}
+	if job.Type == TransferTransaction && job.ToUserId != 0 && job.ToUserId != job.UserId {
+		accounts = append(accounts, models.UserAccount(job.ToUserId))
+	}
+	return accounts
+}
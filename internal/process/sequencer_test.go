@@ -0,0 +1,168 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// sequencerHarness feeds a sequencer from a memory queue the way WorkerPool.dispatch does and
+// takes ready jobs off it the way a worker does.
+type sequencerHarness struct {
+	t         *testing.T
+	queue     Queue
+	sequencer *sequencer
+}
+
+func newSequencerHarness(t *testing.T, window int, jobs ...Transaction) *sequencerHarness {
+	h := &sequencerHarness{t: t, queue: NewMemoryQueue(len(jobs) + 1), sequencer: newSequencer(window)}
+	t.Cleanup(func() {
+		h.sequencer.close(true)
+		h.queue.Close()
+	})
+
+	for _, job := range jobs {
+		if err := h.queue.Enqueue(context.Background(), job); err != nil {
+			t.Fatalf("Enqueue() unexpected error: %v", err)
+		}
+	}
+	return h
+}
+
+// dispatch moves the next queued job into the sequencer.
+func (h *sequencerHarness) dispatch() {
+	h.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := h.sequencer.acquire(ctx); err != nil {
+		h.t.Fatalf("acquire() unexpected error: %v", err)
+	}
+	delivery, err := h.queue.Dequeue(ctx)
+	if err != nil {
+		h.t.Fatalf("Dequeue() unexpected error: %v", err)
+	}
+	h.sequencer.add(delivery)
+}
+
+// start takes the next ready job, which must be jobId, and marks it running.
+func (h *sequencerHarness) start(jobId uint) *Delivery {
+	h.t.Helper()
+
+	select {
+	case delivery := <-h.sequencer.ready:
+		if delivery.Job.JobId != jobId {
+			h.t.Fatalf("job %d is ready, want job %d", delivery.Job.JobId, jobId)
+		}
+		if !h.sequencer.begin(delivery) {
+			h.t.Fatalf("begin() of ready job %d = false", jobId)
+		}
+		return delivery
+	case <-time.After(time.Second):
+		h.t.Fatalf("job %d did not become ready", jobId)
+		return nil
+	}
+}
+
+// expectNoneReady checks that every held job is waiting for an earlier one.
+func (h *sequencerHarness) expectNoneReady() {
+	h.t.Helper()
+
+	select {
+	case delivery := <-h.sequencer.ready:
+		h.t.Fatalf("job %d is ready, want it to wait", delivery.Job.JobId)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSequencerOrdersJobsPerUser(t *testing.T) {
+	h := newSequencerHarness(t, 8,
+		Transaction{JobId: 1, Type: DepositTransaction, UserId: 1},
+		Transaction{JobId: 2, Type: DepositTransaction, UserId: 2},
+		Transaction{JobId: 3, Type: WithdrawTransaction, UserId: 1},
+		Transaction{JobId: 4, Type: TransferTransaction, UserId: 2, ToUserId: 1},
+		Transaction{JobId: 5, Type: DepositTransaction, UserId: 3},
+	)
+	for range 5 {
+		h.dispatch()
+	}
+
+	// Jobs of different users run in parallel; later jobs of a user wait for earlier ones.
+	first := h.start(1)
+	second := h.start(2)
+	h.start(5)
+	h.expectNoneReady()
+
+	h.sequencer.finish(first)
+	third := h.start(3)
+
+	// The transfer heads user 2's lane, but still waits behind the withdrawal of user 1.
+	h.sequencer.finish(second)
+	h.expectNoneReady()
+
+	h.sequencer.finish(third)
+	h.start(4)
+}
+
+func TestSequencerRetryKeepsLanePosition(t *testing.T) {
+	h := newSequencerHarness(t, 8,
+		Transaction{JobId: 1, Type: WithdrawTransaction, UserId: 1},
+		Transaction{JobId: 2, Type: WithdrawTransaction, UserId: 1},
+		Transaction{JobId: 3, Type: TransferTransaction, UserId: 2, ToUserId: 1},
+	)
+	for range 3 {
+		h.dispatch()
+	}
+
+	delivery := h.start(1)
+	h.sequencer.retry(delivery, 50*time.Millisecond)
+	h.sequencer.finish(delivery)
+
+	// Later jobs of the user must not overtake the job during its backoff.
+	h.expectNoneReady()
+
+	retried := h.start(1)
+	if retried.Attempts != 2 {
+		t.Errorf("retried job attempts = %d, want 2", retried.Attempts)
+	}
+
+	// A redelivery of the held job, e.g. after its queue lease expired, is ignored.
+	if err := h.queue.Nack(context.Background(), retried, 0); err != nil {
+		t.Fatalf("Nack() unexpected error: %v", err)
+	}
+	h.dispatch()
+	h.expectNoneReady()
+
+	h.sequencer.finish(retried)
+	next := h.start(2)
+	h.sequencer.finish(next)
+	h.start(3)
+}
+
+func TestSequencerWindow(t *testing.T) {
+	h := newSequencerHarness(t, 2,
+		Transaction{JobId: 1, Type: DepositTransaction, UserId: 1},
+		Transaction{JobId: 2, Type: DepositTransaction, UserId: 1},
+		Transaction{JobId: 3, Type: DepositTransaction, UserId: 2},
+	)
+	h.dispatch()
+	h.dispatch()
+
+	// Two jobs of one user fill the window, so the third one, which could run right away,
+	// stays in the queue until a job finishes.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := h.sequencer.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire() with a full window error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if depth, _ := h.queue.Len(context.Background()); depth != 1 {
+		t.Errorf("queue depth = %d, want 1", depth)
+	}
+
+	h.sequencer.finish(h.start(1))
+	h.dispatch()
+	h.start(2)
+	h.start(3)
+}
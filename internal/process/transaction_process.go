@@ -1,14 +1,16 @@
 package process
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/yusuffugurlu/go-project/config/logger"
-	"github.com/yusuffugurlu/go-project/internal/database"
 	"github.com/yusuffugurlu/go-project/internal/models"
 	"github.com/yusuffugurlu/go-project/internal/repositories"
 	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
 )
 
 type TransactionType string
@@ -20,123 +22,203 @@ const (
 	DebitTransaction    TransactionType = "debit"
 )
 
+// Transaction is the payload of a queued job. Without account ids, money moves out of and
+// into the users' default accounts in the amount's currency.
 type Transaction struct {
-	Amount   float32         `json:"amount" validate:"required"`
-	UserId   uint            `json:"user_id" validate:"required"`
-	ToUserId uint            `json:"to_user_id"`
-	Date     time.Time       `json:"date"`
-	Type     TransactionType `json:"type" validate:"required"`
+	JobId         uint            `json:"job_id"`
+	Amount        money.Money     `json:"amount" validate:"required,gt=0"`
+	UserId        uint            `json:"user_id" validate:"required"`
+	ToUserId      uint            `json:"to_user_id"`
+	FromAccountId uint            `json:"from_account_id,omitempty"`
+	ToAccountId   uint            `json:"to_account_id,omitempty"`
+	Date          time.Time       `json:"date"`
+	Type          TransactionType `json:"type" validate:"required"`
 }
 
-const (
-	maxQueueSize = 100
-)
+func (wp *WorkerPool) process(id int, delivery *Delivery) {
+	job := delivery.Job
+	logger.Log.Infof("Worker %d RECEIVED job %d (attempt %d) for UserID %d: Type %s, Amount %s", id, job.JobId, delivery.Attempts, job.UserId, job.Type, job.Amount)
 
-var JobQueue chan Transaction
+	jobModel, err := wp.jobRepo.GetByID(job.JobId)
+	if err != nil {
+		if appErrors.GetStatusCode(err) == appErrors.ErrCodeNotFound {
+			logger.Log.Errorf("Worker %d DROPPED job %d: no such job", id, job.JobId)
+			wp.ack(delivery)
+			return
+		}
+		logger.Log.Errorf("Worker %d could not load job %d, retrying: %v", id, job.JobId, err)
+		wp.sequencer.retry(delivery, queueErrorBackoff)
+		return
+	}
 
-type WorkerPool struct {
-	balanceRepo     repositories.BalancesRepository
-	transactionRepo repositories.TransactionRepository
-}
+	// Deliveries are at-least-once; a job that already reached a final state is acknowledged without running again.
+	if jobModel.IsFinished() {
+		wp.ack(delivery)
+		return
+	}
 
-func InitWorkerPool(numWorkers int) *WorkerPool {
-	JobQueue = make(chan Transaction, maxQueueSize)
-	wp := &WorkerPool{
-		balanceRepo:     repositories.NewBalancesRepository(database.Db),
-		transactionRepo: repositories.NewTransactionRepository(database.Db),
+	// A run whose ledger transaction committed may not have been acknowledged, e.g. after an
+	// ambiguous commit error, a crash or an expired lease, and the job may have been put back
+	// to queued for a retry since; either way the money has moved and must not move again.
+	existing, err := wp.transactionRepo.GetByJobID(job.JobId)
+	if err == nil {
+		wp.complete(id, job, existing)
+		wp.ack(delivery)
+		return
+	}
+	if appErrors.GetStatusCode(err) != appErrors.ErrCodeNotFound {
+		logger.Log.Errorf("Worker %d could not check job %d for a recorded transaction, retrying: %v", id, job.JobId, err)
+		wp.sequencer.retry(delivery, queueErrorBackoff)
+		return
+	}
+
+	if err := wp.jobRepo.MarkProcessing(job.JobId); err != nil {
+		if IsRetryable(err) {
+			logger.Log.Errorf("Worker %d could not start job %d, retrying: %v", id, job.JobId, err)
+			wp.sequencer.retry(delivery, queueErrorBackoff)
+			return
+		}
+		logger.Log.Errorf("Worker %d SKIPPED job %d: %v", id, job.JobId, err)
+		wp.ack(delivery)
+		return
 	}
 
-	for i := 1; i <= numWorkers; i++ {
-		go wp.worker(i, JobQueue)
+	transaction, err := wp.execute(job)
+	if repositories.IsJobAlreadyRecorded(err) {
+		// Another run of the job committed its transaction since the check above.
+		transaction, err = wp.transactionRepo.GetByJobID(job.JobId)
+	}
+	if err != nil {
+		logger.Log.Infof("Worker %d ERROR: UserID %d, Type %s, Amount %s - Error: %v", id, job.UserId, job.Type, job.Amount, err)
+		wp.fail(id, delivery, err)
+		return
 	}
-	logger.Log.Infof("%d workers started.", numWorkers)
-	return wp
+
+	wp.complete(id, job, transaction)
+	wp.ack(delivery)
 }
 
-func (wp *WorkerPool) worker(id int, jobs <-chan Transaction) {
-	logger.Log.Infof("Worker %d started and waiting for jobs", id)
-	for job := range jobs {
-		logger.Log.Infof("Worker %d RECEIVED job for UserID %d: Type %s, Amount %.2f", id, job.UserId, job.Type, job.Amount)
-		var err error
-
-		switch job.Type {
-		case DepositTransaction:
-			err = wp.balanceRepo.Deposit(job.UserId, float64(job.Amount))
-			if err == nil {
-				transaction := &models.Transaction{
-					FromUserId: nil,
-					ToUserId:   &job.UserId,
-					Amount:     float64(job.Amount),
-					Type:       "deposit",
-					Status:     "completed",
-					CreatedAt:  time.Now(),
-				}
-				wp.transactionRepo.Create(transaction)
-			}
-
-		case WithdrawTransaction:
-			err = wp.balanceRepo.Withdraw(job.UserId, float64(job.Amount))
-			if err == nil {
-				transaction := &models.Transaction{
-					FromUserId: &job.UserId,
-					ToUserId:   nil,
-					Amount:     float64(job.Amount),
-					Type:       "withdraw",
-					Status:     "completed",
-					CreatedAt:  time.Now(),
-				}
-				wp.transactionRepo.Create(transaction)
-			}
-
-		case DebitTransaction:
-			err = wp.balanceRepo.Deposit(job.UserId, float64(job.Amount))
-			if err == nil {
-				transaction := &models.Transaction{
-					FromUserId: nil,
-					ToUserId:   &job.UserId,
-					Amount:     float64(job.Amount),
-					Type:       "debit",
-					Status:     "completed",
-					CreatedAt:  time.Now(),
-				}
-				wp.transactionRepo.Create(transaction)
-			}
-
-		case TransferTransaction:
-			err = wp.balanceRepo.Transfer(job.UserId, job.ToUserId, float64(job.Amount))
-			if err == nil {
-				transaction := &models.Transaction{
-					FromUserId: &job.UserId,
-					ToUserId:   &job.ToUserId,
-					Amount:     float64(job.Amount),
-					Type:       "transfer",
-					Status:     "completed",
-					CreatedAt:  time.Now(),
-				}
-				wp.transactionRepo.Create(transaction)
-			}
-
-		default:
-			err = fmt.Errorf("unknown transaction type: %s", job.Type)
+func (wp *WorkerPool) fail(id int, delivery *Delivery, err error) {
+	job := delivery.Job
+	reason := failureReason(err)
+
+	if wp.retryPolicy.ShouldRetry(err, delivery.Attempts) {
+		backoff := wp.retryPolicy.Backoff(delivery.Attempts)
+		logger.Log.Warnf("Worker %d RETRYING job %d in %s (attempt %d of %d)", id, job.JobId, backoff, delivery.Attempts, wp.retryPolicy.MaxAttempts)
+		if markErr := wp.jobRepo.MarkRetrying(job.JobId, reason); markErr != nil {
+			logger.Log.Errorf("Worker %d failed to mark job %d for retry: %v", id, job.JobId, markErr)
 		}
+		wp.sequencer.retry(delivery, backoff)
+		return
+	}
 
-		if err != nil {
-			logger.Log.Infof("Worker %d ERROR: UserID %d, Type %s, Amount %.2f - Error: %v", id, job.UserId, job.Type, job.Amount, err)
-		} else {
-			logger.Log.Infof("Worker %d SUCCESS: UserID %d, Type %s, Amount %.2f", id, job.UserId, job.Type, job.Amount)
+	if IsRetryable(err) {
+		if dlErr := wp.deadLetter(delivery, reason); dlErr != nil {
+			logger.Log.Errorf("Worker %d failed to dead-letter job %d, retrying: %v", id, job.JobId, dlErr)
+			wp.sequencer.retry(delivery, wp.retryPolicy.MaxBackoff)
+			return
 		}
+	}
+
+	if markErr := wp.jobRepo.MarkFailed(job.JobId, reason); markErr != nil {
+		logger.Log.Errorf("Worker %d failed to mark job %d as failed: %v", id, job.JobId, markErr)
+	}
+
+	wp.ack(delivery)
+}
+
+func (wp *WorkerPool) deadLetter(delivery *Delivery, reason string) error {
+	payload, err := json.Marshal(delivery.Job)
+	if err != nil {
+		return err
+	}
 
-		time.Sleep(1000 * time.Millisecond)
+	if err := wp.deadLetterRepo.Create(&models.DeadLetter{
+		JobId:    &delivery.Job.JobId,
+		Payload:  string(payload),
+		Error:    reason,
+		Attempts: delivery.Attempts,
+	}); err != nil {
+		return err
 	}
-	logger.Log.Infof("Worker %d stopped.\n", id)
+
+	logger.Log.Warnf("Job %d moved to the dead-letter store after %d attempts: %s", delivery.Job.JobId, delivery.Attempts, reason)
+	return nil
 }
 
-func (wp *WorkerPool) SubmitJob(tx Transaction) error {
-	select {
-	case JobQueue <- tx:
-		logger.Log.Infof("Job added to queue: UserID %d, Type %s, Amount %.2f", tx.UserId, tx.Type, tx.Amount)
-		return nil
+// deadLetterMalformed moves a message that could not be decoded into a job straight to the
+// dead-letter store; retrying it could never succeed. If that fails the message is left
+// unacknowledged and redelivered after the visibility timeout.
+func (wp *WorkerPool) deadLetterMalformed(delivery *Delivery) {
+	payload := delivery.Payload
+	if !json.Valid([]byte(payload)) {
+		raw, _ := json.Marshal(payload)
+		payload = string(raw)
+	}
+
+	reason := fmt.Sprintf("undecodable message: %v", delivery.DecodeErr)
+	if err := wp.deadLetterRepo.Create(&models.DeadLetter{
+		Payload:  payload,
+		Error:    reason,
+		Attempts: delivery.Attempts,
+	}); err != nil {
+		logger.Log.Errorf("Failed to dead-letter message %s: %v", delivery.Id, err)
+		return
+	}
+
+	logger.Log.Warnf("Message %s moved to the dead-letter store: %s", delivery.Id, reason)
+	wp.ack(delivery)
+}
+
+func (wp *WorkerPool) execute(job Transaction) (*models.Transaction, error) {
+	ledger := wp.ledgerRepo.ForJob(job.JobId)
+	if job.FromAccountId != 0 {
+		ledger = ledger.FromAccount(job.FromAccountId)
+	}
+	if job.ToAccountId != 0 {
+		ledger = ledger.ToAccount(job.ToAccountId)
+	}
+
+	switch job.Type {
+	case DepositTransaction:
+		return ledger.Deposit(job.UserId, job.Amount, models.TransactionTypeDeposit)
+
+	case WithdrawTransaction:
+		return ledger.Withdraw(job.UserId, job.Amount)
+
+	case DebitTransaction:
+		return ledger.Deposit(job.UserId, job.Amount, models.TransactionTypeDebit)
+
+	case TransferTransaction:
+		return ledger.Transfer(job.UserId, job.ToUserId, job.Amount)
+
 	default:
-		return appErrors.NewConflict(nil, "job queue is full, please try again later")
+		return nil, fmt.Errorf("unknown transaction type: %s", job.Type)
+	}
+}
+
+func (wp *WorkerPool) complete(id int, job Transaction, transaction *models.Transaction) {
+	logger.Log.Infof("Worker %d SUCCESS: UserID %d, Type %s, Amount %s", id, job.UserId, job.Type, job.Amount)
+	if err := wp.jobRepo.MarkCompleted(job.JobId, &transaction.Id); err != nil {
+		logger.Log.Errorf("Worker %d failed to mark job %d as completed: %v", id, job.JobId, err)
+	}
+}
+
+func (wp *WorkerPool) ack(delivery *Delivery) {
+	if err := wp.queue.Ack(context.Background(), delivery); err != nil {
+		logger.Log.Errorf("Failed to acknowledge job %d: %v", delivery.Job.JobId, err)
+	}
+}
+
+func (wp *WorkerPool) nack(delivery *Delivery, delay time.Duration) {
+	if err := wp.queue.Nack(context.Background(), delivery, delay); err != nil {
+		logger.Log.Errorf("Failed to hand back job %d: %v", delivery.Job.JobId, err)
+	}
+}
+
+func failureReason(err error) string {
+	if appErr, ok := appErrors.AsAppError(err); ok {
+		return appErr.Message
 	}
+	return err.Error()
 }
@@ -0,0 +1,446 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/metrics"
+)
+
+const (
+	queueErrorBackoff = 1 * time.Second
+
+	// MaxWorkers caps the pool size, whether set manually or by the autoscaler.
+	MaxWorkers = 500
+)
+
+type WorkerPoolOptions struct {
+	Workers int
+	// Pacing is an optional pause each worker takes after a job; zero disables it.
+	Pacing    time.Duration
+	Autoscale AutoscaleOptions
+}
+
+// AutoscaleOptions sizes the pool so that each worker has about JobsPerWorker queued jobs,
+// within [MinWorkers, MaxWorkers]. Queue depth is sampled every Interval, also when
+// autoscaling is disabled, to keep the queue depth metric current.
+type AutoscaleOptions struct {
+	Enabled       bool
+	MinWorkers    int
+	MaxWorkers    int
+	JobsPerWorker int
+	Interval      time.Duration
+}
+
+type WorkerPoolStatus struct {
+	Workers    int
+	Busy       int
+	QueueDepth int64
+	Pacing     time.Duration
+	Autoscale  bool
+	MinWorkers int
+	MaxWorkers int
+}
+
+type WorkerPool struct {
+	queue           Queue
+	ledgerRepo      repositories.LedgerRepository
+	jobRepo         repositories.JobRepository
+	transactionRepo repositories.TransactionRepository
+	deadLetterRepo  repositories.DeadLetterRepository
+	retryPolicy     RetryPolicy
+	opts            WorkerPoolOptions
+	sequencer       *sequencer
+
+	mu           sync.Mutex
+	accepting    bool
+	autoscale    bool
+	runCtx       context.Context
+	cancel       context.CancelFunc
+	workers      sync.WaitGroup
+	stopWorker   []context.CancelFunc
+	nextWorkerId int
+	busy         int
+	queueDepth   int64
+}
+
+func NewWorkerPool(queue Queue, retryPolicy RetryPolicy, opts WorkerPoolOptions) *WorkerPool {
+	opts.Workers = clampWorkers(opts.Workers, 1, MaxWorkers)
+	opts.Autoscale.MinWorkers = clampWorkers(opts.Autoscale.MinWorkers, 1, MaxWorkers)
+	opts.Autoscale.MaxWorkers = clampWorkers(opts.Autoscale.MaxWorkers, opts.Autoscale.MinWorkers, MaxWorkers)
+	if opts.Autoscale.JobsPerWorker < 1 {
+		opts.Autoscale.JobsPerWorker = 1
+	}
+	if opts.Autoscale.Interval <= 0 {
+		opts.Autoscale.Interval = 10 * time.Second
+	}
+
+	return &WorkerPool{
+		queue:           queue,
+		ledgerRepo:      repositories.NewLedgerRepository(database.Db),
+		jobRepo:         repositories.NewJobRepository(database.Db),
+		transactionRepo: repositories.NewTransactionRepository(database.Db),
+		deadLetterRepo:  repositories.NewDeadLetterRepository(database.Db),
+		retryPolicy:     retryPolicy,
+		opts:            opts,
+		autoscale:       opts.Autoscale.Enabled,
+		sequencer:       newSequencer(prefetchPerWorker * max(opts.Workers, opts.Autoscale.MaxWorkers)),
+	}
+}
+
+// Start launches the workers. They stop pulling new jobs once ctx is cancelled or Stop is called.
+func (wp *WorkerPool) Start(ctx context.Context) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.cancel != nil {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	wp.runCtx = runCtx
+	wp.cancel = cancel
+	wp.accepting = true
+
+	if _, ok := wp.queue.(*memoryQueue); ok {
+		wp.recoverPendingJobs(runCtx)
+	}
+
+	workers := wp.opts.Workers
+	if wp.autoscale {
+		workers = clampWorkers(workers, wp.opts.Autoscale.MinWorkers, wp.opts.Autoscale.MaxWorkers)
+	}
+	wp.resizeLocked(workers)
+
+	wp.workers.Add(2)
+	go wp.dispatch(runCtx)
+	go wp.monitor(runCtx)
+
+	logger.Log.Infof("%d workers started.", workers)
+}
+
+// Stop rejects new submissions, lets in-flight jobs finish until ctx expires and hands the
+// remaining ones back to the queue so they are redelivered.
+func (wp *WorkerPool) Stop(ctx context.Context) error {
+	wp.mu.Lock()
+	wp.accepting = false
+	cancel := wp.cancel
+	wp.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wp.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		wp.handBack(wp.sequencer.close(false))
+		logger.Log.Info("All workers drained.")
+		return nil
+	case <-ctx.Done():
+	}
+
+	logger.Log.Warn("Shutdown deadline reached, handing back in-flight jobs")
+	wp.handBack(wp.sequencer.close(true))
+
+	return ctx.Err()
+}
+
+func (wp *WorkerPool) SubmitJob(tx Transaction) error {
+	wp.mu.Lock()
+	accepting := wp.accepting
+	wp.mu.Unlock()
+
+	if !accepting {
+		return appErrors.NewServiceUnavailable(nil, "worker pool is not accepting jobs, please try again later")
+	}
+
+	err := wp.queue.Enqueue(context.Background(), tx)
+	if errors.Is(err, ErrQueueFull) {
+		return appErrors.NewConflict(err, "job queue is full, please try again later")
+	}
+	if err != nil {
+		return appErrors.NewInternalServerError(err)
+	}
+
+	logger.Log.Infof("Job added to queue: UserID %d, Type %s, Amount %s", tx.UserId, tx.Type, tx.Amount)
+	return nil
+}
+
+// SubmitStoredJob enqueues a job that was already created in the database.
+func (wp *WorkerPool) SubmitStoredJob(job *models.Job) error {
+	return wp.SubmitJob(transactionFromJob(job))
+}
+
+// Resize changes the number of workers. Workers that are removed finish their current job first.
+func (wp *WorkerPool) Resize(workers int) error {
+	if workers < 1 || workers > MaxWorkers {
+		return appErrors.NewBadRequest(nil, fmt.Sprintf("worker count must be between 1 and %d", MaxWorkers))
+	}
+
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.autoscale {
+		return appErrors.NewConflict(nil, "worker pool is autoscaling, disable autoscaling before resizing it manually")
+	}
+	if !wp.accepting {
+		return appErrors.NewServiceUnavailable(nil, "worker pool is not running")
+	}
+
+	logger.Log.Infof("Resizing worker pool from %d to %d workers", len(wp.stopWorker), workers)
+	wp.resizeLocked(workers)
+	return nil
+}
+
+func (wp *WorkerPool) SetAutoscale(enabled bool) {
+	wp.mu.Lock()
+	wp.autoscale = enabled
+	wp.mu.Unlock()
+
+	logger.Log.Infof("Worker pool autoscaling enabled: %t", enabled)
+}
+
+func (wp *WorkerPool) Status() WorkerPoolStatus {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	return WorkerPoolStatus{
+		Workers:    len(wp.stopWorker),
+		Busy:       wp.busy,
+		QueueDepth: wp.queueDepth,
+		Pacing:     wp.opts.Pacing,
+		Autoscale:  wp.autoscale,
+		MinWorkers: wp.opts.Autoscale.MinWorkers,
+		MaxWorkers: wp.opts.Autoscale.MaxWorkers,
+	}
+}
+
+func (wp *WorkerPool) resizeLocked(workers int) {
+	for len(wp.stopWorker) < workers {
+		ctx, stop := context.WithCancel(wp.runCtx)
+		wp.stopWorker = append(wp.stopWorker, stop)
+		wp.nextWorkerId++
+		wp.workers.Add(1)
+		go wp.worker(ctx, wp.nextWorkerId)
+	}
+
+	for len(wp.stopWorker) > workers {
+		last := len(wp.stopWorker) - 1
+		wp.stopWorker[last]()
+		wp.stopWorker = wp.stopWorker[:last]
+	}
+
+	metrics.SetWorkerPoolUsage(len(wp.stopWorker), wp.busy)
+}
+
+// monitor samples the queue depth and, when autoscaling is enabled, resizes the pool to match it.
+func (wp *WorkerPool) monitor(ctx context.Context) {
+	defer wp.workers.Done()
+
+	ticker := time.NewTicker(wp.opts.Autoscale.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		depth, err := wp.queue.Len(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Log.Errorf("Failed to read job queue depth: %v", err)
+			}
+			continue
+		}
+		metrics.SetJobQueueDepth(float64(depth))
+
+		wp.mu.Lock()
+		wp.queueDepth = depth
+		if wp.autoscale && wp.accepting {
+			current := len(wp.stopWorker)
+			if desired := wp.desiredWorkers(depth, current); desired != current {
+				logger.Log.Infof("Autoscaling worker pool from %d to %d workers (queue depth %d)", current, desired, depth)
+				wp.resizeLocked(desired)
+			}
+		}
+		wp.mu.Unlock()
+	}
+}
+
+// desiredWorkers scales up straight to the target but only halves the pool per tick when
+// scaling down, so a briefly empty queue does not tear down every worker.
+func (wp *WorkerPool) desiredWorkers(depth int64, current int) int {
+	autoscale := wp.opts.Autoscale
+	perWorker := int64(autoscale.JobsPerWorker)
+	target := int((depth + perWorker - 1) / perWorker)
+
+	if target < current && target < current/2 {
+		target = current / 2
+	}
+
+	return clampWorkers(target, autoscale.MinWorkers, autoscale.MaxWorkers)
+}
+
+// dispatch pulls jobs off the queue in order and hands them to the sequencer, which releases
+// them to the workers once no earlier job for the same account is pending.
+func (wp *WorkerPool) dispatch(ctx context.Context) {
+	defer wp.workers.Done()
+
+	for {
+		if err := wp.sequencer.acquire(ctx); err != nil {
+			return
+		}
+
+		delivery, err := wp.queue.Dequeue(ctx)
+		if err != nil {
+			wp.sequencer.releaseSlot()
+			if ctx.Err() != nil || errors.Is(err, ErrQueueClosed) {
+				return
+			}
+			logger.Log.Errorf("Failed to dequeue job: %v", err)
+			if sleepContext(ctx, queueErrorBackoff) != nil {
+				return
+			}
+			continue
+		}
+
+		if delivery.DecodeErr != nil {
+			wp.sequencer.releaseSlot()
+			wp.deadLetterMalformed(delivery)
+			continue
+		}
+
+		wp.sequencer.add(delivery)
+	}
+}
+
+func (wp *WorkerPool) worker(ctx context.Context, id int) {
+	defer wp.workers.Done()
+
+	logger.Log.Infof("Worker %d started and waiting for jobs", id)
+	for {
+		var delivery *Delivery
+		select {
+		case <-ctx.Done():
+		case delivery = <-wp.sequencer.ready:
+		}
+		if delivery == nil {
+			break
+		}
+		if !wp.sequencer.begin(delivery) {
+			if wp.sequencer.isClosed() {
+				break
+			}
+			continue
+		}
+
+		started := time.Now()
+		jobType := string(delivery.Job.Type)
+		if !delivery.Job.Date.IsZero() && delivery.Attempts == 1 {
+			metrics.ObserveJobQueueWait(jobType, started.Sub(delivery.Job.Date).Seconds())
+		}
+
+		wp.track()
+		wp.process(id, delivery)
+		wp.untrack()
+		wp.sequencer.finish(delivery)
+
+		metrics.ObserveJobProcessingDuration(jobType, time.Since(started).Seconds())
+
+		if wp.opts.Pacing > 0 && sleepContext(ctx, wp.opts.Pacing) != nil {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	logger.Log.Infof("Worker %d stopped.\n", id)
+}
+
+func (wp *WorkerPool) track() {
+	wp.mu.Lock()
+	wp.busy++
+	metrics.SetWorkerPoolUsage(len(wp.stopWorker), wp.busy)
+	wp.mu.Unlock()
+}
+
+func (wp *WorkerPool) untrack() {
+	wp.mu.Lock()
+	wp.busy--
+	metrics.SetWorkerPoolUsage(len(wp.stopWorker), wp.busy)
+	wp.mu.Unlock()
+}
+
+func (wp *WorkerPool) handBack(deliveries []*Delivery) {
+	for _, delivery := range deliveries {
+		logger.Log.Warnf("Handing back unfinished job %d to the queue", delivery.Job.JobId)
+		wp.nack(delivery, 0)
+	}
+}
+
+// recoverPendingJobs re-enqueues unfinished jobs after a restart, since the memory queue
+// loses its contents. Durable queues redeliver them on their own.
+func (wp *WorkerPool) recoverPendingJobs(ctx context.Context) {
+	jobs, err := wp.jobRepo.GetByStatuses([]string{models.JobStatusQueued, models.JobStatusProcessing})
+	if err != nil {
+		logger.Log.Errorf("Failed to load pending jobs for recovery: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if err := wp.queue.Enqueue(ctx, transactionFromJob(&job)); err != nil {
+			logger.Log.Errorf("Failed to recover job %d: %v", job.Id, err)
+		}
+	}
+
+	if len(jobs) > 0 {
+		logger.Log.Infof("Recovered %d pending jobs into the memory queue", len(jobs))
+	}
+}
+
+func transactionFromJob(job *models.Job) Transaction {
+	tx := Transaction{
+		JobId:  job.Id,
+		Amount: job.Amount,
+		UserId: job.UserId,
+		Date:   job.CreatedAt,
+		Type:   TransactionType(job.Type),
+	}
+	if job.ToUserId != nil {
+		tx.ToUserId = *job.ToUserId
+	}
+	if job.FromAccountId != nil {
+		tx.FromAccountId = *job.FromAccountId
+	}
+	if job.ToAccountId != nil {
+		tx.ToAccountId = *job.ToAccountId
+	}
+	return tx
+}
+
+func clampWorkers(workers, min, max int) int {
+	if workers < min {
+		return min
+	}
+	if workers > max {
+		return max
+	}
+	return workers
+}
@@ -14,6 +14,7 @@ type AuditLogRepository interface {
 	GetAll() ([]models.AuditLog, error)
 	Create(log *models.AuditLog) error
 	GetByEntityType(entityType string) ([]models.AuditLog, error)
+	GetByEntity(entityType string, entityId uint) ([]models.AuditLog, error)
 	Delete(id int) error
 }
 
@@ -54,6 +55,15 @@ func (a *auditLogRepository) GetByEntityType(entityType string) ([]models.AuditL
 	return logs, nil
 }
 
+func (a *auditLogRepository) GetByEntity(entityType string, entityId uint) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+
+	if err := a.db.Where("entity_type = ? AND entity_id = ?", entityType, entityId).Order("id").Find(&logs).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to fetch audit logs by entity")
+	}
+	return logs, nil
+}
+
 func (a *auditLogRepository) Delete(id int) error {
 	result := a.db.Delete(&models.AuditLog{}, id)
 	if result.Error != nil {
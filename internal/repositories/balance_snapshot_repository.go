@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+)
+
+const (
+	SnapshotIntervalDay   = "day"
+	SnapshotIntervalWeek  = "week"
+	SnapshotIntervalMonth = "month"
+)
+
+type BalancePoint struct {
+	Bucket time.Time
+	Amount money.Money
+}
+
+type BalanceSnapshotRepository interface {
+	GetAsOf(balanceId uint, at time.Time) (*models.BalanceSnapshot, error)
+	GetSeries(balance *models.Balance, from, to time.Time, interval string) ([]BalancePoint, error)
+}
+
+type balanceSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewBalanceSnapshotRepository(db *gorm.DB) BalanceSnapshotRepository {
+	return &balanceSnapshotRepository{db: db}
+}
+
+// GetAsOf returns the latest snapshot of an account taken at or before at, or NotFound if the
+// account had not changed yet by then.
+func (b *balanceSnapshotRepository) GetAsOf(balanceId uint, at time.Time) (*models.BalanceSnapshot, error) {
+	var snapshot models.BalanceSnapshot
+	if err := b.db.Where("balance_id = ? AND created_at <= ?", balanceId, at).
+		Order("created_at DESC, id DESC").
+		First(&snapshot).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, "no balance recorded at that time")
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get balance snapshot")
+	}
+	return &snapshot, nil
+}
+
+// GetSeries returns the closing balance of an account for every interval bucket between from
+// and to. Buckets before the first snapshot report a zero balance.
+func (b *balanceSnapshotRepository) GetSeries(balance *models.Balance, from, to time.Time, interval string) ([]BalancePoint, error) {
+	var rows []struct {
+		Bucket         time.Time
+		AmountMinor    *int64
+		AmountCurrency *string
+	}
+
+	if err := b.db.Raw(`
+		SELECT buckets.bucket, s.amount_minor, s.amount_currency
+		FROM generate_series(date_trunc(?, ?::timestamptz), ?::timestamptz, CAST(? AS interval)) AS buckets(bucket)
+		LEFT JOIN LATERAL (
+			SELECT amount_minor, amount_currency
+			FROM balance_snapshots
+			WHERE balance_id = ? AND created_at < buckets.bucket + CAST(? AS interval)
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) s ON true
+		ORDER BY buckets.bucket`,
+		interval, from, to, "1 "+interval, balance.Id, "1 "+interval,
+	).Scan(&rows).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get balance history")
+	}
+
+	points := make([]BalancePoint, 0, len(rows))
+	for _, row := range rows {
+		point := BalancePoint{Bucket: row.Bucket}
+		if row.AmountMinor != nil && row.AmountCurrency != nil {
+			point.Amount = money.New(*row.AmountMinor, *row.AmountCurrency)
+		} else {
+			point.Amount = money.Zero(balance.Amount.Currency)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
@@ -3,170 +3,665 @@ package repositories
 import (
 	"errors"
 	"fmt"
-	"sync"
+	"time"
 
 	"github.com/yusuffugurlu/go-project/internal/models"
 	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrStaleBalance is returned when a balance row changed between being read and written.
+var ErrStaleBalance = errors.New("balance was modified concurrently")
+
 type BalancesRepository interface {
 	Create(balance *models.Balance) error
-	GetByUserId(userId uint) (*models.Balance, error)
-	Deposit(userId uint, amount float64) error
-	Withdraw(userId uint, amount float64) error
-	Transfer(fromUserId, toUserId uint, amount float64) error
-	GetHistoricalBalances(userId uint) ([]models.Balance, error)
+	GetByID(id uint) (*models.Balance, error)
+	GetByUserId(userId uint, currency string) (*models.Balance, error)
+	ListByUserId(userId uint) ([]models.Balance, error)
+	Open(userId uint, currency string) (*models.Balance, error)
+	OpenAccount(balance *models.Balance) error
+	SetDefault(id uint) (*models.Balance, error)
+	Close(id uint) (*models.Balance, error)
+	SetOverdraft(id uint, limitMinor, basisPoints int64) (*models.Balance, error)
+	ResolveAccount(userId uint, accountId *uint, currency string, credit bool) (*models.Balance, error)
+	Credit(accountId uint, amount money.Money) error
+	Debit(accountId uint, amount money.Money) error
+	Charge(accountId uint, amount money.Money) error
+	Move(fromAccountId, toAccountId uint, amount money.Money) error
+	Exchange(fromAccountId, toAccountId uint, source, target money.Money) error
+	Hold(accountId uint, amount money.Money) error
+	Release(accountId uint, amount money.Money) error
 }
 
+// balancesRepository keeps balances consistent across any number of app replicas: mutations
+// lock the affected rows with SELECT ... FOR UPDATE (in id order, so concurrent transfers
+// cannot deadlock) and writes are conditional on the row version that was read. Every write
+// also records a balance snapshot in the same transaction.
+//
+// Each balance is one account of a user. A user can have several accounts per currency, one
+// of which is the currency's default account; crediting a user in a supported currency they
+// have no account in yet opens a default account. Money always moves within one currency,
+// except for conversions.
 type balancesRepository struct {
 	db *gorm.DB
-	mu sync.RWMutex
 }
 
 func NewBalancesRepository(db *gorm.DB) BalancesRepository {
-	return &balancesRepository{
-		db: db,
-		mu: sync.RWMutex{},
-	}
+	return &balancesRepository{db: db}
 }
 
 func (b *balancesRepository) Create(balance *models.Balance) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
 	if err := b.db.Create(balance).Error; err != nil {
 		return appErrors.NewDatabaseError(err, "failed to create balance")
 	}
 	return nil
 }
 
-func (b *balancesRepository) GetByUserId(userId uint) (*models.Balance, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+func (b *balancesRepository) GetByID(id uint) (*models.Balance, error) {
+	var balance models.Balance
+	if err := b.db.First(&balance, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("account with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get account")
+	}
+	return &balance, nil
+}
 
+// GetByUserId returns the user's default account in currency.
+func (b *balancesRepository) GetByUserId(userId uint, currency string) (*models.Balance, error) {
 	var balance models.Balance
-	if err := b.db.Where("user_id = ?", userId).First(&balance).Error; err != nil {
+	if err := b.db.Where("user_id = ? AND amount_currency = ? AND is_default", userId, currency).First(&balance).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, appErrors.NewNotFound(err, fmt.Sprintf("balance not found for user %d", userId))
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("no %s balance found for user %d", currency, userId))
 		}
 		return nil, appErrors.NewDatabaseError(err, fmt.Sprintf("failed to get balance for user %d", userId))
 	}
 	return &balance, nil
 }
 
-func (b *balancesRepository) Deposit(userId uint, amount float64) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// ListByUserId returns the user's open accounts, the default account of each currency first.
+func (b *balancesRepository) ListByUserId(userId uint) ([]models.Balance, error) {
+	var balances []models.Balance
+	if err := b.db.Where("user_id = ? AND closed_at IS NULL", userId).
+		Order("amount_currency, is_default DESC, id").
+		Find(&balances).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, fmt.Sprintf("failed to get balances for user %d", userId))
+	}
+	return balances, nil
+}
 
-	if amount <= 0 {
-		return appErrors.NewBadRequest(nil, "amount must be positive")
+// Open creates the user's default account in currency, or returns it if it already exists.
+func (b *balancesRepository) Open(userId uint, currency string) (*models.Balance, error) {
+	if err := requireSupported(currency); err != nil {
+		return nil, err
+	}
+
+	if err := ensureBalance(b.db, userId, currency); err != nil {
+		return nil, err
+	}
+
+	return b.GetByUserId(userId, currency)
+}
+
+// OpenAccount creates a named account. It becomes the default account of its currency if the
+// user has none yet.
+func (b *balancesRepository) OpenAccount(balance *models.Balance) error {
+	if err := requireSupported(balance.Amount.Currency); err != nil {
+		return err
 	}
 
 	return b.db.Transaction(func(tx *gorm.DB) error {
-		var balance models.Balance
+		if err := lockUser(tx, balance.UserId); err != nil {
+			return err
+		}
 
-		if err := tx.Where("user_id = ?", userId).First(&balance).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return appErrors.NewNotFound(err, fmt.Sprintf("balance not found for user %d, cannot deposit", userId))
+		var existing []models.Balance
+		if err := tx.Where("user_id = ? AND amount_currency = ? AND closed_at IS NULL", balance.UserId, balance.Amount.Currency).
+			Find(&existing).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to get accounts")
+		}
+
+		balance.IsDefault = true
+		for _, account := range existing {
+			if account.Name == balance.Name {
+				return appErrors.NewConflict(nil, fmt.Sprintf("an open %s account named %q already exists", balance.Amount.Currency, balance.Name))
+			}
+			if account.IsDefault {
+				balance.IsDefault = false
 			}
-			return appErrors.NewDatabaseError(err, "failed to get balance for deposit")
 		}
 
-		balance.Amount += amount
-		if err := tx.Save(&balance).Error; err != nil {
-			return appErrors.NewDatabaseError(err, "failed to update balance after deposit")
+		balance.Amount = money.Zero(balance.Amount.Currency)
+		balance.LastUpdatedAt = time.Now()
+		if err := tx.Create(balance).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to open account")
 		}
 		return nil
 	})
 }
 
-func (b *balancesRepository) Withdraw(userId uint, amount float64) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// SetDefault makes an open account the default account of its currency.
+func (b *balancesRepository) SetDefault(id uint) (*models.Balance, error) {
+	var balance *models.Balance
 
-	if amount <= 0 {
-		return fmt.Errorf("amount must be positive")
+	err := b.db.Transaction(func(tx *gorm.DB) error {
+		account, err := NewBalancesRepository(tx).GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		if err := lockUser(tx, account.UserId); err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(tx, id)
+		if err != nil {
+			return err
+		}
+		balance = accounts[id]
+
+		if balance.IsDefault {
+			return nil
+		}
+
+		if err := tx.Model(&models.Balance{}).
+			Where("user_id = ? AND amount_currency = ? AND is_default", balance.UserId, balance.Amount.Currency).
+			Update("is_default", false).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to unset default account")
+		}
+
+		if err := tx.Model(balance).Update("is_default", true).Error; err != nil {
+			return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to make account %d the default", id))
+		}
+		balance.IsDefault = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return b.db.Transaction(func(tx *gorm.DB) error {
-		var balance models.Balance
+	return balance, nil
+}
 
-		if err := tx.Where("user_id = ?", userId).First(&balance).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return appErrors.NewNotFound(err, fmt.Sprintf("balance not found for user %d, cannot withdraw", userId))
+// Close closes an empty account. The default account of a currency can only be closed once
+// it is the user's last open account in that currency.
+func (b *balancesRepository) Close(id uint) (*models.Balance, error) {
+	var balance *models.Balance
+
+	err := b.db.Transaction(func(tx *gorm.DB) error {
+		account, err := NewBalancesRepository(tx).GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		if err := lockUser(tx, account.UserId); err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(tx, id)
+		if err != nil {
+			return err
+		}
+		balance = accounts[id]
+
+		if !balance.Amount.IsZero() || balance.HeldMinor != 0 {
+			return appErrors.NewConflict(nil, fmt.Sprintf("account %d still holds %s (%s held), move the funds out before closing it", id, balance.Amount, balance.Held()))
+		}
+
+		if balance.IsDefault {
+			var others int64
+			if err := tx.Model(&models.Balance{}).
+				Where("user_id = ? AND amount_currency = ? AND closed_at IS NULL AND id <> ?", balance.UserId, balance.Amount.Currency, id).
+				Count(&others).Error; err != nil {
+				return appErrors.NewDatabaseError(err, "failed to count accounts")
+			}
+			if others > 0 {
+				return appErrors.NewConflict(nil, fmt.Sprintf("account %d is the default %s account, make another account the default first", id, balance.Amount.Currency))
 			}
-			return appErrors.NewDatabaseError(err, "failed to get balance for withdrawal")
 		}
 
-		if balance.Amount < amount {
-			return appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds for user %d: requested %.2f, available %.2f", userId, amount, balance.Amount))
+		now := time.Now()
+		result := tx.Model(&models.Balance{}).
+			Where("id = ? AND version = ?", balance.Id, balance.Version).
+			Updates(map[string]interface{}{
+				"is_default":      false,
+				"closed_at":       now,
+				"version":         gorm.Expr("version + 1"),
+				"last_updated_at": now,
+			})
+		if result.Error != nil {
+			return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to close account %d", id))
+		}
+		if result.RowsAffected == 0 {
+			return appErrors.NewDatabaseError(ErrStaleBalance, fmt.Sprintf("failed to close account %d", id))
 		}
 
-		balance.Amount -= amount
-		if err := tx.Save(&balance).Error; err != nil {
-			return appErrors.NewDatabaseError(err, "failed to update balance after withdrawal")
+		balance.IsDefault = false
+		balance.ClosedAt = &now
+		balance.Version++
+		balance.LastUpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// SetOverdraft sets the credit line of an open account and the annual interest charged on it.
+// Lowering the limit below what is in use only stops further spending. Interest on an
+// overdrawn balance accrues from today.
+func (b *balancesRepository) SetOverdraft(id uint, limitMinor, basisPoints int64) (*models.Balance, error) {
+	var balance *models.Balance
+
+	err := b.db.Transaction(func(tx *gorm.DB) error {
+		account, err := NewBalancesRepository(tx).GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		if err := lockUser(tx, account.UserId); err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(tx, id)
+		if err != nil {
+			return err
+		}
+		balance = accounts[id]
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"overdraft_limit_minor":  limitMinor,
+			"overdraft_basis_points": basisPoints,
+			"version":                gorm.Expr("version + 1"),
+			"last_updated_at":        now,
+		}
+		if balance.InterestAccruedThrough == nil {
+			accruedThrough := models.UTCDay(now).AddDate(0, 0, -1)
+			updates["interest_accrued_through"] = accruedThrough
+			balance.InterestAccruedThrough = &accruedThrough
 		}
+
+		result := tx.Model(&models.Balance{}).
+			Where("id = ? AND version = ?", balance.Id, balance.Version).
+			Updates(updates)
+		if result.Error != nil {
+			return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to set overdraft of account %d", id))
+		}
+		if result.RowsAffected == 0 {
+			return appErrors.NewDatabaseError(ErrStaleBalance, fmt.Sprintf("failed to set overdraft of account %d", id))
+		}
+
+		balance.OverdraftLimitMinor = limitMinor
+		balance.OverdraftBasisPoints = basisPoints
+		balance.Version++
+		balance.LastUpdatedAt = now
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return balance, nil
 }
 
-func (b *balancesRepository) Transfer(fromUserId, toUserId uint, amount float64) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+// ResolveAccount returns the account of userId that money in currency moves out of (or, if
+// credit is set, into): the given account, which must belong to the user, hold currency and
+// be open, or else the user's default account in currency. When crediting, a missing default
+// account is opened.
+func (b *balancesRepository) ResolveAccount(userId uint, accountId *uint, currency string, credit bool) (*models.Balance, error) {
+	if accountId != nil {
+		account, err := b.GetByID(*accountId)
+		if err != nil {
+			return nil, err
+		}
+		if account.UserId != userId {
+			return nil, appErrors.NewNotFound(nil, fmt.Sprintf("account with id %d not found", *accountId))
+		}
+		if account.Amount.Currency != currency {
+			return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("account %d holds %s, not %s", account.Id, account.Amount.Currency, currency))
+		}
+		if account.IsClosed() {
+			return nil, appErrors.NewConflict(nil, fmt.Sprintf("account %d is closed", account.Id))
+		}
+		return account, nil
+	}
+
+	if credit {
+		if err := requireSupported(currency); err != nil {
+			return nil, err
+		}
+		if err := ensureBalance(b.db, userId, currency); err != nil {
+			return nil, err
+		}
+	}
 
-	if amount <= 0 {
+	return b.GetByUserId(userId, currency)
+}
+
+func (b *balancesRepository) Credit(accountId uint, amount money.Money) error {
+	if !amount.IsPositive() {
 		return appErrors.NewBadRequest(nil, "amount must be positive")
 	}
 
-	if fromUserId == toUserId {
-		return appErrors.NewBadRequest(nil, "cannot transfer to same user")
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, accountId)
+		if err != nil {
+			return err
+		}
+		balance := accounts[accountId]
+
+		newAmount, err := balance.Amount.Add(amount)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "deposit currency does not match balance currency")
+		}
+
+		return saveBalance(tx, balance, newAmount, "failed to update balance after deposit")
+	})
+}
+
+func (b *balancesRepository) Debit(accountId uint, amount money.Money) error {
+	if !amount.IsPositive() {
+		return appErrors.NewBadRequest(nil, "amount must be positive")
 	}
 
 	return b.db.Transaction(func(tx *gorm.DB) error {
-		var fromBalance, toBalance models.Balance
+		accounts, err := lockAccounts(tx, accountId)
+		if err != nil {
+			return err
+		}
+		balance := accounts[accountId]
 
-		if err := tx.Where("user_id = ?", fromUserId).First(&fromBalance).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return appErrors.NewNotFound(err, fmt.Sprintf("balance not found for user %d", fromUserId))
-			}
-			return appErrors.NewDatabaseError(err, "failed to get sender balance")
+		newAmount, err := balance.Amount.Sub(amount)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "withdrawal currency does not match balance currency")
 		}
 
-		if err := tx.Where("user_id = ?", toUserId).First(&toBalance).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return appErrors.NewNotFound(err, fmt.Sprintf("balance not found for user %d", toUserId))
-			}
-			return appErrors.NewDatabaseError(err, "failed to get receiver balance")
+		if available := balance.Available(); available.Minor < amount.Minor {
+			return appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds in account %d: requested %s, available %s", accountId, amount, available))
 		}
 
-		if fromBalance.Amount < amount {
-			return appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds for user %d: requested %.2f, available %.2f", fromUserId, amount, fromBalance.Amount))
+		return saveBalance(tx, balance, newAmount, "failed to update balance after withdrawal")
+	})
+}
+
+// Charge debits amount without checking the available balance, so it can take the account
+// past its overdraft limit.
+func (b *balancesRepository) Charge(accountId uint, amount money.Money) error {
+	if !amount.IsPositive() {
+		return appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, accountId)
+		if err != nil {
+			return err
 		}
+		balance := accounts[accountId]
 
-		fromBalance.Amount -= amount
-		toBalance.Amount += amount
+		newAmount, err := balance.Amount.Sub(amount)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "charge currency does not match balance currency")
+		}
+
+		return saveBalance(tx, balance, newAmount, "failed to update balance after charge")
+	})
+}
+
+// Move transfers amount between two accounts, of the same or of different users.
+func (b *balancesRepository) Move(fromAccountId, toAccountId uint, amount money.Money) error {
+	if !amount.IsPositive() {
+		return appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	if fromAccountId == toAccountId {
+		return appErrors.NewBadRequest(nil, "cannot transfer to same account")
+	}
 
-		if err := tx.Save(&fromBalance).Error; err != nil {
-			return appErrors.NewDatabaseError(err, "failed to update sender balance")
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, fromAccountId, toAccountId)
+		if err != nil {
+			return err
 		}
+		fromBalance, toBalance := accounts[fromAccountId], accounts[toAccountId]
 
-		if err := tx.Save(&toBalance).Error; err != nil {
-			return appErrors.NewDatabaseError(err, "failed to update receiver balance")
+		newFromAmount, err := fromBalance.Amount.Sub(amount)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "transfer currency does not match sender balance currency")
 		}
 
-		return nil
+		newToAmount, err := toBalance.Amount.Add(amount)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "transfer currency does not match receiver balance currency")
+		}
+
+		if available := fromBalance.Available(); available.Minor < amount.Minor {
+			return appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds in account %d: requested %s, available %s", fromAccountId, amount, available))
+		}
+
+		if err := saveBalance(tx, fromBalance, newFromAmount, "failed to update sender balance"); err != nil {
+			return err
+		}
+
+		return saveBalance(tx, toBalance, newToAmount, "failed to update receiver balance")
+	})
+}
+
+// Exchange debits source from one account and credits target, in another currency, to another.
+func (b *balancesRepository) Exchange(fromAccountId, toAccountId uint, source, target money.Money) error {
+	if !source.IsPositive() || !target.IsPositive() {
+		return appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	if source.SameCurrency(target) {
+		return appErrors.NewBadRequest(nil, "cannot convert between the same currency")
+	}
+
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, fromAccountId, toAccountId)
+		if err != nil {
+			return err
+		}
+		fromBalance, toBalance := accounts[fromAccountId], accounts[toAccountId]
+
+		newFromAmount, err := fromBalance.Amount.Sub(source)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "conversion currency does not match sender balance currency")
+		}
+
+		newToAmount, err := toBalance.Amount.Add(target)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "conversion currency does not match receiver balance currency")
+		}
+
+		if available := fromBalance.Available(); available.Minor < source.Minor {
+			return appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds in account %d: requested %s, available %s", fromAccountId, source, available))
+		}
+
+		if err := saveBalance(tx, fromBalance, newFromAmount, "failed to update sender balance"); err != nil {
+			return err
+		}
+
+		return saveBalance(tx, toBalance, newToAmount, "failed to update receiver balance")
+	})
+}
+
+// Hold reserves amount of the account's available balance. The ledger balance is unchanged.
+func (b *balancesRepository) Hold(accountId uint, amount money.Money) error {
+	if !amount.IsPositive() {
+		return appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, accountId)
+		if err != nil {
+			return err
+		}
+		balance := accounts[accountId]
+
+		if !balance.Amount.SameCurrency(amount) {
+			return appErrors.NewBadRequest(nil, "hold currency does not match balance currency")
+		}
+
+		if available := balance.Available(); available.Minor < amount.Minor {
+			return appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds in account %d: requested %s, available %s", accountId, amount, available))
+		}
+
+		return saveHeld(tx, balance, balance.HeldMinor+amount.Minor, "failed to hold funds")
+	})
+}
+
+// Release returns held funds to the account's available balance.
+func (b *balancesRepository) Release(accountId uint, amount money.Money) error {
+	if !amount.IsPositive() {
+		return appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, accountId)
+		if err != nil {
+			return err
+		}
+		balance := accounts[accountId]
+
+		if !balance.Amount.SameCurrency(amount) {
+			return appErrors.NewBadRequest(nil, "release currency does not match balance currency")
+		}
+
+		if balance.HeldMinor < amount.Minor {
+			return appErrors.NewInternalServerError(fmt.Errorf("cannot release %s in account %d, only %s is held", amount, accountId, balance.Held()))
+		}
+
+		return saveHeld(tx, balance, balance.HeldMinor-amount.Minor, "failed to release held funds")
 	})
 }
 
-func (b *balancesRepository) GetHistoricalBalances(userId uint) ([]models.Balance, error) {
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+// lockAccounts reads and row-locks the given open accounts for the rest of tx. Rows are
+// always locked in ascending id order.
+func lockAccounts(tx *gorm.DB, ids ...uint) (map[uint]*models.Balance, error) {
+	var rows []models.Balance
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&rows).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to lock balances")
+	}
+
+	balances := make(map[uint]*models.Balance, len(rows))
+	for i := range rows {
+		balances[rows[i].Id] = &rows[i]
+	}
+
+	for _, id := range ids {
+		balance, ok := balances[id]
+		if !ok {
+			return nil, appErrors.NewNotFound(nil, fmt.Sprintf("account with id %d not found", id))
+		}
+		if balance.IsClosed() {
+			return nil, appErrors.NewConflict(nil, fmt.Sprintf("account %d is closed", id))
+		}
+	}
+
+	return balances, nil
+}
+
+// lockUser row-locks a user for the rest of tx, serializing changes to the set of their
+// accounts and their limited outgoing transactions. NO KEY UPDATE does not block the foreign
+// key checks of concurrent inserts that reference the user, such as new transactions.
+func lockUser(tx *gorm.DB, userId uint) error {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).Select("id").First(&user, userId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return appErrors.NewNotFound(err, fmt.Sprintf("user with id %d not found", userId))
+		}
+		return appErrors.NewDatabaseError(err, "failed to lock user")
+	}
+	return nil
+}
+
+// ensureBalance opens a zero default account in currency for an existing user who has none yet.
+func ensureBalance(tx *gorm.DB, userId uint, currency string) error {
+	if err := tx.Exec(`
+		INSERT INTO balances (user_id, name, kind, is_default, amount_minor, amount_currency, last_updated_at)
+		SELECT id, ?, ?, true, 0, ?, ? FROM users WHERE id = ?
+		ON CONFLICT DO NOTHING`,
+		models.DefaultAccountName, models.AccountKindChecking, currency, time.Now(), userId,
+	).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to open %s balance for user %d", currency, userId))
+	}
+	return nil
+}
+
+func requireSupported(currency string) error {
+	if !money.Supported(currency) {
+		return appErrors.NewBadRequest(nil, fmt.Sprintf("currency %s is not supported", currency))
+	}
+	return nil
+}
+
+// saveBalance writes the new amount only if the row still has the version that was read.
+func saveBalance(tx *gorm.DB, balance *models.Balance, amount money.Money, message string) error {
+	now := time.Now()
+
+	result := tx.Model(&models.Balance{}).
+		Where("id = ? AND version = ?", balance.Id, balance.Version).
+		Updates(map[string]interface{}{
+			"amount_minor":    amount.Minor,
+			"amount_currency": amount.Currency,
+			"version":         gorm.Expr("version + 1"),
+			"last_updated_at": now,
+		})
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, message)
+	}
+	if result.RowsAffected == 0 {
+		return appErrors.NewDatabaseError(ErrStaleBalance, message)
+	}
+
+	balance.Amount = amount
+	balance.Version++
+	balance.LastUpdatedAt = now
+
+	if err := tx.Create(&models.BalanceSnapshot{
+		UserId:    balance.UserId,
+		BalanceId: &balance.Id,
+		Amount:    amount,
+		Version:   balance.Version,
+		CreatedAt: now,
+	}).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to record balance snapshot")
+	}
 
-	var historicalBalances []models.Balance
-	if err := b.db.Where("user_id = ?", userId).Order("date DESC").Find(&historicalBalances).Error; err != nil {
-		return nil, appErrors.NewDatabaseError(err, "failed to fetch historical balances")
+	return nil
+}
+
+// saveHeld writes the new held amount only if the row still has the version that was read.
+// Holds do not change the ledger balance, so no snapshot is recorded.
+func saveHeld(tx *gorm.DB, balance *models.Balance, heldMinor int64, message string) error {
+	now := time.Now()
+
+	result := tx.Model(&models.Balance{}).
+		Where("id = ? AND version = ?", balance.Id, balance.Version).
+		Updates(map[string]interface{}{
+			"held_minor":      heldMinor,
+			"version":         gorm.Expr("version + 1"),
+			"last_updated_at": now,
+		})
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, message)
 	}
+	if result.RowsAffected == 0 {
+		return appErrors.NewDatabaseError(ErrStaleBalance, message)
+	}
+
+	balance.HeldMinor = heldMinor
+	balance.Version++
+	balance.LastUpdatedAt = now
 
-	return historicalBalances, nil
+	return nil
 }
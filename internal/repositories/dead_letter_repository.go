@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeadLetterRepository interface {
+	Create(deadLetter *models.DeadLetter) error
+	GetAll(limit, offset int) ([]models.DeadLetter, error)
+	GetByID(id uint) (*models.DeadLetter, error)
+	Delete(id uint) error
+}
+
+type deadLetterRepository struct {
+	db *gorm.DB
+}
+
+func NewDeadLetterRepository(db *gorm.DB) DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+func (d *deadLetterRepository) Create(deadLetter *models.DeadLetter) error {
+	if err := d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"payload", "error", "attempts", "created_at"}),
+	}).Create(deadLetter).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to store dead letter")
+	}
+	return nil
+}
+
+func (d *deadLetterRepository) GetAll(limit, offset int) ([]models.DeadLetter, error) {
+	var deadLetters []models.DeadLetter
+	if err := d.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deadLetters).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to fetch dead letters")
+	}
+	return deadLetters, nil
+}
+
+func (d *deadLetterRepository) GetByID(id uint) (*models.DeadLetter, error) {
+	var deadLetter models.DeadLetter
+	if err := d.db.Preload("Job").First(&deadLetter, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("dead letter with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to fetch dead letter")
+	}
+	return &deadLetter, nil
+}
+
+func (d *deadLetterRepository) Delete(id uint) error {
+	result := d.db.Delete(&models.DeadLetter{}, id)
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to delete dead letter with id %d", id))
+	}
+
+	if result.RowsAffected == 0 {
+		return appErrors.NewNotFound(nil, fmt.Sprintf("dead letter with id %d not found", id))
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type ExchangeRateRepository interface {
+	Create(rate *models.ExchangeRate) error
+	GetLatest(base, quote string, asOf time.Time) (*models.ExchangeRate, error)
+	List(limit, offset int) ([]models.ExchangeRate, error)
+}
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) Create(rate *models.ExchangeRate) error {
+	if err := r.db.Create(rate).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create exchange rate")
+	}
+	return nil
+}
+
+// GetLatest returns the most recent rate for the pair that was in effect at asOf.
+func (r *exchangeRateRepository) GetLatest(base, quote string, asOf time.Time) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	if err := r.db.Where("base = ? AND quote = ? AND as_of <= ?", base, quote, asOf).
+		Order("as_of DESC, id DESC").
+		First(&rate).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("no exchange rate found for %s/%s", base, quote))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get exchange rate")
+	}
+	return &rate, nil
+}
+
+func (r *exchangeRateRepository) List(limit, offset int) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	if err := r.db.Order("as_of DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&rates).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get exchange rates")
+	}
+	return rates, nil
+}
@@ -0,0 +1,327 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExpenseGroupRepository interface {
+	Create(group *models.ExpenseGroup) error
+	GetByID(id uint) (*models.ExpenseGroup, error)
+	GetByUserID(userId uint, limit, offset int) ([]models.ExpenseGroup, error)
+	IsMember(groupId, userId uint) (bool, error)
+	AddMember(groupId, userId uint) error
+	RemoveMember(groupId, userId uint) error
+	CreateExpense(expense *models.Expense) error
+	GetExpense(groupId, expenseId uint) (*models.Expense, error)
+	GetExpenses(groupId uint, limit, offset int) ([]models.Expense, error)
+	DeleteExpense(groupId, expenseId uint) error
+	GetSettlements(groupId uint, limit, offset int) ([]models.GroupSettlement, error)
+	NetBalances(groupId uint) (map[uint]int64, error)
+	SettlementPlan(groupId, userId uint) *SettlementPlan
+}
+
+type expenseGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewExpenseGroupRepository(db *gorm.DB) ExpenseGroupRepository {
+	return &expenseGroupRepository{db: db}
+}
+
+// Create creates the group with its creator as the first member.
+func (e *expenseGroupRepository) Create(group *models.ExpenseGroup) error {
+	group.Members = []models.ExpenseGroupMember{{UserId: group.CreatedBy}}
+	if err := e.db.Create(group).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create expense group")
+	}
+	return nil
+}
+
+func (e *expenseGroupRepository) GetByID(id uint) (*models.ExpenseGroup, error) {
+	var group models.ExpenseGroup
+	if err := e.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("expense group with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get expense group")
+	}
+	return &group, nil
+}
+
+func (e *expenseGroupRepository) GetByUserID(userId uint, limit, offset int) ([]models.ExpenseGroup, error) {
+	var groups []models.ExpenseGroup
+	if err := e.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).
+		Where("id IN (?)", e.db.Model(&models.ExpenseGroupMember{}).Select("group_id").Where("user_id = ?", userId)).
+		Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&groups).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get expense groups")
+	}
+	return groups, nil
+}
+
+func (e *expenseGroupRepository) IsMember(groupId, userId uint) (bool, error) {
+	var count int64
+	if err := e.db.Model(&models.ExpenseGroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupId, userId).
+		Count(&count).Error; err != nil {
+		return false, appErrors.NewDatabaseError(err, "failed to check expense group membership")
+	}
+	return count > 0, nil
+}
+
+func (e *expenseGroupRepository) AddMember(groupId, userId uint) error {
+	isMember, err := e.IsMember(groupId, userId)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return appErrors.NewConflict(nil, fmt.Sprintf("user %d is already a member of expense group %d", userId, groupId))
+	}
+
+	if err := e.db.Create(&models.ExpenseGroupMember{GroupId: groupId, UserId: userId}).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to add expense group member")
+	}
+	return nil
+}
+
+// RemoveMember removes a member who is settled up. The group is locked so no expense or
+// settlement changes the member's balance while it is checked.
+func (e *expenseGroupRepository) RemoveMember(groupId, userId uint) error {
+	return e.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockExpenseGroup(tx, groupId); err != nil {
+			return err
+		}
+
+		net, err := netBalances(tx, groupId)
+		if err != nil {
+			return err
+		}
+		if net[userId] != 0 {
+			return appErrors.NewConflict(nil, fmt.Sprintf("user %d must settle up before leaving expense group %d", userId, groupId))
+		}
+
+		result := tx.Where("group_id = ? AND user_id = ?", groupId, userId).Delete(&models.ExpenseGroupMember{})
+		if result.Error != nil {
+			return appErrors.NewDatabaseError(result.Error, "failed to remove expense group member")
+		}
+		if result.RowsAffected == 0 {
+			return appErrors.NewNotFound(nil, fmt.Sprintf("user %d is not a member of expense group %d", userId, groupId))
+		}
+		return nil
+	})
+}
+
+// CreateExpense records an expense with its shares. The group is locked so the expense does
+// not race a settle-up that is being planned.
+func (e *expenseGroupRepository) CreateExpense(expense *models.Expense) error {
+	return e.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockExpenseGroup(tx, expense.GroupId); err != nil {
+			return err
+		}
+
+		if err := tx.Create(expense).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to create expense")
+		}
+		return nil
+	})
+}
+
+func (e *expenseGroupRepository) GetExpense(groupId, expenseId uint) (*models.Expense, error) {
+	var expense models.Expense
+	if err := e.db.Preload("Shares").
+		Where("group_id = ?", groupId).
+		First(&expense, expenseId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("expense with id %d not found", expenseId))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get expense")
+	}
+	return &expense, nil
+}
+
+func (e *expenseGroupRepository) GetExpenses(groupId uint, limit, offset int) ([]models.Expense, error) {
+	var expenses []models.Expense
+	if err := e.db.Preload("Shares").
+		Where("group_id = ?", groupId).
+		Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&expenses).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get expenses")
+	}
+	return expenses, nil
+}
+
+func (e *expenseGroupRepository) DeleteExpense(groupId, expenseId uint) error {
+	return e.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockExpenseGroup(tx, groupId); err != nil {
+			return err
+		}
+
+		if err := tx.Where("expense_id = ?", expenseId).Delete(&models.ExpenseShare{}).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to delete expense shares")
+		}
+
+		result := tx.Where("group_id = ?", groupId).Delete(&models.Expense{}, expenseId)
+		if result.Error != nil {
+			return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to delete expense %d", expenseId))
+		}
+		if result.RowsAffected == 0 {
+			return appErrors.NewNotFound(nil, fmt.Sprintf("expense with id %d not found", expenseId))
+		}
+		return nil
+	})
+}
+
+func (e *expenseGroupRepository) GetSettlements(groupId uint, limit, offset int) ([]models.GroupSettlement, error) {
+	var settlements []models.GroupSettlement
+	if err := e.db.Where("group_id = ?", groupId).
+		Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&settlements).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get group settlements")
+	}
+	return settlements, nil
+}
+
+// NetBalances returns what each member is owed in the group, in minor units: positive if the
+// group owes them money, negative if they owe the group. The balances add up to zero.
+func (e *expenseGroupRepository) NetBalances(groupId uint) (map[uint]int64, error) {
+	return netBalances(e.db, groupId)
+}
+
+// SettlementPlan returns the plan that pays what userId owes in the group's simplified debts,
+// one transfer per creditor, for LedgerRepository.TransferAll. The group stays locked while the
+// transfers are made, and each of them is recorded as a settlement with an audit log in the
+// same transaction.
+func (e *expenseGroupRepository) SettlementPlan(groupId, userId uint) *SettlementPlan {
+	return &SettlementPlan{groupId: groupId, userId: userId}
+}
+
+// SettlementPlan is a settle-up of one member of an expense group. Settlements holds the
+// settlements recorded once the transfers went through.
+type SettlementPlan struct {
+	groupId     uint
+	userId      uint
+	Settlements []models.GroupSettlement
+}
+
+func (p *SettlementPlan) Transfers(tx *gorm.DB) ([]Transfer, error) {
+	if err := lockExpenseGroup(tx, p.groupId); err != nil {
+		return nil, err
+	}
+
+	var group models.ExpenseGroup
+	if err := tx.First(&group, p.groupId).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get expense group")
+	}
+
+	net, err := netBalances(tx, p.groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []Transfer
+	for _, debt := range models.SimplifyDebts(net) {
+		if debt.FromUserId != p.userId {
+			continue
+		}
+		transfers = append(transfers, Transfer{
+			FromUserId: debt.FromUserId,
+			ToUserId:   debt.ToUserId,
+			Amount:     money.New(debt.AmountMinor, group.Currency),
+		})
+	}
+	return transfers, nil
+}
+
+func (p *SettlementPlan) Record(tx *gorm.DB, transfer Transfer, transaction *models.Transaction) error {
+	settlement := models.GroupSettlement{
+		GroupId:       p.groupId,
+		FromUserId:    transfer.FromUserId,
+		ToUserId:      transfer.ToUserId,
+		Amount:        transfer.Amount,
+		Status:        models.SettlementStatusCompleted,
+		TransactionId: &transaction.Id,
+	}
+	if err := tx.Create(&settlement).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create settlement")
+	}
+
+	details := fmt.Sprintf("user %d paid user %d %s to settle up, transaction %d", settlement.FromUserId, settlement.ToUserId, settlement.Amount, transaction.Id)
+	if err := tx.Create(models.NewAuditLog(models.ExpenseGroupAuditEntity, p.groupId, "settle", details)).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create audit log")
+	}
+
+	p.Settlements = append(p.Settlements, settlement)
+	return nil
+}
+
+// lockExpenseGroup row-locks a group for the rest of tx. Everything that changes the group's
+// balances takes this lock first.
+func lockExpenseGroup(tx *gorm.DB, id uint) error {
+	var group models.ExpenseGroup
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return appErrors.NewNotFound(err, fmt.Sprintf("expense group with id %d not found", id))
+		}
+		return appErrors.NewDatabaseError(err, "failed to lock expense group")
+	}
+	return nil
+}
+
+// netBalances adds up, per user, what they paid for the group and what settlements they made,
+// less their shares of expenses and the settlements they received.
+func netBalances(db *gorm.DB, groupId uint) (map[uint]int64, error) {
+	type total struct {
+		UserId uint
+		Minor  int64
+	}
+
+	queries := []struct {
+		sign  int64
+		query *gorm.DB
+	}{
+		{1, db.Model(&models.Expense{}).
+			Select("paid_by AS user_id, SUM(amount_minor) AS minor").
+			Where("group_id = ?", groupId).
+			Group("paid_by")},
+		{-1, db.Model(&models.ExpenseShare{}).
+			Select("expense_shares.user_id, SUM(expense_shares.amount_minor) AS minor").
+			Joins("JOIN expenses ON expenses.id = expense_shares.expense_id").
+			Where("expenses.group_id = ?", groupId).
+			Group("expense_shares.user_id")},
+		{1, db.Model(&models.GroupSettlement{}).
+			Select("from_user_id AS user_id, SUM(amount_minor) AS minor").
+			Where("group_id = ? AND status = ?", groupId, models.SettlementStatusCompleted).
+			Group("from_user_id")},
+		{-1, db.Model(&models.GroupSettlement{}).
+			Select("to_user_id AS user_id, SUM(amount_minor) AS minor").
+			Where("group_id = ? AND status = ?", groupId, models.SettlementStatusCompleted).
+			Group("to_user_id")},
+	}
+
+	net := make(map[uint]int64)
+	for _, q := range queries {
+		var totals []total
+		if err := q.query.Scan(&totals).Error; err != nil {
+			return nil, appErrors.NewDatabaseError(err, "failed to compute expense group balances")
+		}
+		for _, t := range totals {
+			net[t.UserId] += q.sign * t.Minor
+		}
+	}
+
+	return net, nil
+}
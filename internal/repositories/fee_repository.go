@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+)
+
+// FeeTotal sums the fees charged on one transaction type in one currency, net of refunds.
+type FeeTotal struct {
+	TransactionType string `json:"transaction_type"`
+	Currency        string `json:"currency"`
+	Count           int64  `json:"count"`
+	TotalMinor      int64  `json:"total_minor_units"`
+}
+
+type FeeRepository interface {
+	CreateRule(rule *models.FeeRule) error
+	GetRuleByID(id uint) (*models.FeeRule, error)
+	ListRules(activeOnly bool, limit, offset int) ([]models.FeeRule, error)
+	DeactivateRule(id uint) (*models.FeeRule, error)
+	FindRule(transactionType, role, currency string) (*models.FeeRule, error)
+	Quote(transactionType string, userId uint, amount money.Money) (money.Money, *models.FeeRule, error)
+	GetTotals(from, to time.Time) ([]FeeTotal, error)
+}
+
+type feeRepository struct {
+	db *gorm.DB
+}
+
+func NewFeeRepository(db *gorm.DB) FeeRepository {
+	return &feeRepository{db: db}
+}
+
+func (f *feeRepository) CreateRule(rule *models.FeeRule) error {
+	if err := f.db.Create(rule).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create fee rule")
+	}
+	return nil
+}
+
+func (f *feeRepository) GetRuleByID(id uint) (*models.FeeRule, error) {
+	var rule models.FeeRule
+	if err := f.db.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("fee rule with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get fee rule")
+	}
+	return &rule, nil
+}
+
+func (f *feeRepository) ListRules(activeOnly bool, limit, offset int) ([]models.FeeRule, error) {
+	query := f.db.Order("transaction_type, currency, id DESC")
+	if activeOnly {
+		query = query.Where("active")
+	}
+
+	var rules []models.FeeRule
+	if err := query.Limit(limit).Offset(offset).Find(&rules).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get fee rules")
+	}
+	return rules, nil
+}
+
+// DeactivateRule stops a rule from applying to new transactions. Rules are kept so past fees
+// can still be traced to them.
+func (f *feeRepository) DeactivateRule(id uint) (*models.FeeRule, error) {
+	rule, err := f.GetRuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rule.Active {
+		return rule, nil
+	}
+
+	if err := f.db.Model(rule).Update("active", false).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, fmt.Sprintf("failed to deactivate fee rule %d", id))
+	}
+	rule.Active = false
+	return rule, nil
+}
+
+// FindRule returns the rule that prices a transaction of a user with role: the newest active
+// rule for that role if there is one, and the newest active rule for everyone otherwise. It
+// returns nil if the transaction is free.
+func (f *feeRepository) FindRule(transactionType, role, currency string) (*models.FeeRule, error) {
+	var rules []models.FeeRule
+	if err := f.db.Where("active AND transaction_type = ? AND currency = ? AND role IN ?", transactionType, currency, []string{"", role}).
+		Order("role = '', id DESC").
+		Limit(1).
+		Find(&rules).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get fee rule")
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &rules[0], nil
+}
+
+// Quote returns the fee a user pays for a transaction of amount and the rule that sets it.
+// Free transactions cost zero and have no rule.
+func (f *feeRepository) Quote(transactionType string, userId uint, amount money.Money) (money.Money, *models.FeeRule, error) {
+	role, err := getUserRole(f.db, userId)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+
+	rule, err := f.FindRule(transactionType, role, amount.Currency)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+	if rule == nil {
+		return money.Zero(amount.Currency), nil, nil
+	}
+
+	fee, err := rule.Fee(amount)
+	if err != nil {
+		return money.Money{}, nil, appErrors.NewUnprocessableEntity(err, fmt.Sprintf("the fee on %s is too large", amount))
+	}
+
+	return fee, rule, nil
+}
+
+// GetTotals sums the fees charged between from and to by the type of the transaction they
+// were charged for.
+func (f *feeRepository) GetTotals(from, to time.Time) ([]FeeTotal, error) {
+	totals := []FeeTotal{}
+	if err := f.db.Table("transactions AS fee").
+		Select("t.type AS transaction_type, fee.amount_currency AS currency, COUNT(*) AS count, SUM(fee.amount_minor - fee.refunded_minor) AS total_minor").
+		Joins("JOIN transactions t ON t.id = fee.fee_of_id").
+		Where("fee.type = ? AND fee.created_at >= ? AND fee.created_at <= ?", models.TransactionTypeFee, from, to).
+		Group("t.type, fee.amount_currency").
+		Order("t.type, fee.amount_currency").
+		Scan(&totals).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to sum fees")
+	}
+	return totals, nil
+}
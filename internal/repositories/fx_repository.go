@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FxRepository interface {
+	CreateQuote(quote *models.FxQuote) error
+	GetQuoteByID(id uint) (*models.FxQuote, error)
+	Execute(quoteId uint, toUserId uint, now time.Time) (*models.Conversion, error)
+	GetConversionByID(id uint) (*models.Conversion, error)
+	GetConversionsByUserID(userId uint, limit, offset int) ([]models.Conversion, error)
+}
+
+type fxRepository struct {
+	db *gorm.DB
+}
+
+func NewFxRepository(db *gorm.DB) FxRepository {
+	return &fxRepository{db: db}
+}
+
+func (f *fxRepository) CreateQuote(quote *models.FxQuote) error {
+	if err := f.db.Create(quote).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create quote")
+	}
+	return nil
+}
+
+func (f *fxRepository) GetQuoteByID(id uint) (*models.FxQuote, error) {
+	var quote models.FxQuote
+	if err := f.db.First(&quote, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("quote with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get quote")
+	}
+	return &quote, nil
+}
+
+// Execute converts at a quote's rate, crediting toUserId, and marks the quote executed. The
+// quote is row-locked, so it cannot be executed twice concurrently.
+func (f *fxRepository) Execute(quoteId uint, toUserId uint, now time.Time) (*models.Conversion, error) {
+	var conversion *models.Conversion
+
+	err := f.db.Transaction(func(tx *gorm.DB) error {
+		var quote models.FxQuote
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&quote, quoteId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return appErrors.NewNotFound(err, fmt.Sprintf("quote with id %d not found", quoteId))
+			}
+			return appErrors.NewDatabaseError(err, "failed to lock quote")
+		}
+
+		if quote.Status != models.FxQuoteStatusOpen {
+			return appErrors.NewConflict(nil, fmt.Sprintf("quote %d is already %s", quote.Id, quote.Status))
+		}
+		if quote.IsExpired(now) {
+			return appErrors.NewConflict(nil, fmt.Sprintf("quote %d has expired", quote.Id))
+		}
+
+		transaction, err := NewLedgerRepository(tx).Convert(quote.UserId, toUserId, quote.Source, quote.Target)
+		if err != nil {
+			return err
+		}
+
+		conversion = &models.Conversion{
+			TransactionId: transaction.Id,
+			QuoteId:       quote.Id,
+			UserId:        quote.UserId,
+			ToUserId:      toUserId,
+			Source:        quote.Source,
+			Target:        quote.Target,
+			Rate:          quote.Rate,
+			Provider:      quote.Provider,
+		}
+		if err := tx.Create(conversion).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to record conversion")
+		}
+
+		if err := tx.Model(&quote).Updates(map[string]interface{}{
+			"status":      models.FxQuoteStatusExecuted,
+			"executed_at": now,
+		}).Error; err != nil {
+			return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to mark quote %d as executed", quote.Id))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conversion, nil
+}
+
+func (f *fxRepository) GetConversionByID(id uint) (*models.Conversion, error) {
+	var conversion models.Conversion
+	if err := f.db.First(&conversion, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("conversion with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get conversion")
+	}
+	return &conversion, nil
+}
+
+// GetConversionsByUserID lists conversions the user made or received.
+func (f *fxRepository) GetConversionsByUserID(userId uint, limit, offset int) ([]models.Conversion, error) {
+	var conversions []models.Conversion
+	if err := f.db.Where("user_id = ? OR to_user_id = ?", userId, userId).
+		Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&conversions).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get conversions")
+	}
+	return conversions, nil
+}
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HoldRepository interface {
+	Create(hold *models.Hold) error
+	GetByID(id uint) (*models.Hold, error)
+	GetByUserID(userId uint, status string, limit, offset int) ([]models.Hold, error)
+	Capture(id uint, amount money.Money) (*models.Hold, error)
+	Release(id uint) (*models.Hold, error)
+	ExpireDue(now time.Time, limit int) (int, error)
+}
+
+type holdRepository struct {
+	db *gorm.DB
+}
+
+func NewHoldRepository(db *gorm.DB) HoldRepository {
+	return &holdRepository{db: db}
+}
+
+// Create reserves the hold's amount and fee on one of the user's accounts and records the
+// hold, atomically. The hold is placed on hold.BalanceId if set, and on the user's default
+// account in the hold's currency otherwise. The user's limits are enforced here rather than on
+// capture, and active holds count against them until they are closed.
+func (h *holdRepository) Create(hold *models.Hold) error {
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		if err := enforceLimits(tx, hold.UserId, hold.Amount); err != nil {
+			return err
+		}
+
+		balances := NewBalancesRepository(tx)
+
+		var accountId *uint
+		if hold.BalanceId != 0 {
+			accountId = &hold.BalanceId
+		}
+		account, err := balances.ResolveAccount(hold.UserId, accountId, hold.Amount.Currency, false)
+		if err != nil {
+			return err
+		}
+		hold.BalanceId = account.Id
+
+		fee, err := feeFor(tx, hold.TransactionType(), account, hold.Amount)
+		if err != nil {
+			return err
+		}
+		hold.FeeMinor = fee.Minor
+
+		if err := balances.Hold(hold.BalanceId, hold.Reserved()); err != nil {
+			return err
+		}
+
+		if err := tx.Create(hold).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to create hold")
+		}
+		return nil
+	})
+}
+
+func (h *holdRepository) GetByID(id uint) (*models.Hold, error) {
+	var hold models.Hold
+	if err := h.db.First(&hold, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("hold with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get hold")
+	}
+	return &hold, nil
+}
+
+func (h *holdRepository) GetByUserID(userId uint, status string, limit, offset int) ([]models.Hold, error) {
+	query := h.db.Where("user_id = ?", userId)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var holds []models.Hold
+	if err := query.Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&holds).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get holds")
+	}
+	return holds, nil
+}
+
+// Capture settles amount of an active hold, to the hold's payee if it has one and out of the
+// system otherwise, and releases the rest of the hold. Limits and funds were checked when the
+// hold was placed, so they are not checked again. The fee is the schedule's fee for the
+// captured amount, but never more than the fee reserved with the hold.
+func (h *holdRepository) Capture(id uint, amount money.Money) (*models.Hold, error) {
+	if !amount.IsPositive() {
+		return nil, appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	var hold *models.Hold
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockActiveHold(tx, id)
+		if err != nil {
+			return err
+		}
+		hold = locked
+
+		// A hold past its TTL that has not been swept yet can no longer be captured.
+		if hold.IsExpired(time.Now()) {
+			return appErrors.NewConflict(nil, fmt.Sprintf("hold %d has expired", hold.Id))
+		}
+
+		exceeds, err := hold.Amount.LessThan(amount)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "capture currency does not match hold currency")
+		}
+		if exceeds {
+			return appErrors.NewUnprocessableEntity(nil, fmt.Sprintf("capture of %s exceeds the %s held", amount, hold.Amount))
+		}
+
+		// The ledger locks the user before their accounts to enforce limits; lock it before
+		// releasing the hold, which locks the account, to keep that order.
+		if err := lockUser(tx, hold.UserId); err != nil {
+			return err
+		}
+
+		fee, _, err := NewFeeRepository(tx).Quote(hold.TransactionType(), hold.UserId, amount)
+		if err != nil {
+			return err
+		}
+		if fee.Minor > hold.FeeMinor {
+			fee = hold.Fee()
+		}
+
+		if err := NewBalancesRepository(tx).Release(hold.BalanceId, hold.Reserved()); err != nil {
+			return err
+		}
+
+		ledger := &ledgerRepository{db: tx, fromAccountId: &hold.BalanceId}
+		var transaction *models.Transaction
+		if hold.ToUserId != nil {
+			transaction, err = ledger.transfer(hold.UserId, *hold.ToUserId, amount, &fee)
+		} else {
+			transaction, err = ledger.withdraw(hold.UserId, amount, &fee)
+		}
+		if err != nil {
+			return err
+		}
+
+		hold.CapturedMinor = amount.Minor
+		hold.TransactionId = &transaction.Id
+		return closeHold(tx, hold, models.HoldStatusCaptured)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (h *holdRepository) Release(id uint) (*models.Hold, error) {
+	var hold *models.Hold
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockActiveHold(tx, id)
+		if err != nil {
+			return err
+		}
+		hold = locked
+
+		if err := NewBalancesRepository(tx).Release(hold.BalanceId, hold.Reserved()); err != nil {
+			return err
+		}
+
+		return closeHold(tx, hold, models.HoldStatusReleased)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ExpireDue releases active holds whose TTL has passed. Holds are locked with SKIP LOCKED, so
+// several instances can run this concurrently. It returns the number of holds expired.
+func (h *holdRepository) ExpireDue(now time.Time, limit int) (int, error) {
+	var holds []models.Hold
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at <= ?", models.HoldStatusActive, now).
+			Order("expires_at, id").
+			Limit(limit).
+			Find(&holds).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim expired holds")
+		}
+
+		for i := range holds {
+			if err := NewBalancesRepository(tx).Release(holds[i].BalanceId, holds[i].Reserved()); err != nil {
+				return err
+			}
+			if err := closeHold(tx, &holds[i], models.HoldStatusExpired); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(holds), nil
+}
+
+// lockActiveHold row-locks a hold for the rest of tx and checks that it is still active.
+func lockActiveHold(tx *gorm.DB, id uint) (*models.Hold, error) {
+	var hold models.Hold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("hold with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to lock hold")
+	}
+
+	if hold.Status != models.HoldStatusActive {
+		return nil, appErrors.NewConflict(nil, fmt.Sprintf("hold %d is already %s", hold.Id, hold.Status))
+	}
+
+	return &hold, nil
+}
+
+func closeHold(tx *gorm.DB, hold *models.Hold, status string) error {
+	now := time.Now()
+	hold.Status = status
+	hold.ClosedAt = &now
+
+	if err := tx.Model(hold).Updates(map[string]interface{}{
+		"status":         hold.Status,
+		"captured_minor": hold.CapturedMinor,
+		"transaction_id": hold.TransactionId,
+		"closed_at":      hold.ClosedAt,
+	}).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update hold %d", hold.Id))
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository interface {
+	Create(job *models.Job) error
+	GetByID(id uint) (*models.Job, error)
+	GetByStatuses(statuses []string) ([]models.Job, error)
+	MarkProcessing(id uint) error
+	MarkCompleted(id uint, transactionId *uint) error
+	MarkFailed(id uint, reason string) error
+	MarkRetrying(id uint, reason string) error
+	MarkRequeued(id uint) error
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (j *jobRepository) Create(job *models.Job) error {
+	if err := j.db.Create(job).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create job")
+	}
+	return nil
+}
+
+func (j *jobRepository) GetByID(id uint) (*models.Job, error) {
+	var job models.Job
+	if err := j.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("job with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get job")
+	}
+	return &job, nil
+}
+
+func (j *jobRepository) GetByStatuses(statuses []string) ([]models.Job, error) {
+	var jobs []models.Job
+	if err := j.db.Where("status IN ?", statuses).Order("id").Find(&jobs).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get jobs by status")
+	}
+	return jobs, nil
+}
+
+func (j *jobRepository) MarkProcessing(id uint) error {
+	now := time.Now()
+	return j.transition(id, []string{models.JobStatusQueued, models.JobStatusProcessing}, map[string]interface{}{
+		"status":     models.JobStatusProcessing,
+		"started_at": &now,
+	})
+}
+
+// MarkCompleted also completes a queued job, whose transaction was recorded by a run that was
+// put back for a retry before it could be acknowledged.
+func (j *jobRepository) MarkCompleted(id uint, transactionId *uint) error {
+	now := time.Now()
+	return j.transition(id, []string{models.JobStatusQueued, models.JobStatusProcessing}, map[string]interface{}{
+		"status":         models.JobStatusCompleted,
+		"transaction_id": transactionId,
+		"failure_reason": "",
+		"completed_at":   &now,
+	})
+}
+
+func (j *jobRepository) MarkFailed(id uint, reason string) error {
+	now := time.Now()
+	return j.transition(id, []string{models.JobStatusQueued, models.JobStatusProcessing}, map[string]interface{}{
+		"status":         models.JobStatusFailed,
+		"failure_reason": reason,
+		"completed_at":   &now,
+	})
+}
+
+func (j *jobRepository) MarkRetrying(id uint, reason string) error {
+	return j.transition(id, []string{models.JobStatusProcessing}, map[string]interface{}{
+		"status":         models.JobStatusQueued,
+		"failure_reason": reason,
+	})
+}
+
+// MarkRequeued moves a failed job back to queued. A job whose standing order occurrence or
+// payment request already settled it as failed is refused: the order may have retried it and
+// the request may have been accepted again, so running it again would move the money twice.
+func (j *jobRepository) MarkRequeued(id uint) error {
+	return j.db.Transaction(func(tx *gorm.DB) error {
+		job, err := (&jobRepository{db: tx}).GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		if err := lockJobOwner(tx, job); err != nil {
+			return err
+		}
+
+		return (&jobRepository{db: tx}).transition(id, []string{models.JobStatusFailed}, map[string]interface{}{
+			"status":         models.JobStatusQueued,
+			"failure_reason": "",
+			"started_at":     nil,
+			"completed_at":   nil,
+		})
+	})
+}
+
+func (j *jobRepository) transition(id uint, from []string, updates map[string]interface{}) error {
+	result := j.db.Model(&models.Job{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to update job %d", id))
+	}
+
+	if result.RowsAffected == 0 {
+		return appErrors.NewConflict(nil, fmt.Sprintf("job %d cannot move to %v from its current state", id, updates["status"]))
+	}
+
+	return nil
+}
+
+// lockJobOwner row-locks the standing order occurrence or payment request a job was created
+// for, if any, for the rest of tx and checks that it is still waiting for the job's outcome.
+// The owners' SettleFinished lock the same rows, so a job cannot be settled while it is
+// requeued.
+func lockJobOwner(tx *gorm.DB, job *models.Job) error {
+	var occurrence models.StandingOrderOccurrence
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("job_id = ?", job.Id).First(&occurrence).Error
+	if err == nil {
+		if occurrence.Status != models.OccurrenceStatusSubmitting && occurrence.Status != models.OccurrenceStatusSubmitted {
+			return appErrors.NewConflict(nil, fmt.Sprintf("job %d was already settled as %s by standing order occurrence %d", job.Id, occurrence.Status, occurrence.Id))
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to lock the owner of job %d", job.Id))
+	}
+
+	if job.Reference == nil || !strings.HasPrefix(*job.Reference, models.PaymentRequestJobReferencePrefix) {
+		return nil
+	}
+
+	// A request drops its job when it settles it as failed, so one that no longer points at
+	// the job has moved on.
+	var request models.PaymentRequest
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("job_id = ? AND status = ?", job.Id, models.PaymentRequestStatusAccepted).
+		First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return appErrors.NewConflict(nil, fmt.Sprintf("job %d was already settled as failed by its payment request", job.Id))
+	}
+	if err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to lock the owner of job %d", job.Id))
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+// TestMarkRequeuedPaymentRequest checks that a failed payment request transfer can be requeued
+// while the request still waits for it, but not once the request was settled as failed and
+// could be accepted, and paid, again.
+//
+//	DATABASE_CONNECTION_URL=... go test -tags integration ./internal/repositories -run MarkRequeued
+func TestMarkRequeuedPaymentRequest(t *testing.T) {
+	if os.Getenv("DATABASE_CONNECTION_URL") == "" {
+		t.Skip("DATABASE_CONNECTION_URL is not set")
+	}
+
+	logger.InitializeLogger()
+	database.InitializeDb()
+
+	users, err := createTestUsers(database.Db, "requeue", 2)
+	if err != nil {
+		t.Fatalf("failed to create users: %v", err)
+	}
+
+	requests := repositories.NewPaymentRequestRepository(database.Db)
+	jobs := repositories.NewJobRepository(database.Db)
+
+	request := &models.PaymentRequest{
+		RequesterId: users[0].Id,
+		PayerId:     users[1].Id,
+		Amount:      money.MustParse("10.00", money.DefaultCurrency),
+		Status:      models.PaymentRequestStatusPending,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	if err := requests.Create(request); err != nil {
+		t.Fatalf("failed to create payment request: %v", err)
+	}
+
+	accepted, err := requests.Accept(request.Id, nil, time.Now())
+	if err != nil {
+		t.Fatalf("failed to accept payment request: %v", err)
+	}
+	jobId := *accepted.JobId
+
+	if err := jobs.MarkFailed(jobId, "dead-lettered"); err != nil {
+		t.Fatalf("failed to fail job: %v", err)
+	}
+	if err := jobs.MarkRequeued(jobId); err != nil {
+		t.Fatalf("MarkRequeued() of a job its request still waits for: %v", err)
+	}
+
+	if err := jobs.MarkFailed(jobId, "dead-lettered"); err != nil {
+		t.Fatalf("failed to fail job: %v", err)
+	}
+	for {
+		settled, err := requests.SettleFinished(time.Now(), 100)
+		if err != nil {
+			t.Fatalf("failed to settle payment requests: %v", err)
+		}
+		if len(settled) < 100 {
+			break
+		}
+	}
+
+	reopened, err := requests.GetByID(request.Id)
+	if err != nil {
+		t.Fatalf("failed to get payment request: %v", err)
+	}
+	if reopened.Status != models.PaymentRequestStatusPending {
+		t.Fatalf("payment request status = %s, want %s", reopened.Status, models.PaymentRequestStatusPending)
+	}
+
+	err = jobs.MarkRequeued(jobId)
+	if appErrors.GetStatusCode(err) != appErrors.ErrCodeConflict {
+		t.Fatalf("MarkRequeued() of a job its request settled as failed: error = %v, want conflict", err)
+	}
+
+	job, err := jobs.GetByID(jobId)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if job.Status != models.JobStatusFailed {
+		t.Errorf("job status = %s, want %s", job.Status, models.JobStatusFailed)
+	}
+}
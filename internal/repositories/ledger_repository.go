@@ -0,0 +1,694 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository interface {
+	ForJob(jobId uint) LedgerRepository
+	FromAccount(accountId uint) LedgerRepository
+	ToAccount(accountId uint) LedgerRepository
+	Deposit(userId uint, amount money.Money, transactionType string) (*models.Transaction, error)
+	Withdraw(userId uint, amount money.Money) (*models.Transaction, error)
+	Charge(userId uint, amount money.Money, transactionType string) (*models.Transaction, error)
+	Transfer(fromUserId, toUserId uint, amount money.Money) (*models.Transaction, error)
+	TransferAll(plan TransferPlan, check func(Transfer) error) ([]*models.Transaction, error)
+	Convert(fromUserId, toUserId uint, source, target money.Money) (*models.Transaction, error)
+	Reverse(transactionId uint, amount money.Money) (*models.Transaction, error)
+	GetPostingsByAccount(account string, limit, offset int) ([]models.Posting, error)
+	GetAccountBalance(account string, currency string) (money.Money, error)
+	CheckInvariants() (*LedgerInvariantReport, error)
+}
+
+// Transfer is one transfer of a TransferPlan.
+type Transfer struct {
+	FromUserId uint
+	ToUserId   uint
+	Amount     money.Money
+}
+
+// TransferPlan decides a set of transfers and records them in the database transaction that
+// makes them; see LedgerRepository.TransferAll.
+type TransferPlan interface {
+	// Transfers locks whatever decides the transfers in tx and returns them.
+	Transfers(tx *gorm.DB) ([]Transfer, error)
+	// Record writes what the plan keeps of a transfer that went through, in tx.
+	Record(tx *gorm.DB, transfer Transfer, transaction *models.Transaction) error
+}
+
+type CurrencyImbalance struct {
+	Currency string `json:"currency"`
+	Minor    int64  `json:"minor_units"`
+}
+
+type AccountMismatch struct {
+	UserId       uint   `json:"user_id"`
+	Currency     string `json:"currency"`
+	BalanceMinor int64  `json:"balance_minor_units"`
+	LedgerMinor  int64  `json:"ledger_minor_units"`
+}
+
+type LedgerInvariantReport struct {
+	Balanced   bool                `json:"balanced"`
+	Imbalances []CurrencyImbalance `json:"imbalances"`
+	Mismatches []AccountMismatch   `json:"mismatches"`
+	CheckedAt  time.Time           `json:"checked_at"`
+}
+
+// ledgerRepository moves money between users' accounts. Unless an account is given with
+// FromAccount or ToAccount, money moves out of and into the user's default account in the
+// currency. Postings are made per user, so the ledger balance of a user in a currency is the
+// sum of their accounts in it.
+type ledgerRepository struct {
+	db            *gorm.DB
+	jobId         *uint
+	fromAccountId *uint
+	toAccountId   *uint
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// ForJob returns a repository that links every transaction it records to the given job,
+// so a job that is delivered twice cannot move money twice.
+func (l *ledgerRepository) ForJob(jobId uint) LedgerRepository {
+	scoped := *l
+	scoped.jobId = &jobId
+	return &scoped
+}
+
+// FromAccount returns a repository that debits the given account of the paying user instead
+// of their default account.
+func (l *ledgerRepository) FromAccount(accountId uint) LedgerRepository {
+	scoped := *l
+	scoped.fromAccountId = &accountId
+	return &scoped
+}
+
+// ToAccount returns a repository that credits the given account of the receiving user instead
+// of their default account.
+func (l *ledgerRepository) ToAccount(accountId uint) LedgerRepository {
+	scoped := *l
+	scoped.toAccountId = &accountId
+	return &scoped
+}
+
+func (l *ledgerRepository) Deposit(userId uint, amount money.Money, transactionType string) (*models.Transaction, error) {
+	var transaction *models.Transaction
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		balances := NewBalancesRepository(tx)
+		account, err := balances.ResolveAccount(userId, l.toAccountId, amount.Currency, true)
+		if err != nil {
+			return err
+		}
+		if err := balances.Credit(account.Id, amount); err != nil {
+			return err
+		}
+
+		transaction = &models.Transaction{
+			FromUserId:  nil,
+			ToUserId:    &userId,
+			ToAccountId: &account.Id,
+			Amount:      amount,
+			Type:        transactionType,
+			Status:      models.TransactionStatusCompleted,
+			CreatedAt:   time.Now(),
+		}
+
+		return l.record(tx, transaction, []models.Posting{
+			{Account: counterAccount(transaction), Amount: amount.Negate()},
+			{Account: models.UserAccount(userId), Amount: amount},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (l *ledgerRepository) Withdraw(userId uint, amount money.Money) (*models.Transaction, error) {
+	return l.withdraw(userId, amount, nil)
+}
+
+// withdraw pays amount out of the system. Without a settled fee the user's limits are enforced
+// and the fee schedule is applied; with one, both were checked when the money was reserved (see
+// holdRepository.Create) and settledFee is charged as is.
+func (l *ledgerRepository) withdraw(userId uint, amount money.Money, settledFee *money.Money) (*models.Transaction, error) {
+	var transaction *models.Transaction
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		if settledFee == nil {
+			if err := enforceLimits(tx, userId, amount); err != nil {
+				return err
+			}
+		}
+
+		balances := NewBalancesRepository(tx)
+		account, err := balances.ResolveAccount(userId, l.fromAccountId, amount.Currency, false)
+		if err != nil {
+			return err
+		}
+		fee, err := settledFeeOr(tx, settledFee, models.TransactionTypeWithdraw, account, amount)
+		if err != nil {
+			return err
+		}
+		if err := balances.Debit(account.Id, amount); err != nil {
+			return err
+		}
+
+		transaction = &models.Transaction{
+			FromUserId:    &userId,
+			ToUserId:      nil,
+			FromAccountId: &account.Id,
+			Amount:        amount,
+			Type:          models.TransactionTypeWithdraw,
+			Status:        models.TransactionStatusCompleted,
+			CreatedAt:     time.Now(),
+		}
+
+		if err := l.record(tx, transaction, []models.Posting{
+			{Account: models.UserAccount(userId), Amount: amount.Negate()},
+			{Account: models.SystemExternalAccount, Amount: amount},
+		}); err != nil {
+			return err
+		}
+
+		return l.chargeFee(tx, transaction, fee)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// Charge debits a charge the system levies, such as overdraft interest, from the user's
+// account. Charges are taken even if they overdraw the account past its overdraft limit, and
+// count against neither the user's limits nor the fee schedule.
+func (l *ledgerRepository) Charge(userId uint, amount money.Money, transactionType string) (*models.Transaction, error) {
+	var transaction *models.Transaction
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		balances := NewBalancesRepository(tx)
+		account, err := balances.ResolveAccount(userId, l.fromAccountId, amount.Currency, false)
+		if err != nil {
+			return err
+		}
+		if err := balances.Charge(account.Id, amount); err != nil {
+			return err
+		}
+
+		transaction = &models.Transaction{
+			FromUserId:    &userId,
+			ToUserId:      nil,
+			FromAccountId: &account.Id,
+			Amount:        amount,
+			Type:          transactionType,
+			Status:        models.TransactionStatusCompleted,
+			CreatedAt:     time.Now(),
+		}
+
+		return l.record(tx, transaction, []models.Posting{
+			{Account: models.UserAccount(userId), Amount: amount.Negate()},
+			{Account: counterAccount(transaction), Amount: amount},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (l *ledgerRepository) Transfer(fromUserId, toUserId uint, amount money.Money) (*models.Transaction, error) {
+	return l.transfer(fromUserId, toUserId, amount, nil)
+}
+
+// TransferAll makes the transfers of plan in one database transaction with the plan's own
+// reads and writes, using the repository's accounts: either every transfer passes check, goes
+// through and is recorded, or none does and the first error is returned.
+func (l *ledgerRepository) TransferAll(plan TransferPlan, check func(Transfer) error) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		transfers, err := plan.Transfers(tx)
+		if err != nil {
+			return err
+		}
+
+		scoped := *l
+		scoped.db = tx
+		for _, transfer := range transfers {
+			if err := check(transfer); err != nil {
+				return err
+			}
+
+			transaction, err := scoped.Transfer(transfer.FromUserId, transfer.ToUserId, transfer.Amount)
+			if err != nil {
+				return err
+			}
+
+			if err := plan.Record(tx, transfer, transaction); err != nil {
+				return err
+			}
+			transactions = append(transactions, transaction)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// transfer moves amount between users. A settled fee skips the limit and fee schedule checks,
+// as for withdraw.
+func (l *ledgerRepository) transfer(fromUserId, toUserId uint, amount money.Money, settledFee *money.Money) (*models.Transaction, error) {
+	var transaction *models.Transaction
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		// Moving money between one's own accounts is neither limited nor charged a fee.
+		if fromUserId != toUserId && settledFee == nil {
+			if err := enforceLimits(tx, fromUserId, amount); err != nil {
+				return err
+			}
+		}
+
+		from, to, err := l.resolveAccounts(tx, fromUserId, toUserId, amount.Currency, amount.Currency)
+		if err != nil {
+			return err
+		}
+		fee := money.Zero(amount.Currency)
+		if fromUserId != toUserId {
+			if fee, err = settledFeeOr(tx, settledFee, models.TransactionTypeTransfer, from, amount); err != nil {
+				return err
+			}
+		}
+		if err := NewBalancesRepository(tx).Move(from.Id, to.Id, amount); err != nil {
+			return err
+		}
+
+		transaction = &models.Transaction{
+			FromUserId:    &fromUserId,
+			ToUserId:      &toUserId,
+			FromAccountId: &from.Id,
+			ToAccountId:   &to.Id,
+			Amount:        amount,
+			Type:          models.TransactionTypeTransfer,
+			Status:        models.TransactionStatusCompleted,
+			CreatedAt:     time.Now(),
+		}
+
+		if err := l.record(tx, transaction, []models.Posting{
+			{Account: models.UserAccount(fromUserId), Amount: amount.Negate()},
+			{Account: models.UserAccount(toUserId), Amount: amount},
+		}); err != nil {
+			return err
+		}
+
+		return l.chargeFee(tx, transaction, fee)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// Convert debits source from one user and credits target, in another currency, to another
+// (or the same) user. Each currency is balanced through the FX system account, which so
+// holds the currency position taken on by conversions. Converting into another user's
+// account is limited and charged like a transfer; converting between one's own accounts is
+// neither.
+func (l *ledgerRepository) Convert(fromUserId, toUserId uint, source, target money.Money) (*models.Transaction, error) {
+	var transaction *models.Transaction
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		if fromUserId != toUserId {
+			if err := enforceLimits(tx, fromUserId, source); err != nil {
+				return err
+			}
+		}
+
+		from, to, err := l.resolveAccounts(tx, fromUserId, toUserId, source.Currency, target.Currency)
+		if err != nil {
+			return err
+		}
+		fee := money.Zero(source.Currency)
+		if fromUserId != toUserId {
+			if fee, err = feeFor(tx, models.TransactionTypeConversion, from, source); err != nil {
+				return err
+			}
+		}
+		if err := NewBalancesRepository(tx).Exchange(from.Id, to.Id, source, target); err != nil {
+			return err
+		}
+
+		transaction = &models.Transaction{
+			FromUserId:    &fromUserId,
+			ToUserId:      &toUserId,
+			FromAccountId: &from.Id,
+			ToAccountId:   &to.Id,
+			Amount:        source,
+			Type:          models.TransactionTypeConversion,
+			Status:        models.TransactionStatusCompleted,
+			CreatedAt:     time.Now(),
+		}
+
+		if err := l.record(tx, transaction, []models.Posting{
+			{Account: models.UserAccount(fromUserId), Amount: source.Negate()},
+			{Account: models.SystemFxAccount, Amount: source},
+			{Account: models.SystemFxAccount, Amount: target.Negate()},
+			{Account: models.UserAccount(toUserId), Amount: target},
+		}); err != nil {
+			return err
+		}
+
+		return l.chargeFee(tx, transaction, fee)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// Reverse refunds amount of a completed transaction by moving it back the way it came, in a
+// compensating reversal transaction linked to the original. The original is row-locked, so
+// concurrent reversals cannot refund more than its amount between them.
+func (l *ledgerRepository) Reverse(transactionId uint, amount money.Money) (*models.Transaction, error) {
+	if !amount.IsPositive() {
+		return nil, appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	var reversal *models.Transaction
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		var original models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&original, transactionId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return appErrors.NewNotFound(err, fmt.Sprintf("transaction with id %d not found", transactionId))
+			}
+			return appErrors.NewDatabaseError(err, "failed to lock transaction")
+		}
+
+		if !original.IsReversible() {
+			return appErrors.NewConflict(nil, fmt.Sprintf("transaction %d is %s and cannot be reversed", original.Id, original.Status))
+		}
+
+		refundable := original.Refundable()
+		exceeds, err := refundable.LessThan(amount)
+		if err != nil {
+			return appErrors.NewBadRequest(err, "refund currency does not match transaction currency")
+		}
+		if exceeds {
+			return appErrors.NewUnprocessableEntity(nil, fmt.Sprintf("refund of %s exceeds the %s left to refund on transaction %d", amount, refundable, original.Id))
+		}
+
+		balances := NewBalancesRepository(tx)
+		reversal = &models.Transaction{
+			Amount:       amount,
+			Type:         models.TransactionTypeReversal,
+			Status:       models.TransactionStatusCompleted,
+			ReversalOfId: &original.Id,
+			CreatedAt:    time.Now(),
+		}
+
+		var postings []models.Posting
+		switch {
+		case original.FromUserId != nil && original.ToUserId != nil:
+			from, err := refundAccount(balances, *original.ToUserId, original.ToAccountId, amount.Currency, false)
+			if err != nil {
+				return err
+			}
+			to, err := refundAccount(balances, *original.FromUserId, original.FromAccountId, amount.Currency, true)
+			if err != nil {
+				return err
+			}
+			if err := balances.Move(from.Id, to.Id, amount); err != nil {
+				return err
+			}
+			reversal.FromUserId, reversal.ToUserId = original.ToUserId, original.FromUserId
+			reversal.FromAccountId, reversal.ToAccountId = &from.Id, &to.Id
+			postings = []models.Posting{
+				{Account: models.UserAccount(*original.ToUserId), Amount: amount.Negate()},
+				{Account: models.UserAccount(*original.FromUserId), Amount: amount},
+			}
+		case original.ToUserId != nil:
+			from, err := refundAccount(balances, *original.ToUserId, original.ToAccountId, amount.Currency, false)
+			if err != nil {
+				return err
+			}
+			if err := balances.Debit(from.Id, amount); err != nil {
+				return err
+			}
+			reversal.FromUserId = original.ToUserId
+			reversal.FromAccountId = &from.Id
+			postings = []models.Posting{
+				{Account: models.UserAccount(*original.ToUserId), Amount: amount.Negate()},
+				{Account: counterAccount(&original), Amount: amount},
+			}
+		case original.FromUserId != nil:
+			to, err := refundAccount(balances, *original.FromUserId, original.FromAccountId, amount.Currency, true)
+			if err != nil {
+				return err
+			}
+			if err := balances.Credit(to.Id, amount); err != nil {
+				return err
+			}
+			reversal.ToUserId = original.FromUserId
+			reversal.ToAccountId = &to.Id
+			postings = []models.Posting{
+				{Account: counterAccount(&original), Amount: amount.Negate()},
+				{Account: models.UserAccount(*original.FromUserId), Amount: amount},
+			}
+		default:
+			return appErrors.NewConflict(nil, fmt.Sprintf("transaction %d has no accounts to reverse", original.Id))
+		}
+
+		original.RefundedMinor += amount.Minor
+		original.Status = models.TransactionStatusPartiallyReversed
+		if original.RefundedMinor == original.Amount.Minor {
+			original.Status = models.TransactionStatusReversed
+		}
+
+		if err := tx.Model(&original).Updates(map[string]interface{}{
+			"refunded_minor": original.RefundedMinor,
+			"status":         original.Status,
+		}).Error; err != nil {
+			return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to mark transaction %d as reversed", original.Id))
+		}
+
+		return l.record(tx, reversal, postings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+func (l *ledgerRepository) GetPostingsByAccount(account string, limit, offset int) ([]models.Posting, error) {
+	var postings []models.Posting
+	if err := l.db.Where("account = ?", account).
+		Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&postings).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get account postings")
+	}
+	return postings, nil
+}
+
+func (l *ledgerRepository) GetAccountBalance(account string, currency string) (money.Money, error) {
+	var total int64
+	if err := l.db.Model(&models.Posting{}).
+		Where("account = ? AND amount_currency = ?", account, currency).
+		Select("COALESCE(SUM(amount_minor), 0)").
+		Scan(&total).Error; err != nil {
+		return money.Money{}, appErrors.NewDatabaseError(err, "failed to compute account balance from ledger")
+	}
+	return money.New(total, currency), nil
+}
+
+func (l *ledgerRepository) CheckInvariants() (*LedgerInvariantReport, error) {
+	report := &LedgerInvariantReport{
+		Imbalances: []CurrencyImbalance{},
+		Mismatches: []AccountMismatch{},
+		CheckedAt:  time.Now(),
+	}
+
+	if err := l.db.Model(&models.Posting{}).
+		Select("amount_currency AS currency, SUM(amount_minor) AS minor").
+		Group("amount_currency").
+		Having("SUM(amount_minor) <> 0").
+		Scan(&report.Imbalances).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to sum ledger postings")
+	}
+
+	// Postings are per user, so they are compared with the total of the user's accounts.
+	if err := l.db.Raw(`
+		SELECT b.user_id, b.currency, b.balance_minor, COALESCE(p.ledger_minor, 0) AS ledger_minor
+		FROM (
+			SELECT user_id, amount_currency AS currency, SUM(amount_minor) AS balance_minor
+			FROM balances
+			GROUP BY user_id, amount_currency
+		) b
+		LEFT JOIN (
+			SELECT account, amount_currency, SUM(amount_minor) AS ledger_minor
+			FROM postings
+			GROUP BY account, amount_currency
+		) p ON p.account = CONCAT('user:', b.user_id) AND p.amount_currency = b.currency
+		WHERE b.balance_minor <> COALESCE(p.ledger_minor, 0)`).
+		Scan(&report.Mismatches).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to compare balances with ledger")
+	}
+
+	report.Balanced = len(report.Imbalances) == 0 && len(report.Mismatches) == 0
+	return report, nil
+}
+
+// resolveAccounts picks the paying and the receiving account of a transfer or conversion.
+func (l *ledgerRepository) resolveAccounts(tx *gorm.DB, fromUserId, toUserId uint, fromCurrency, toCurrency string) (*models.Balance, *models.Balance, error) {
+	balances := NewBalancesRepository(tx)
+
+	from, err := balances.ResolveAccount(fromUserId, l.fromAccountId, fromCurrency, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to, err := balances.ResolveAccount(toUserId, l.toAccountId, toCurrency, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return from, to, nil
+}
+
+// refundAccount resolves the account a reversal moves money out of or back into: the account
+// of the original transaction, or the user's default account if the transaction predates
+// accounts or, when refunding into it, the original account has been closed since.
+func refundAccount(balances BalancesRepository, userId uint, accountId *uint, currency string, credit bool) (*models.Balance, error) {
+	if accountId != nil {
+		account, err := balances.GetByID(*accountId)
+		if err != nil {
+			return nil, err
+		}
+		if !credit || !account.IsClosed() {
+			return account, nil
+		}
+	}
+
+	return balances.ResolveAccount(userId, nil, currency, credit)
+}
+
+// enforceLimits locks the paying user, which serializes their outgoing transactions, and
+// checks amount against their limits. The user is locked before any of their accounts.
+func enforceLimits(tx *gorm.DB, userId uint, amount money.Money) error {
+	if err := lockUser(tx, userId); err != nil {
+		return err
+	}
+	return NewLimitRepository(tx).Enforce(userId, amount, time.Now())
+}
+
+// feeFor returns the fee the fee schedule sets for a transaction of amount paid from account,
+// and checks that the account can cover both.
+func feeFor(tx *gorm.DB, transactionType string, account *models.Balance, amount money.Money) (money.Money, error) {
+	fee, _, err := NewFeeRepository(tx).Quote(transactionType, account.UserId, amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if fee.IsPositive() {
+		if available := account.Available(); available.Minor-fee.Minor < amount.Minor {
+			return money.Money{}, appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds in account %d: requested %s plus a fee of %s, available %s", account.Id, amount, fee, available))
+		}
+	}
+
+	return fee, nil
+}
+
+// settledFeeOr returns settledFee if given, and the fee the schedule sets otherwise.
+func settledFeeOr(tx *gorm.DB, settledFee *money.Money, transactionType string, account *models.Balance, amount money.Money) (money.Money, error) {
+	if settledFee != nil {
+		return *settledFee, nil
+	}
+	return feeFor(tx, transactionType, account, amount)
+}
+
+// chargeFee debits fee from the account transaction was paid from, in a fee transaction
+// linked to it. A zero fee charges nothing.
+func (l *ledgerRepository) chargeFee(tx *gorm.DB, transaction *models.Transaction, fee money.Money) error {
+	if !fee.IsPositive() {
+		return nil
+	}
+
+	if err := NewBalancesRepository(tx).Debit(*transaction.FromAccountId, fee); err != nil {
+		return err
+	}
+
+	charge := &models.Transaction{
+		FromUserId:    transaction.FromUserId,
+		FromAccountId: transaction.FromAccountId,
+		Amount:        fee,
+		Type:          models.TransactionTypeFee,
+		Status:        models.TransactionStatusCompleted,
+		FeeOfId:       &transaction.Id,
+		CreatedAt:     time.Now(),
+	}
+
+	// The job id is unique, so it stays on the transaction the fee was charged for.
+	unscoped := *l
+	unscoped.jobId = nil
+	return unscoped.record(tx, charge, []models.Posting{
+		{Account: models.UserAccount(*transaction.FromUserId), Amount: fee.Negate()},
+		{Account: models.SystemFeesAccount, Amount: fee},
+	})
+}
+
+// counterAccount returns the system account on the other side of a transaction that only
+// one user took part in.
+func counterAccount(transaction *models.Transaction) string {
+	switch transaction.Type {
+	case models.TransactionTypeFee:
+		return models.SystemFeesAccount
+	case models.TransactionTypeInterest, models.TransactionTypeOverdraftInterest:
+		return models.SystemInterestAccount
+	}
+	return models.SystemExternalAccount
+}
+
+func (l *ledgerRepository) record(tx *gorm.DB, transaction *models.Transaction, postings []models.Posting) error {
+	entry := &models.JournalEntry{
+		Type:     transaction.Type,
+		Postings: postings,
+	}
+
+	if err := entry.Validate(); err != nil {
+		return appErrors.NewInternalServerError(err)
+	}
+
+	transaction.JobId = l.jobId
+
+	if err := tx.Create(transaction).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create transaction record")
+	}
+
+	entry.TransactionId = &transaction.Id
+	if err := tx.Create(entry).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to write journal entry")
+	}
+
+	return nil
+}
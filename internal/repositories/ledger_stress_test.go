@@ -0,0 +1,190 @@
+//go:build integration
+
+package repositories_test
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+var (
+	stressReplicas = flag.Int("stress.replicas", 4, "number of independent connection pools")
+	stressWorkers  = flag.Int("stress.workers", 16, "concurrent goroutines per replica")
+	stressOps      = flag.Int("stress.ops", 200, "operations per goroutine")
+	stressAccounts = flag.Int("stress.accounts", 3, "number of balances to contend on")
+)
+
+// TestLedgerStress hammers a few balances with concurrent deposits, withdrawals and transfers
+// through several independent connection pools, each standing in for an app replica, and then
+// checks that no update was lost, no balance went negative and the ledger still balances.
+// Operations rejected for insufficient funds (409) or by transaction limits (422) are counted
+// but do not fail the test; fees are read back from the ledger.
+//
+//	DATABASE_CONNECTION_URL=... go test -tags integration ./internal/repositories -run LedgerStress -args -stress.replicas 4
+func TestLedgerStress(t *testing.T) {
+	dsn := os.Getenv("DATABASE_CONNECTION_URL")
+	if dsn == "" {
+		t.Skip("DATABASE_CONNECTION_URL is not set")
+	}
+	if *stressAccounts < 2 {
+		t.Fatal("at least 2 accounts are needed to exercise transfers")
+	}
+
+	logger.InitializeLogger()
+	database.InitializeDb()
+
+	users, err := createTestUsers(database.Db, "stress", *stressAccounts)
+	if err != nil {
+		t.Fatalf("failed to create stress users: %v", err)
+	}
+
+	opening := money.MustParse("100.00", money.DefaultCurrency)
+	ledger := repositories.NewLedgerRepository(database.Db)
+	for _, user := range users {
+		if _, err := ledger.Deposit(user.Id, opening, models.TransactionTypeDeposit); err != nil {
+			t.Fatalf("failed to fund user %d: %v", user.Id, err)
+		}
+	}
+
+	var (
+		net        atomic.Int64
+		succeeded  atomic.Int64
+		rejected   atomic.Int64
+		unexpected atomic.Value
+		wg         sync.WaitGroup
+	)
+
+	started := time.Now()
+	for r := 0; r < *stressReplicas; r++ {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+		})
+		if err != nil {
+			t.Fatalf("failed to open replica connection: %v", err)
+		}
+		replicaLedger := repositories.NewLedgerRepository(db)
+
+		for w := 0; w < *stressWorkers; w++ {
+			wg.Add(1)
+			go func(seed int64) {
+				defer wg.Done()
+				rnd := rand.New(rand.NewSource(seed))
+
+				for i := 0; i < *stressOps && unexpected.Load() == nil; i++ {
+					amount := money.New(int64(rnd.Intn(5000)+1), money.DefaultCurrency)
+					index := rnd.Intn(len(users))
+					from := users[index].Id
+					to := users[(index+1+rnd.Intn(len(users)-1))%len(users)].Id
+
+					var err error
+					delta := int64(0)
+					switch rnd.Intn(3) {
+					case 0:
+						_, err = replicaLedger.Deposit(from, amount, models.TransactionTypeDeposit)
+						delta = amount.Minor
+					case 1:
+						_, err = replicaLedger.Withdraw(from, amount)
+						delta = -amount.Minor
+					default:
+						_, err = replicaLedger.Transfer(from, to, amount)
+					}
+
+					code := appErrors.GetStatusCode(err)
+					switch {
+					case err == nil:
+						net.Add(delta)
+						succeeded.Add(1)
+					case code == appErrors.ErrCodeConflict || code == appErrors.ErrCodeValidation:
+						rejected.Add(1)
+					default:
+						unexpected.CompareAndSwap(nil, err)
+						return
+					}
+				}
+			}(time.Now().UnixNano() + int64(r*1000+w))
+		}
+	}
+	wg.Wait()
+
+	if err, ok := unexpected.Load().(error); ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := make([]uint, 0, len(users))
+	var total int64
+	for _, user := range users {
+		ids = append(ids, user.Id)
+
+		var balance models.Balance
+		if err := database.Db.Where("user_id = ? AND amount_currency = ? AND is_default", user.Id, opening.Currency).First(&balance).Error; err != nil {
+			t.Fatalf("failed to read balance of user %d: %v", user.Id, err)
+		}
+		if balance.Amount.IsNegative() {
+			t.Errorf("balance of user %d went negative: %s", user.Id, balance.Amount)
+		}
+		total += balance.Amount.Minor
+	}
+
+	var fees int64
+	if err := database.Db.Model(&models.Transaction{}).
+		Where("type = ? AND from_user_id IN ?", models.TransactionTypeFee, ids).
+		Select("COALESCE(SUM(amount_minor), 0)").
+		Scan(&fees).Error; err != nil {
+		t.Fatalf("failed to sum fees: %v", err)
+	}
+
+	expected := opening.Minor*int64(len(users)) + net.Load() - fees
+	if total != expected {
+		t.Errorf("lost update: balances sum to %d minor units, expected %d (fees %d)", total, expected, fees)
+	}
+
+	report, err := ledger.CheckInvariants()
+	if err != nil {
+		t.Fatalf("failed to check ledger invariants: %v", err)
+	}
+	if !report.Balanced {
+		t.Errorf("ledger out of balance: %+v", report)
+	}
+
+	t.Logf("%d operations succeeded, %d rejected for insufficient funds or limits, %d minor units of fees in %s",
+		succeeded.Load(), rejected.Load(), fees, time.Since(started).Round(time.Millisecond))
+}
+
+func createTestUsers(db *gorm.DB, prefix string, count int) ([]*models.User, error) {
+	suffix := time.Now().UnixNano()
+	users := make([]*models.User, 0, count)
+
+	for i := 0; i < count; i++ {
+		user, err := models.NewUser(
+			fmt.Sprintf("%s-%d-%d", prefix, suffix, i),
+			fmt.Sprintf("%s-%d-%d@example.com", prefix, suffix, i),
+			prefix+"-password",
+			"user",
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Create(user).Error; err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
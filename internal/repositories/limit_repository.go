@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LimitRepository interface {
+	Upsert(limit *models.TransactionLimit) error
+	List(limit, offset int) ([]models.TransactionLimit, error)
+	DeleteUserLimit(userId uint, currency string) error
+	GetEffective(userId uint, currency string) (*models.TransactionLimit, error)
+	GetUsage(userId uint, currency string, now time.Time) (models.LimitUsage, error)
+	Enforce(userId uint, amount money.Money, now time.Time) error
+	Precheck(userId uint, amount money.Money, now time.Time) error
+}
+
+type limitRepository struct {
+	db *gorm.DB
+}
+
+func NewLimitRepository(db *gorm.DB) LimitRepository {
+	return &limitRepository{db: db}
+}
+
+// Upsert creates the limit of a role or user in a currency, or replaces it.
+func (r *limitRepository) Upsert(limit *models.TransactionLimit) error {
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "role"}, {Name: "user_id"}, {Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"max_single_minor", "daily_minor", "monthly_minor", "daily_count", "monthly_count", "updated_at",
+		}),
+	}).Create(limit).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to save transaction limit")
+	}
+	return nil
+}
+
+func (r *limitRepository) List(limit, offset int) ([]models.TransactionLimit, error) {
+	var limits []models.TransactionLimit
+	if err := r.db.Order("user_id, role, currency").
+		Limit(limit).Offset(offset).
+		Find(&limits).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get transaction limits")
+	}
+	return limits, nil
+}
+
+// DeleteUserLimit removes a user's override, so the limit of their role applies again.
+func (r *limitRepository) DeleteUserLimit(userId uint, currency string) error {
+	result := r.db.Where("user_id = ? AND currency = ?", userId, currency).Delete(&models.TransactionLimit{})
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, "failed to delete transaction limit")
+	}
+	if result.RowsAffected == 0 {
+		return appErrors.NewNotFound(nil, fmt.Sprintf("user %d has no %s limit override", userId, currency))
+	}
+	return nil
+}
+
+// GetEffective returns the limit that applies to a user in currency: their own override if
+// they have one, and the limit of their role otherwise. It returns nil if they are unlimited.
+func (r *limitRepository) GetEffective(userId uint, currency string) (*models.TransactionLimit, error) {
+	role, err := getUserRole(r.db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var limits []models.TransactionLimit
+	if err := r.db.Where("currency = ? AND (user_id = ? OR (user_id = 0 AND role = ?))", currency, userId, role).
+		Order("user_id DESC").
+		Limit(1).
+		Find(&limits).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get transaction limit")
+	}
+
+	if len(limits) == 0 {
+		return nil, nil
+	}
+	return &limits[0], nil
+}
+
+// GetUsage sums what the user has moved out in currency since the start of the current UTC
+// day and month, including what active holds have reserved to move out.
+func (r *limitRepository) GetUsage(userId uint, currency string, now time.Time) (models.LimitUsage, error) {
+	dayStart, monthStart := models.LimitWindowStarts(now)
+	const usageColumns = `COALESCE(SUM(amount_minor) FILTER (WHERE created_at >= ?), 0) AS daily_minor,
+			COUNT(*) FILTER (WHERE created_at >= ?) AS daily_count,
+			COALESCE(SUM(amount_minor), 0) AS monthly_minor,
+			COUNT(*) AS monthly_count`
+
+	var usage models.LimitUsage
+	if err := r.db.Model(&models.Transaction{}).
+		Select(usageColumns, dayStart, dayStart).
+		Where("from_user_id = ? AND amount_currency = ? AND type IN ? AND created_at >= ?", userId, currency, models.LimitedTransactionTypes, monthStart).
+		Where("to_user_id IS DISTINCT FROM from_user_id").
+		Scan(&usage).Error; err != nil {
+		return models.LimitUsage{}, appErrors.NewDatabaseError(err, "failed to get transaction limit usage")
+	}
+
+	var held models.LimitUsage
+	if err := r.db.Model(&models.Hold{}).
+		Select(usageColumns, dayStart, dayStart).
+		Where("user_id = ? AND amount_currency = ? AND status = ? AND created_at >= ?", userId, currency, models.HoldStatusActive, monthStart).
+		Scan(&held).Error; err != nil {
+		return models.LimitUsage{}, appErrors.NewDatabaseError(err, "failed to get transaction limit usage")
+	}
+
+	usage.DailyMinor += held.DailyMinor
+	usage.DailyCount += held.DailyCount
+	usage.MonthlyMinor += held.MonthlyMinor
+	usage.MonthlyCount += held.MonthlyCount
+	return usage, nil
+}
+
+// Enforce rejects moving amount out of the user's accounts if it would exceed one of their
+// limits, with the limit that was hit in the error details. Run it in the transaction that
+// moves the money after locking the user, so concurrent transactions cannot both pass.
+func (r *limitRepository) Enforce(userId uint, amount money.Money, now time.Time) error {
+	limit, err := r.GetEffective(userId, amount.Currency)
+	if err != nil || limit == nil {
+		return err
+	}
+
+	usage, err := r.GetUsage(userId, amount.Currency, now)
+	if err != nil {
+		return err
+	}
+
+	breach := limit.Breach(amount, usage, now)
+	if breach == nil {
+		return nil
+	}
+
+	details := map[string]interface{}{
+		"limit":    breach.Limit,
+		"currency": amount.Currency,
+	}
+	if breach.IsAmount() {
+		details["allowed"] = money.New(breach.Allowed, amount.Currency)
+		details["used"] = money.New(breach.Used, amount.Currency)
+		details["requested"] = amount
+	} else {
+		details["allowed"] = breach.Allowed
+		details["used"] = breach.Used
+	}
+	if breach.ResetsAt != nil {
+		details["resets_at"] = breach.ResetsAt.Format(time.RFC3339)
+	}
+
+	return appErrors.NewUnprocessableEntityWithDetails(nil, limitMessage(breach, amount), details)
+}
+
+// Precheck checks a transaction against the user's limits before its job is queued. Limits
+// are enforced again by the ledger when the job runs, which is what keeps concurrent jobs
+// within them; checking them up front already lets the user know right away instead of
+// through a failed job.
+func (r *limitRepository) Precheck(userId uint, amount money.Money, now time.Time) error {
+	return r.Enforce(userId, amount, now)
+}
+
+func limitMessage(breach *models.LimitBreach, amount money.Money) string {
+	name := strings.ReplaceAll(breach.Limit, "_", " ")
+	switch {
+	case breach.Limit == models.LimitMaxSingle:
+		return fmt.Sprintf("%s exceeds the maximum of %s per transaction", amount, money.New(breach.Allowed, amount.Currency))
+	case breach.IsAmount():
+		remaining := money.New(breach.Allowed-breach.Used, amount.Currency)
+		return fmt.Sprintf("%s exceeds the %s limit of %s, %s remaining", amount, name, money.New(breach.Allowed, amount.Currency), remaining)
+	default:
+		return fmt.Sprintf("the %s limit of %d transactions has been reached", name, breach.Allowed)
+	}
+}
+
+// getUserRole returns the role of a user.
+func getUserRole(db *gorm.DB, userId uint) (string, error) {
+	var user models.User
+	if err := db.Select("id", "role").First(&user, userId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", appErrors.NewNotFound(err, fmt.Sprintf("user with id %d not found", userId))
+		}
+		return "", appErrors.NewDatabaseError(err, "failed to get user role")
+	}
+	return user.Role, nil
+}
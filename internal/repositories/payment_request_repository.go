@@ -0,0 +1,367 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PaymentRequestsIncoming = "incoming"
+	PaymentRequestsOutgoing = "outgoing"
+)
+
+type PaymentRequestRepository interface {
+	Create(request *models.PaymentRequest) error
+	GetByID(id uint) (*models.PaymentRequest, error)
+	GetByUserID(userId uint, direction, status string, limit, offset int) ([]models.PaymentRequest, error)
+	Accept(id uint, fromAccountId *uint, now time.Time) (*models.PaymentRequest, error)
+	ClaimUnsubmitted(before time.Time, limit int) ([]models.PaymentRequest, error)
+	MarkSubmitted(id uint, now time.Time) error
+	Close(id uint, status string, now time.Time) (*models.PaymentRequest, error)
+	ExpireDue(now time.Time, limit int) (int, error)
+	SettleFinished(now time.Time, limit int) ([]models.PaymentRequest, error)
+}
+
+type paymentRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRequestRepository(db *gorm.DB) PaymentRequestRepository {
+	return &paymentRequestRepository{db: db}
+}
+
+// Create stores a new request and audit-logs it in the same transaction.
+func (p *paymentRequestRepository) Create(request *models.PaymentRequest) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(request).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to create payment request")
+		}
+
+		details := fmt.Sprintf("user %d requested %s from user %d", request.RequesterId, request.Amount, request.PayerId)
+		return auditPaymentRequest(tx, request, "create", details)
+	})
+}
+
+func (p *paymentRequestRepository) GetByID(id uint) (*models.PaymentRequest, error) {
+	var request models.PaymentRequest
+	if err := p.db.Preload("Job").First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("payment request with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get payment request")
+	}
+	return &request, nil
+}
+
+// GetByUserID lists the requests a user sent (outgoing), received (incoming) or, without a
+// direction, both.
+func (p *paymentRequestRepository) GetByUserID(userId uint, direction, status string, limit, offset int) ([]models.PaymentRequest, error) {
+	query := p.db.Preload("Job")
+	switch direction {
+	case PaymentRequestsIncoming:
+		query = query.Where("payer_id = ?", userId)
+	case PaymentRequestsOutgoing:
+		query = query.Where("requester_id = ?", userId)
+	default:
+		query = query.Where("payer_id = ? OR requester_id = ?", userId, userId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []models.PaymentRequest
+	if err := query.Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&requests).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get payment requests")
+	}
+	return requests, nil
+}
+
+// Accept moves a pending request that has not expired to accepted, paid from fromAccountId or
+// the payer's default account. The transfer job is created and the acceptance audit-logged in
+// the same transaction; the caller enqueues the job and marks the request submitted.
+func (p *paymentRequestRepository) Accept(id uint, fromAccountId *uint, now time.Time) (*models.PaymentRequest, error) {
+	var request *models.PaymentRequest
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockPendingPaymentRequest(tx, id)
+		if err != nil {
+			return err
+		}
+		request = locked
+
+		// A request past its expiry that has not been swept yet can no longer be accepted.
+		if request.IsExpired(now) {
+			return appErrors.NewConflict(nil, fmt.Sprintf("payment request %d has expired", request.Id))
+		}
+
+		if err := NewLimitRepository(tx).Precheck(request.PayerId, request.Amount, now); err != nil {
+			return err
+		}
+
+		request.Attempts++
+		reference := request.JobReference()
+		job := models.NewJob(models.TransactionTypeTransfer, request.PayerId, &request.RequesterId, request.Amount)
+		job.FromAccountId = fromAccountId
+		job.Reference = &reference
+		if err := tx.Create(job).Error; err != nil {
+			return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to create job for payment request %d", request.Id))
+		}
+
+		request.Status = models.PaymentRequestStatusAccepted
+		request.FromAccountId = fromAccountId
+		request.JobId = &job.Id
+		request.Job = job
+		request.SubmittedAt = nil
+		request.FailureReason = ""
+		if err := tx.Model(request).Updates(map[string]interface{}{
+			"status":          request.Status,
+			"from_account_id": request.FromAccountId,
+			"job_id":          request.JobId,
+			"submitted_at":    nil,
+			"attempts":        request.Attempts,
+			"failure_reason":  request.FailureReason,
+		}).Error; err != nil {
+			return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to accept payment request %d", request.Id))
+		}
+
+		details := fmt.Sprintf("user %d accepted payment request %d of %s to user %d", request.PayerId, request.Id, request.Amount, request.RequesterId)
+		return auditPaymentRequest(tx, request, "accept", details)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ClaimUnsubmitted returns accepted requests whose transfer job was created but not confirmed
+// as enqueued since before, with their job, bumping updated_at so other instances leave them
+// alone.
+func (p *paymentRequestRepository) ClaimUnsubmitted(before time.Time, limit int) ([]models.PaymentRequest, error) {
+	var claimed []models.PaymentRequest
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Preload("Job").
+			Where("status = ? AND job_id IS NOT NULL AND submitted_at IS NULL AND updated_at < ?", models.PaymentRequestStatusAccepted, before).
+			Order("id").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim unsubmitted payment requests")
+		}
+
+		for i := range claimed {
+			if err := tx.Model(&claimed[i]).Update("updated_at", time.Now()).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update payment request %d", claimed[i].Id))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+func (p *paymentRequestRepository) MarkSubmitted(id uint, now time.Time) error {
+	if err := p.db.Model(&models.PaymentRequest{}).
+		Where("id = ? AND status = ? AND submitted_at IS NULL", id, models.PaymentRequestStatusAccepted).
+		Update("submitted_at", now).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to mark payment request %d as submitted", id))
+	}
+	return nil
+}
+
+// Close declines or cancels a pending request and audit-logs it in the same transaction.
+func (p *paymentRequestRepository) Close(id uint, status string, now time.Time) (*models.PaymentRequest, error) {
+	var request *models.PaymentRequest
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockPendingPaymentRequest(tx, id)
+		if err != nil {
+			return err
+		}
+		request = locked
+
+		if err := closePaymentRequest(tx, request, status, now); err != nil {
+			return err
+		}
+
+		switch status {
+		case models.PaymentRequestStatusDeclined:
+			details := fmt.Sprintf("user %d declined payment request %d of %s from user %d", request.PayerId, request.Id, request.Amount, request.RequesterId)
+			return auditPaymentRequest(tx, request, "decline", details)
+		case models.PaymentRequestStatusCancelled:
+			details := fmt.Sprintf("user %d cancelled payment request %d of %s to user %d", request.RequesterId, request.Id, request.Amount, request.PayerId)
+			return auditPaymentRequest(tx, request, "cancel", details)
+		default:
+			return appErrors.NewInternalServerError(fmt.Errorf("payment request %d cannot be closed as %s", request.Id, status))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ExpireDue expires pending requests whose expiry has passed and audit-logs each of them.
+// Requests are locked with SKIP LOCKED, so several instances can run this concurrently. It
+// returns the number of requests expired.
+func (p *paymentRequestRepository) ExpireDue(now time.Time, limit int) (int, error) {
+	var requests []models.PaymentRequest
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at <= ?", models.PaymentRequestStatusPending, now).
+			Order("expires_at, id").
+			Limit(limit).
+			Find(&requests).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim expired payment requests")
+		}
+
+		for i := range requests {
+			request := &requests[i]
+			if err := closePaymentRequest(tx, request, models.PaymentRequestStatusExpired, now); err != nil {
+				return err
+			}
+
+			details := fmt.Sprintf("payment request %d of %s from user %d to user %d expired", request.Id, request.Amount, request.PayerId, request.RequesterId)
+			if err := auditPaymentRequest(tx, request, "expire", details); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(requests), nil
+}
+
+// SettleFinished copies the outcome of finished transfer jobs onto accepted requests: a
+// completed transfer pays the request, a failed one puts it back to pending with the reason.
+func (p *paymentRequestRepository) SettleFinished(now time.Time, limit int) ([]models.PaymentRequest, error) {
+	var settled []models.PaymentRequest
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		var claimed []models.PaymentRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.PaymentRequestStatusAccepted).
+			Where("job_id IN (?)", tx.Model(&models.Job{}).
+				Select("id").
+				Where("status IN ?", []string{models.JobStatusCompleted, models.JobStatusFailed})).
+			Order("id").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to find finished payment requests")
+		}
+
+		for i := range claimed {
+			var job models.Job
+			if err := tx.First(&job, *claimed[i].JobId).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to load job %d", *claimed[i].JobId))
+			}
+			// A failed job requeued from the dead-letter store after the query above is
+			// running again; it is settled once it finishes.
+			if !job.IsFinished() {
+				continue
+			}
+
+			settled = append(settled, claimed[i])
+			request := &settled[len(settled)-1]
+			request.Job = &job
+
+			if job.Status == models.JobStatusFailed {
+				request.Status = models.PaymentRequestStatusPending
+				request.FailureReason = job.FailureReason
+				request.FromAccountId = nil
+				request.JobId = nil
+				request.SubmittedAt = nil
+				if err := tx.Model(request).Updates(map[string]interface{}{
+					"status":          request.Status,
+					"failure_reason":  request.FailureReason,
+					"from_account_id": nil,
+					"job_id":          nil,
+					"submitted_at":    nil,
+				}).Error; err != nil {
+					return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to settle payment request %d", request.Id))
+				}
+
+				details := fmt.Sprintf("transfer for payment request %d failed, the request is pending again: %s", request.Id, job.FailureReason)
+				if err := auditPaymentRequest(tx, request, "fail", details); err != nil {
+					return err
+				}
+				continue
+			}
+
+			request.TransactionId = job.TransactionId
+			if err := closePaymentRequest(tx, request, models.PaymentRequestStatusPaid, now); err != nil {
+				return err
+			}
+
+			details := fmt.Sprintf("user %d paid payment request %d of %s to user %d", request.PayerId, request.Id, request.Amount, request.RequesterId)
+			if err := auditPaymentRequest(tx, request, "pay", details); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return settled, nil
+}
+
+// lockPendingPaymentRequest row-locks a request for the rest of tx and checks that it is still
+// pending.
+func lockPendingPaymentRequest(tx *gorm.DB, id uint) (*models.PaymentRequest, error) {
+	var request models.PaymentRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("payment request with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to lock payment request")
+	}
+
+	if request.Status != models.PaymentRequestStatusPending {
+		return nil, appErrors.NewConflict(nil, fmt.Sprintf("payment request %d is already %s", request.Id, request.Status))
+	}
+
+	return &request, nil
+}
+
+func closePaymentRequest(tx *gorm.DB, request *models.PaymentRequest, status string, now time.Time) error {
+	request.Status = status
+	request.ClosedAt = &now
+
+	if err := tx.Model(request).Updates(map[string]interface{}{
+		"status":         request.Status,
+		"transaction_id": request.TransactionId,
+		"closed_at":      request.ClosedAt,
+	}).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update payment request %d", request.Id))
+	}
+
+	return nil
+}
+
+// auditPaymentRequest records a state change in the transaction that makes it.
+func auditPaymentRequest(tx *gorm.DB, request *models.PaymentRequest, action, details string) error {
+	log := models.NewAuditLog(models.PaymentRequestAuditEntity, request.Id, action, details)
+	if err := tx.Create(log).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create audit log")
+	}
+	return nil
+}
@@ -0,0 +1,434 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UnpaidInterest is the interest an account has accrued since it was last capitalized.
+type UnpaidInterest struct {
+	AmountMicros int64
+	Days         int64
+	Since        *time.Time
+}
+
+type SavingsRepository interface {
+	CreateProduct(product *models.SavingsProduct, rate *models.InterestRate) error
+	GetProductByID(id uint) (*models.SavingsProduct, error)
+	ListProducts(activeOnly bool, limit, offset int) ([]models.SavingsProduct, error)
+	DeactivateProduct(id uint) (*models.SavingsProduct, error)
+	SetRate(rate *models.InterestRate) error
+	ListRates(productId uint) ([]models.InterestRate, error)
+	GetRateOn(productId uint, day time.Time) (*models.InterestRate, error)
+	AccrueDue(now time.Time, limit int) (int, error)
+	CapitalizeDue(now time.Time, limit int) (int, error)
+	GetUnpaid(balanceId uint) (*UnpaidInterest, error)
+}
+
+type savingsRepository struct {
+	db *gorm.DB
+}
+
+func NewSavingsRepository(db *gorm.DB) SavingsRepository {
+	return &savingsRepository{db: db}
+}
+
+// CreateProduct creates a product together with its first rate.
+func (s *savingsRepository) CreateProduct(product *models.SavingsProduct, rate *models.InterestRate) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.SavingsProduct{}).Where("name = ?", product.Name).Count(&existing).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to check savings product name")
+		}
+		if existing > 0 {
+			return appErrors.NewConflict(nil, fmt.Sprintf("a savings product named %q already exists", product.Name))
+		}
+
+		if err := tx.Create(product).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to create savings product")
+		}
+
+		rate.ProductId = product.Id
+		if err := tx.Create(rate).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to create interest rate")
+		}
+		return nil
+	})
+}
+
+func (s *savingsRepository) GetProductByID(id uint) (*models.SavingsProduct, error) {
+	var product models.SavingsProduct
+	if err := s.db.First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("savings product with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get savings product")
+	}
+	return &product, nil
+}
+
+func (s *savingsRepository) ListProducts(activeOnly bool, limit, offset int) ([]models.SavingsProduct, error) {
+	query := s.db.Order("currency, name")
+	if activeOnly {
+		query = query.Where("active")
+	}
+
+	var products []models.SavingsProduct
+	if err := query.Limit(limit).Offset(offset).Find(&products).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get savings products")
+	}
+	return products, nil
+}
+
+// DeactivateProduct stops new accounts from being opened with a product. Accounts that
+// already have it keep earning its rate.
+func (s *savingsRepository) DeactivateProduct(id uint) (*models.SavingsProduct, error) {
+	product, err := s.GetProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !product.Active {
+		return product, nil
+	}
+
+	if err := s.db.Model(product).Update("active", false).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, fmt.Sprintf("failed to deactivate savings product %d", id))
+	}
+	product.Active = false
+	return product, nil
+}
+
+// SetRate schedules a product's rate from rate.EffectiveFrom, replacing a rate already set
+// for that day. Days that have been accrued keep the rate they were accrued at, so a rate
+// cannot take effect on or before any of them.
+func (s *savingsRepository) SetRate(rate *models.InterestRate) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var product models.SavingsProduct
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, rate.ProductId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return appErrors.NewNotFound(err, fmt.Sprintf("savings product with id %d not found", rate.ProductId))
+			}
+			return appErrors.NewDatabaseError(err, "failed to lock savings product")
+		}
+
+		var accrued int64
+		if err := tx.Model(&models.Balance{}).
+			Where("savings_product_id = ? AND interest_accrued_through >= ?", rate.ProductId, rate.EffectiveFrom).
+			Count(&accrued).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to check accrued interest")
+		}
+		if accrued > 0 {
+			return appErrors.NewConflict(nil, fmt.Sprintf("interest has already been accrued for %s, rates can only change from a later day", rate.EffectiveFrom.Format("2006-01-02")))
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "effective_from"}},
+			DoUpdates: clause.AssignmentColumns([]string{"annual_basis_points"}),
+		}).Create(rate).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to save interest rate")
+		}
+		return nil
+	})
+}
+
+// ListRates returns the rate history of a product, oldest first.
+func (s *savingsRepository) ListRates(productId uint) ([]models.InterestRate, error) {
+	var rates []models.InterestRate
+	if err := s.db.Where("product_id = ?", productId).
+		Order("effective_from").
+		Find(&rates).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get interest rates")
+	}
+	return rates, nil
+}
+
+// GetRateOn returns the rate of a product in effect on day, or nil if it had none yet.
+func (s *savingsRepository) GetRateOn(productId uint, day time.Time) (*models.InterestRate, error) {
+	var rates []models.InterestRate
+	if err := s.db.Where("product_id = ? AND effective_from <= ?", productId, day).
+		Order("effective_from DESC").
+		Limit(1).
+		Find(&rates).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get interest rate")
+	}
+
+	if len(rates) == 0 {
+		return nil, nil
+	}
+	return &rates[0], nil
+}
+
+// AccrueDue accrues interest on savings and overdraft accounts for every day up to yesterday
+// (UTC) they have not been accrued for yet, so accounts catch up on days missed while no
+// scheduler ran. Each day earns, or is charged, interest on the account's closing balance of
+// that day. Accounts are locked with SKIP LOCKED, so several instances can run this
+// concurrently. It returns the number of accounts accrued.
+func (s *savingsRepository) AccrueDue(now time.Time, limit int) (int, error) {
+	yesterday := models.UTCDay(now).AddDate(0, 0, -1)
+	var accounts []models.Balance
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(savings_product_id IS NOT NULL OR overdraft_basis_points > 0) AND interest_accrued_through < ?", yesterday).
+			Where("closed_at IS NULL OR interest_accrued_through + 1 <= closed_at").
+			Order("id").
+			Limit(limit).
+			Find(&accounts).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim savings accounts")
+		}
+
+		rates := make(map[uint][]models.InterestRate)
+		for i := range accounts {
+			account := &accounts[i]
+
+			var productRates []models.InterestRate
+			if account.SavingsProductId != nil {
+				var ok bool
+				if productRates, ok = rates[*account.SavingsProductId]; !ok {
+					// SetRate locks the product while it checks that nothing was accrued from
+					// the new rate's day on, so sharing the lock before reading the rates keeps
+					// a rate from being added underneath this accrual.
+					if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+						First(&models.SavingsProduct{}, *account.SavingsProductId).Error; err != nil {
+						return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to lock savings product %d", *account.SavingsProductId))
+					}
+
+					var err error
+					if productRates, err = NewSavingsRepository(tx).ListRates(*account.SavingsProductId); err != nil {
+						return err
+					}
+					rates[*account.SavingsProductId] = productRates
+				}
+			}
+
+			if err := accrue(tx, account, productRates, yesterday); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(accounts), nil
+}
+
+// CapitalizeDue settles the interest accounts accrued before the current UTC month, one
+// account at a time. Interest earned is credited to the account it was earned on and
+// overdraft interest is charged to it, or to the user's default account if it has been
+// closed since. It returns the number of accounts settled.
+func (s *savingsRepository) CapitalizeDue(now time.Time, limit int) (int, error) {
+	monthStart := models.NextCapitalization(now).AddDate(0, -1, 0)
+
+	var balanceIds []uint
+	if err := s.db.Model(&models.InterestAccrual{}).
+		Where("capitalized_at IS NULL AND date < ?", monthStart).
+		Distinct().
+		Order("balance_id").
+		Limit(limit).
+		Pluck("balance_id", &balanceIds).Error; err != nil {
+		return 0, appErrors.NewDatabaseError(err, "failed to find accrued interest")
+	}
+
+	paid := 0
+	for _, balanceId := range balanceIds {
+		capitalized, err := s.capitalize(balanceId, monthStart, now)
+		if err != nil {
+			return paid, err
+		}
+		if capitalized {
+			paid++
+		}
+	}
+
+	return paid, nil
+}
+
+// GetUnpaid sums the interest an account has accrued and not been paid yet.
+func (s *savingsRepository) GetUnpaid(balanceId uint) (*UnpaidInterest, error) {
+	var unpaid UnpaidInterest
+	if err := s.db.Model(&models.InterestAccrual{}).
+		Select("COALESCE(SUM(amount_micros), 0) AS amount_micros, COUNT(*) AS days, MIN(date) AS since").
+		Where("balance_id = ? AND capitalized_at IS NULL", balanceId).
+		Scan(&unpaid).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to sum accrued interest")
+	}
+	return &unpaid, nil
+}
+
+// capitalize settles an account's accruals from before monthStart in one interest or
+// overdraft interest transaction, for their net amount. Accruals being settled by another
+// instance are skipped. It reports whether anything was capitalized.
+func (s *savingsRepository) capitalize(balanceId uint, monthStart, now time.Time) (bool, error) {
+	capitalized := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		balances := NewBalancesRepository(tx)
+		account, err := balances.GetByID(balanceId)
+		if err != nil {
+			return err
+		}
+
+		// The user is locked first, as everywhere else, so the account cannot be closed
+		// while interest is paid into it.
+		if err := lockUser(tx, account.UserId); err != nil {
+			return err
+		}
+		if account, err = balances.GetByID(balanceId); err != nil {
+			return err
+		}
+
+		var accruals []models.InterestAccrual
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("balance_id = ? AND capitalized_at IS NULL AND date < ?", balanceId, monthStart).
+			Find(&accruals).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to lock accrued interest")
+		}
+		if len(accruals) == 0 {
+			return nil
+		}
+
+		var micros int64
+		ids := make([]uint, 0, len(accruals))
+		for _, accrual := range accruals {
+			micros += accrual.AmountMicros
+			ids = append(ids, accrual.Id)
+		}
+
+		var transaction *models.Transaction
+		ledger := NewLedgerRepository(tx)
+		switch {
+		case micros > 0 && models.InterestMinor(micros) > 0:
+			if !account.IsClosed() {
+				ledger = ledger.ToAccount(account.Id)
+			}
+			interest := money.New(models.InterestMinor(micros), account.Amount.Currency)
+			if transaction, err = ledger.Deposit(account.UserId, interest, models.TransactionTypeInterest); err != nil {
+				return err
+			}
+		case micros < 0 && models.InterestMinor(-micros) > 0:
+			if !account.IsClosed() {
+				ledger = ledger.FromAccount(account.Id)
+			}
+			interest := money.New(models.InterestMinor(-micros), account.Amount.Currency)
+			if transaction, err = ledger.Charge(account.UserId, interest, models.TransactionTypeOverdraftInterest); err != nil {
+				return err
+			}
+		}
+
+		var transactionId *uint
+		if transaction != nil {
+			transactionId = &transaction.Id
+		}
+
+		if err := tx.Model(&models.InterestAccrual{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"capitalized_at": now,
+				"transaction_id": transactionId,
+			}).Error; err != nil {
+			return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to capitalize interest of account %d", balanceId))
+		}
+
+		capitalized = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return capitalized, nil
+}
+
+// accrue records the interest a locked account earned, or was charged, on each day after the
+// last accrued one up to through, or up to the day it was closed. A positive closing balance
+// earns the savings product's rate of the day and an overdrawn one is charged the overdraft
+// rate; days that come to nothing get no accrual.
+func accrue(tx *gorm.DB, account *models.Balance, rates []models.InterestRate, through time.Time) error {
+	if account.ClosedAt != nil {
+		if closedDay := models.UTCDay(*account.ClosedAt); closedDay.Before(through) {
+			through = closedDay
+		}
+	}
+
+	var accruals []models.InterestAccrual
+	for day := models.UTCDay(*account.InterestAccruedThrough).AddDate(0, 0, 1); !day.After(through); day = day.AddDate(0, 0, 1) {
+		basisPoints := rateOn(rates, day)
+		if basisPoints == 0 && account.OverdraftBasisPoints == 0 {
+			continue
+		}
+
+		principal, err := closingBalance(tx, account.Id, day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+
+		micros := models.DailyInterestMicros(principal, basisPoints)
+		if principal < 0 {
+			basisPoints = account.OverdraftBasisPoints
+			micros = -models.DailyInterestMicros(-principal, basisPoints)
+		}
+		if micros == 0 {
+			continue
+		}
+
+		accruals = append(accruals, models.InterestAccrual{
+			BalanceId:         account.Id,
+			Date:              day,
+			PrincipalMinor:    principal,
+			AnnualBasisPoints: basisPoints,
+			AmountMicros:      micros,
+		})
+	}
+
+	if len(accruals) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&accruals).Error; err != nil {
+			return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to accrue interest on account %d", account.Id))
+		}
+	}
+
+	if err := tx.Model(&models.Balance{}).
+		Where("id = ?", account.Id).
+		Update("interest_accrued_through", through).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update accrual date of account %d", account.Id))
+	}
+	return nil
+}
+
+// rateOn returns the annual rate in effect on day from a product's rates, oldest first.
+func rateOn(rates []models.InterestRate, day time.Time) int64 {
+	var basisPoints int64
+	for _, rate := range rates {
+		if rate.EffectiveFrom.After(day) {
+			break
+		}
+		basisPoints = rate.AnnualBasisPoints
+	}
+	return basisPoints
+}
+
+// closingBalance returns an account's balance at the end of the day ending at dayEnd: its
+// latest snapshot before then, or zero if it had none.
+func closingBalance(tx *gorm.DB, balanceId uint, dayEnd time.Time) (int64, error) {
+	var snapshots []models.BalanceSnapshot
+	if err := tx.Where("balance_id = ? AND created_at < ?", balanceId, dayEnd).
+		Order("created_at DESC, id DESC").
+		Limit(1).
+		Find(&snapshots).Error; err != nil {
+		return 0, appErrors.NewDatabaseError(err, "failed to get closing balance")
+	}
+
+	if len(snapshots) == 0 {
+		return 0, nil
+	}
+	return snapshots[0].Amount.Minor, nil
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduledTransactionRepository interface {
+	Create(scheduled *models.ScheduledTransaction) error
+	GetByID(id uint) (*models.ScheduledTransaction, error)
+	GetByUserID(userId uint, status string, limit, offset int) ([]models.ScheduledTransaction, error)
+	Cancel(id uint) error
+	ClaimDue(now time.Time, limit int) ([]models.ScheduledTransaction, error)
+	ClaimStuck(before time.Time, limit int) ([]models.ScheduledTransaction, error)
+	MarkSubmitted(id uint) error
+}
+
+type scheduledTransactionRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledTransactionRepository(db *gorm.DB) ScheduledTransactionRepository {
+	return &scheduledTransactionRepository{db: db}
+}
+
+func (s *scheduledTransactionRepository) Create(scheduled *models.ScheduledTransaction) error {
+	if err := s.db.Create(scheduled).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create scheduled transaction")
+	}
+	return nil
+}
+
+func (s *scheduledTransactionRepository) GetByID(id uint) (*models.ScheduledTransaction, error) {
+	var scheduled models.ScheduledTransaction
+	if err := s.db.Preload("Job").First(&scheduled, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("scheduled transaction with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get scheduled transaction")
+	}
+	return &scheduled, nil
+}
+
+func (s *scheduledTransactionRepository) GetByUserID(userId uint, status string, limit, offset int) ([]models.ScheduledTransaction, error) {
+	query := s.db.Preload("Job").Where("user_id = ?", userId)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var scheduled []models.ScheduledTransaction
+	if err := query.Order("scheduled_at").
+		Limit(limit).Offset(offset).
+		Find(&scheduled).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get scheduled transactions")
+	}
+	return scheduled, nil
+}
+
+func (s *scheduledTransactionRepository) Cancel(id uint) error {
+	result := s.db.Model(&models.ScheduledTransaction{}).
+		Where("id = ? AND status = ?", id, models.ScheduledStatusScheduled).
+		Update("status", models.ScheduledStatusCancelled)
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to cancel scheduled transaction %d", id))
+	}
+
+	if result.RowsAffected == 0 {
+		return appErrors.NewConflict(nil, fmt.Sprintf("scheduled transaction %d can no longer be cancelled", id))
+	}
+
+	return nil
+}
+
+// ClaimDue creates a job for each due scheduled transaction and moves it to submitting, all in
+// one transaction. Rows are locked with SKIP LOCKED, so concurrent schedulers on other
+// instances never claim the same row.
+func (s *scheduledTransactionRepository) ClaimDue(now time.Time, limit int) ([]models.ScheduledTransaction, error) {
+	var claimed []models.ScheduledTransaction
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND scheduled_at <= ?", models.ScheduledStatusScheduled, now).
+			Order("scheduled_at, id").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim due scheduled transactions")
+		}
+
+		for i := range claimed {
+			scheduled := &claimed[i]
+			reference := scheduled.JobReference()
+
+			job := models.NewJob(scheduled.Type, scheduled.UserId, scheduled.ToUserId, scheduled.Amount)
+			job.FromAccountId = scheduled.FromAccountId
+			job.ToAccountId = scheduled.ToAccountId
+			job.Reference = &reference
+			if err := tx.Create(job).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to create job for scheduled transaction %d", scheduled.Id))
+			}
+
+			if err := tx.Model(scheduled).Updates(map[string]interface{}{
+				"status": models.ScheduledStatusSubmitting,
+				"job_id": job.Id,
+			}).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update scheduled transaction %d", scheduled.Id))
+			}
+
+			scheduled.Status = models.ScheduledStatusSubmitting
+			scheduled.JobId = &job.Id
+			scheduled.Job = job
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// ClaimStuck returns scheduled transactions whose job was created but not confirmed as
+// enqueued since before, e.g. because the instance that claimed them crashed. Their
+// updated_at is bumped so that other instances leave them alone while they are resubmitted.
+func (s *scheduledTransactionRepository) ClaimStuck(before time.Time, limit int) ([]models.ScheduledTransaction, error) {
+	var claimed []models.ScheduledTransaction
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND updated_at < ?", models.ScheduledStatusSubmitting, before).
+			Order("id").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim stuck scheduled transactions")
+		}
+
+		for i := range claimed {
+			if err := tx.Model(&claimed[i]).Update("updated_at", time.Now()).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update scheduled transaction %d", claimed[i].Id))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+func (s *scheduledTransactionRepository) MarkSubmitted(id uint) error {
+	if err := s.db.Model(&models.ScheduledTransaction{}).
+		Where("id = ? AND status = ?", id, models.ScheduledStatusSubmitting).
+		Update("status", models.ScheduledStatusSubmitted).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to mark scheduled transaction %d as submitted", id))
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StandingOrderRepository interface {
+	Create(order *models.StandingOrder) error
+	GetByID(id uint) (*models.StandingOrder, error)
+	GetByUserID(userId uint, limit, offset int) ([]models.StandingOrder, error)
+	GetOccurrences(orderId uint, limit, offset int) ([]models.StandingOrderOccurrence, error)
+	Pause(id uint) error
+	Resume(id uint, nextRunAt *time.Time) error
+	Cancel(id uint) error
+	Skip(id uint) (*models.StandingOrderOccurrence, error)
+	MaterializeDue(now time.Time, limit int) (int, error)
+	ClaimPendingOccurrences(now time.Time, limit int) ([]models.StandingOrderOccurrence, error)
+	ClaimStuckOccurrences(before time.Time, limit int) ([]models.StandingOrderOccurrence, error)
+	MarkOccurrenceSubmitted(id uint) error
+	SettleFinished(now time.Time, limit int) ([]models.StandingOrderOccurrence, error)
+}
+
+type standingOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewStandingOrderRepository(db *gorm.DB) StandingOrderRepository {
+	return &standingOrderRepository{db: db}
+}
+
+func (s *standingOrderRepository) Create(order *models.StandingOrder) error {
+	if err := s.db.Create(order).Error; err != nil {
+		return appErrors.NewDatabaseError(err, "failed to create standing order")
+	}
+	return nil
+}
+
+func (s *standingOrderRepository) GetByID(id uint) (*models.StandingOrder, error) {
+	var order models.StandingOrder
+	if err := s.db.First(&order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("standing order with id %d not found", id))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get standing order")
+	}
+	return &order, nil
+}
+
+func (s *standingOrderRepository) GetByUserID(userId uint, limit, offset int) ([]models.StandingOrder, error) {
+	var orders []models.StandingOrder
+	if err := s.db.Where("user_id = ?", userId).
+		Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&orders).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get standing orders")
+	}
+	return orders, nil
+}
+
+func (s *standingOrderRepository) GetOccurrences(orderId uint, limit, offset int) ([]models.StandingOrderOccurrence, error) {
+	var occurrences []models.StandingOrderOccurrence
+	if err := s.db.Preload("Job").
+		Where("standing_order_id = ?", orderId).
+		Order("sequence DESC, attempt DESC").
+		Limit(limit).Offset(offset).
+		Find(&occurrences).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get standing order occurrences")
+	}
+	return occurrences, nil
+}
+
+func (s *standingOrderRepository) Pause(id uint) error {
+	return s.transition(id, []string{models.StandingOrderStatusActive}, map[string]interface{}{
+		"status": models.StandingOrderStatusPaused,
+	})
+}
+
+// Resume reactivates a paused order from nextRunAt; occurrences missed while it was paused are
+// not made up. A nil nextRunAt means the order has nothing left to run and completes it.
+func (s *standingOrderRepository) Resume(id uint, nextRunAt *time.Time) error {
+	status := models.StandingOrderStatusActive
+	if nextRunAt == nil {
+		status = models.StandingOrderStatusCompleted
+	}
+
+	return s.transition(id, []string{models.StandingOrderStatusPaused}, map[string]interface{}{
+		"status":      status,
+		"next_run_at": nextRunAt,
+	})
+}
+
+func (s *standingOrderRepository) Cancel(id uint) error {
+	return s.transition(id, []string{models.StandingOrderStatusActive, models.StandingOrderStatusPaused}, map[string]interface{}{
+		"status":      models.StandingOrderStatusCancelled,
+		"next_run_at": nil,
+	})
+}
+
+// Skip records the next due occurrence as skipped and moves the order on to the one after it.
+func (s *standingOrderRepository) Skip(id uint) (*models.StandingOrderOccurrence, error) {
+	var occurrence *models.StandingOrderOccurrence
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var order models.StandingOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return appErrors.NewNotFound(err, fmt.Sprintf("standing order with id %d not found", id))
+			}
+			return appErrors.NewDatabaseError(err, "failed to lock standing order")
+		}
+
+		if order.Status != models.StandingOrderStatusActive || order.NextRunAt == nil {
+			return appErrors.NewConflict(nil, fmt.Sprintf("standing order %d has no upcoming occurrence to skip", id))
+		}
+
+		created, err := advance(tx, &order, models.OccurrenceStatusSkipped)
+		if err != nil {
+			return err
+		}
+		occurrence = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return occurrence, nil
+}
+
+// MaterializeDue turns the due date of up to limit due active orders into a pending occurrence
+// and moves each order on to its next due date, which may still be due. Orders are locked with
+// SKIP LOCKED, so several instances can run this concurrently. It returns the number of
+// occurrences created.
+func (s *standingOrderRepository) MaterializeDue(now time.Time, limit int) (int, error) {
+	var orders []models.StandingOrder
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", models.StandingOrderStatusActive, now).
+			Order("next_run_at, id").
+			Limit(limit).
+			Find(&orders).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim due standing orders")
+		}
+
+		for i := range orders {
+			if _, err := advance(tx, &orders[i], models.OccurrenceStatusPending); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(orders), nil
+}
+
+// ClaimPendingOccurrences creates a job for each pending occurrence whose run time has come
+// and moves it to submitting, in one transaction.
+func (s *standingOrderRepository) ClaimPendingOccurrences(now time.Time, limit int) ([]models.StandingOrderOccurrence, error) {
+	var claimed []models.StandingOrderOccurrence
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", models.OccurrenceStatusPending, now).
+			Order("run_at, id").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim pending occurrences")
+		}
+
+		for i := range claimed {
+			occurrence := &claimed[i]
+
+			var order models.StandingOrder
+			if err := tx.First(&order, occurrence.StandingOrderId).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to load standing order %d", occurrence.StandingOrderId))
+			}
+
+			reference := occurrence.JobReference()
+			job := models.NewJob(order.Type, order.UserId, order.ToUserId, order.Amount)
+			job.FromAccountId = order.FromAccountId
+			job.ToAccountId = order.ToAccountId
+			job.Reference = &reference
+			if err := tx.Create(job).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to create job for occurrence %d", occurrence.Id))
+			}
+
+			if err := tx.Model(occurrence).Updates(map[string]interface{}{
+				"status": models.OccurrenceStatusSubmitting,
+				"job_id": job.Id,
+			}).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update occurrence %d", occurrence.Id))
+			}
+
+			occurrence.Status = models.OccurrenceStatusSubmitting
+			occurrence.JobId = &job.Id
+			occurrence.Job = job
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// ClaimStuckOccurrences returns occurrences whose job was created but not confirmed as
+// enqueued since before, bumping updated_at so other instances leave them alone.
+func (s *standingOrderRepository) ClaimStuckOccurrences(before time.Time, limit int) ([]models.StandingOrderOccurrence, error) {
+	var claimed []models.StandingOrderOccurrence
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND updated_at < ?", models.OccurrenceStatusSubmitting, before).
+			Order("id").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to claim stuck occurrences")
+		}
+
+		for i := range claimed {
+			if err := tx.Model(&claimed[i]).Update("updated_at", time.Now()).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to update occurrence %d", claimed[i].Id))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+func (s *standingOrderRepository) MarkOccurrenceSubmitted(id uint) error {
+	if err := s.db.Model(&models.StandingOrderOccurrence{}).
+		Where("id = ? AND status = ?", id, models.OccurrenceStatusSubmitting).
+		Update("status", models.OccurrenceStatusSubmitted).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to mark occurrence %d as submitted", id))
+	}
+	return nil
+}
+
+// SettleFinished copies the outcome of finished jobs onto their occurrences. A failed
+// occurrence of an order that retries failures gets a new pending attempt a day later.
+func (s *standingOrderRepository) SettleFinished(now time.Time, limit int) ([]models.StandingOrderOccurrence, error) {
+	var settled []models.StandingOrderOccurrence
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var claimed []models.StandingOrderOccurrence
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.OccurrenceStatusSubmitted).
+			Where("job_id IN (?)", tx.Model(&models.Job{}).
+				Select("id").
+				Where("status IN ?", []string{models.JobStatusCompleted, models.JobStatusFailed})).
+			Order("id").
+			Limit(limit).
+			Find(&claimed).Error; err != nil {
+			return appErrors.NewDatabaseError(err, "failed to find finished occurrences")
+		}
+
+		for i := range claimed {
+			var job models.Job
+			if err := tx.First(&job, *claimed[i].JobId).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to load job %d", *claimed[i].JobId))
+			}
+			// A failed job requeued from the dead-letter store after the query above is
+			// running again; it is settled once it finishes.
+			if !job.IsFinished() {
+				continue
+			}
+
+			settled = append(settled, claimed[i])
+			occurrence := &settled[len(settled)-1]
+			occurrence.Job = &job
+			occurrence.TransactionId = job.TransactionId
+			occurrence.FailureReason = job.FailureReason
+			occurrence.Status = models.OccurrenceStatusCompleted
+			if job.Status == models.JobStatusFailed {
+				occurrence.Status = models.OccurrenceStatusFailed
+			}
+
+			if err := tx.Model(occurrence).Updates(map[string]interface{}{
+				"status":         occurrence.Status,
+				"transaction_id": occurrence.TransactionId,
+				"failure_reason": occurrence.FailureReason,
+			}).Error; err != nil {
+				return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to settle occurrence %d", occurrence.Id))
+			}
+
+			if occurrence.Status == models.OccurrenceStatusFailed {
+				if err := scheduleRetry(tx, occurrence, now); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return settled, nil
+}
+
+func (s *standingOrderRepository) transition(id uint, from []string, updates map[string]interface{}) error {
+	result := s.db.Model(&models.StandingOrder{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to update standing order %d", id))
+	}
+
+	if result.RowsAffected == 0 {
+		return appErrors.NewConflict(nil, fmt.Sprintf("standing order %d cannot move to %v from its current state", id, updates["status"]))
+	}
+
+	return nil
+}
+
+// advance records the order's current due date as an occurrence with the given status and
+// moves the order to its next due date, completing it when there is none.
+func advance(tx *gorm.DB, order *models.StandingOrder, status string) (*models.StandingOrderOccurrence, error) {
+	dueAt := *order.NextRunAt
+	order.OccurrenceCount++
+
+	occurrence := &models.StandingOrderOccurrence{
+		StandingOrderId: order.Id,
+		Sequence:        order.OccurrenceCount,
+		Attempt:         1,
+		DueAt:           dueAt,
+		RunAt:           dueAt,
+		Status:          status,
+	}
+	if err := tx.Create(occurrence).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, fmt.Sprintf("failed to record occurrence of standing order %d", order.Id))
+	}
+
+	next, err := order.NextOccurrence(dueAt)
+	if err != nil {
+		return nil, appErrors.NewInternalServerError(err)
+	}
+
+	order.NextRunAt = next
+	if next == nil {
+		order.Status = models.StandingOrderStatusCompleted
+	}
+
+	if err := tx.Model(order).Updates(map[string]interface{}{
+		"occurrence_count": order.OccurrenceCount,
+		"next_run_at":      order.NextRunAt,
+		"status":           order.Status,
+	}).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, fmt.Sprintf("failed to advance standing order %d", order.Id))
+	}
+
+	return occurrence, nil
+}
+
+func scheduleRetry(tx *gorm.DB, failed *models.StandingOrderOccurrence, now time.Time) error {
+	var order models.StandingOrder
+	if err := tx.First(&order, failed.StandingOrderId).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to load standing order %d", failed.StandingOrderId))
+	}
+
+	if !order.ShouldRetry(failed.Attempt) {
+		return nil
+	}
+
+	retry := &models.StandingOrderOccurrence{
+		StandingOrderId: failed.StandingOrderId,
+		Sequence:        failed.Sequence,
+		Attempt:         failed.Attempt + 1,
+		DueAt:           failed.DueAt,
+		RunAt:           now.Add(models.StandingOrderRetryDelay),
+		Status:          models.OccurrenceStatusPending,
+	}
+	if err := tx.Create(retry).Error; err != nil {
+		return appErrors.NewDatabaseError(err, fmt.Sprintf("failed to schedule retry of standing order %d", order.Id))
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/yusuffugurlu/go-project/internal/models"
 	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
 	"gorm.io/gorm"
@@ -12,10 +13,21 @@ import (
 type TransactionRepository interface {
 	Create(transaction *models.Transaction) error
 	GetByID(id uint) (*models.Transaction, error)
+	GetByJobID(jobID uint) (*models.Transaction, error)
 	GetByUserID(userID uint) ([]*models.Transaction, error)
 	GetHistoryByUserID(userID uint, limit, offset int) ([]*models.Transaction, error)
+	GetHistoryByAccountID(accountID uint, limit, offset int) ([]*models.Transaction, error)
 	GetAll(limit, offset int) ([]*models.Transaction, error)
-	Transfer(fromUserID, toUserID uint, amount float64) error
+}
+
+// jobTransactionIndex is the unique index that allows one transaction per job.
+const jobTransactionIndex = "idx_transactions_job_id"
+
+// IsJobAlreadyRecorded reports whether err is the unique violation of writing a second
+// transaction for a job, i.e. an earlier run of the job already moved the money.
+func IsJobAlreadyRecorded(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == jobTransactionIndex
 }
 
 type transactionRepository struct {
@@ -37,7 +49,7 @@ func (r *transactionRepository) Create(transaction *models.Transaction) error {
 
 func (r *transactionRepository) GetByID(id uint) (*models.Transaction, error) {
 	var transaction models.Transaction
-	if err := r.db.Preload("FromUser.Balance").Preload("ToUser.Balance").First(&transaction, id).Error; err != nil {
+	if err := r.db.Preload("FromUser.Balances").Preload("ToUser.Balances").First(&transaction, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, appErrors.NewNotFound(err, fmt.Sprintf("transaction with id %d not found", id))
 		}
@@ -46,10 +58,21 @@ func (r *transactionRepository) GetByID(id uint) (*models.Transaction, error) {
 	return &transaction, nil
 }
 
+func (r *transactionRepository) GetByJobID(jobID uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := r.db.Where("job_id = ?", jobID).First(&transaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("transaction for job %d not found", jobID))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to get transaction by job")
+	}
+	return &transaction, nil
+}
+
 func (r *transactionRepository) GetByUserID(userID uint) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
 	if err := r.db.Where("from_user_id = ? OR to_user_id = ?", userID, userID).
-		Preload("FromUser.Balance").Preload("ToUser.Balance").
+		Preload("FromUser.Balances").Preload("ToUser.Balances").
 		Order("created_at DESC").
 		Find(&transactions).Error; err != nil {
 		return nil, appErrors.NewDatabaseError(err, "failed to get user transactions")
@@ -60,7 +83,7 @@ func (r *transactionRepository) GetByUserID(userID uint) ([]*models.Transaction,
 func (r *transactionRepository) GetHistoryByUserID(userID uint, limit, offset int) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
 	if err := r.db.Where("from_user_id = ? OR to_user_id = ?", userID, userID).
-		Preload("FromUser.Balance").Preload("ToUser.Balance").
+		Preload("FromUser.Balances").Preload("ToUser.Balances").
 		Order("created_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&transactions).Error; err != nil {
@@ -69,9 +92,21 @@ func (r *transactionRepository) GetHistoryByUserID(userID uint, limit, offset in
 	return transactions, nil
 }
 
+func (r *transactionRepository) GetHistoryByAccountID(accountID uint, limit, offset int) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	if err := r.db.Where("from_account_id = ? OR to_account_id = ?", accountID, accountID).
+		Preload("FromUser.Balances").Preload("ToUser.Balances").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&transactions).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to get account transaction history")
+	}
+	return transactions, nil
+}
+
 func (r *transactionRepository) GetAll(limit, offset int) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	query := r.db.Preload("FromUser.Balance").Preload("ToUser.Balance").Order("created_at DESC")
+	query := r.db.Preload("FromUser.Balances").Preload("ToUser.Balances").Order("created_at DESC")
 
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -85,56 +120,3 @@ func (r *transactionRepository) GetAll(limit, offset int) ([]*models.Transaction
 	}
 	return transactions, nil
 }
-
-func (r *transactionRepository) Transfer(fromUserID, toUserID uint, amount float64) error {
-	if amount <= 0 {
-		return appErrors.NewBadRequest(nil, "amount must be positive")
-	}
-
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		var fromBalance, toBalance models.Balance
-
-		if err := tx.Where("user_id = ?", fromUserID).First(&fromBalance).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return appErrors.NewNotFound(err, fmt.Sprintf("balance not found for user %d", fromUserID))
-			}
-			return appErrors.NewDatabaseError(err, "failed to get sender balance")
-		}
-
-		if err := tx.Where("user_id = ?", toUserID).First(&toBalance).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return appErrors.NewNotFound(err, fmt.Sprintf("balance not found for user %d", toUserID))
-			}
-			return appErrors.NewDatabaseError(err, "failed to get receiver balance")
-		}
-
-		if fromBalance.Amount < amount {
-			return appErrors.NewConflict(nil, fmt.Sprintf("insufficient funds: requested %.2f, available %.2f", amount, fromBalance.Amount))
-		}
-
-		fromBalance.Amount -= amount
-		toBalance.Amount += amount
-
-		if err := tx.Save(&fromBalance).Error; err != nil {
-			return appErrors.NewDatabaseError(err, "failed to update sender balance")
-		}
-
-		if err := tx.Save(&toBalance).Error; err != nil {
-			return appErrors.NewDatabaseError(err, "failed to update receiver balance")
-		}
-
-		transaction := &models.Transaction{
-			FromUserId: &fromUserID,
-			ToUserId:   &toUserID,
-			Amount:     amount,
-			Type:       "transfer",
-			Status:     "completed",
-		}
-
-		if err := tx.Create(transaction).Error; err != nil {
-			return appErrors.NewDatabaseError(err, "failed to create transaction record")
-		}
-
-		return nil
-	})
-}
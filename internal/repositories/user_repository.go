@@ -14,6 +14,9 @@ type UserRepository interface {
 	Create(user *models.User) error
 	GetById(id int) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
+	GetByHandle(handle string) (*models.User, error)
+	FindByUsername(username string, limit int) ([]models.User, error)
+	SetHandle(id uint, handle *string) error
 	GetAll() ([]models.User, error)
 	Update(user *models.User) error
 	Delete(id int) error
@@ -50,7 +53,7 @@ func (u *userRepository) Delete(id int) error {
 func (u *userRepository) GetAll() ([]models.User, error) {
 	var users []models.User
 
-	if err := u.db.Preload("Balance").Find(&users).Error; err != nil {
+	if err := u.db.Preload("Balances").Find(&users).Error; err != nil {
 		return nil, appErrors.NewDatabaseError(err, "failed to fetch all users")
 	}
 
@@ -68,9 +71,42 @@ func (u *userRepository) GetByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+func (u *userRepository) GetByHandle(handle string) (*models.User, error) {
+	var user models.User
+	if err := u.db.Where("handle = ?", handle).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, appErrors.NewNotFound(err, fmt.Sprintf("user with handle @%s not found", handle))
+		}
+		return nil, appErrors.NewDatabaseError(err, "failed to fetch user by handle")
+	}
+	return &user, nil
+}
+
+// FindByUsername returns up to limit users with the given username, which is not unique.
+func (u *userRepository) FindByUsername(username string, limit int) ([]models.User, error) {
+	var users []models.User
+	if err := u.db.Where("username = ?", username).Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return nil, appErrors.NewDatabaseError(err, "failed to fetch users by username")
+	}
+	return users, nil
+}
+
+func (u *userRepository) SetHandle(id uint, handle *string) error {
+	result := u.db.Model(&models.User{}).Where("id = ?", id).Update("handle", handle)
+	if result.Error != nil {
+		return appErrors.NewDatabaseError(result.Error, fmt.Sprintf("failed to set handle of user with id %d", id))
+	}
+
+	if result.RowsAffected == 0 {
+		return appErrors.NewNotFound(nil, fmt.Sprintf("user with id %d not found", id))
+	}
+
+	return nil
+}
+
 func (u *userRepository) GetById(id int) (*models.User, error) {
 	var user models.User
-	if err := u.db.Preload("Balance").First(&user, id).Error; err != nil {
+	if err := u.db.Preload("Balances").First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, appErrors.NewNotFound(err, fmt.Sprintf("user with id %d not found", id))
 		}
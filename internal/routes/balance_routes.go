@@ -11,6 +11,18 @@ func RegisterBalanceRoutes(e *echo.Group) {
 
 	route := e.Group("/balances")
 
+	route.GET("/", controller.GetAll, middleware.RoleBasedAuth("user"))
+	route.POST("/", controller.Open, middleware.RoleBasedAuth("user"))
 	route.GET("/current", controller.GetCurrentBalance, middleware.RoleBasedAuth("user"))
 	route.GET("/historical", controller.GetHistoricalBalances, middleware.RoleBasedAuth("user"))
+	route.GET("/as-of", controller.GetBalanceAsOf, middleware.RoleBasedAuth("user"))
+	route.GET("/:id", controller.GetAccount, middleware.RoleBasedAuth("user"))
+	route.POST("/:id/default", controller.SetDefault, middleware.RoleBasedAuth("user"))
+	route.DELETE("/:id", controller.Close, middleware.RoleBasedAuth("user"))
+
+	admin := e.Group("/admin/accounts")
+
+	admin.Use(middleware.RoleBasedAuth("admin"))
+
+	admin.PUT("/:id/overdraft", controller.SetOverdraft)
 }
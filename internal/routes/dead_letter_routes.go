@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/process"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterDeadLetterRoutes(e *echo.Group, workerPool *process.WorkerPool) {
+	service := services.NewDeadLetterService(
+		repositories.NewDeadLetterRepository(database.Db),
+		repositories.NewJobRepository(database.Db),
+		services.NewAuditLogService(repositories.NewAuditLogRepository(database.Db)),
+		workerPool,
+	)
+	controller := controllers.NewDeadLetterController(service)
+
+	route := e.Group("/admin/dead-letters")
+
+	route.Use(middleware.RoleBasedAuth("admin"))
+
+	route.GET("/", controller.GetAll)
+	route.GET("/:id", controller.GetByID)
+	route.POST("/:id/requeue", controller.Requeue)
+	route.DELETE("/:id", controller.Discard)
+}
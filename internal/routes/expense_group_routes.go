@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterExpenseGroupRoutes(e *echo.Group, cfg *config.Config, cacheService *cache.CacheService) {
+	logService := services.NewAuditLogService(repositories.NewAuditLogRepository(database.Db))
+	userService := services.NewUserServiceWithCache(repositories.NewUserRepository(database.Db), logService, cacheService)
+	service := services.NewExpenseGroupService(repositories.NewExpenseGroupRepository(database.Db), services.NewTransactionServiceWithCache(cacheService), userService, logService)
+	controller := controllers.NewExpenseGroupController(service)
+
+	route := e.Group("/groups")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	idempotency := middleware.NewIdempotencyMiddleware(cacheService, cfg.IdempotencyKeyTTL).Handle()
+
+	route.POST("/", controller.Create)
+	route.GET("/", controller.GetAll)
+	route.GET("/:id", controller.GetByID)
+	route.POST("/:id/members", controller.AddMember)
+	route.DELETE("/:id/members/:userId", controller.RemoveMember)
+	route.POST("/:id/expenses", controller.AddExpense, idempotency)
+	route.GET("/:id/expenses", controller.GetExpenses)
+	route.DELETE("/:id/expenses/:expenseId", controller.DeleteExpense)
+	route.GET("/:id/balances", controller.GetBalances)
+	route.GET("/:id/settlements", controller.GetSettlements)
+	route.POST("/:id/settle", controller.SettleUp, idempotency)
+}
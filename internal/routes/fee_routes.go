@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterFeeRoutes(e *echo.Group) {
+	service := services.NewFeeService(repositories.NewFeeRepository(database.Db))
+	controller := controllers.NewFeeController(service)
+
+	route := e.Group("/fees")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	route.POST("/quote", controller.Quote)
+
+	admin := e.Group("/admin/fees")
+
+	admin.Use(middleware.RoleBasedAuth("admin"))
+
+	admin.GET("/rules", controller.GetRules)
+	admin.POST("/rules", controller.CreateRule)
+	admin.DELETE("/rules/:id", controller.DeactivateRule)
+	admin.GET("/totals", controller.GetTotals)
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/fx"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterFxRoutes(e *echo.Group, cfg *config.Config, cacheService *cache.CacheService, rateProvider fx.RateProvider) {
+	service := services.NewFxService(
+		rateProvider,
+		repositories.NewFxRepository(database.Db),
+		repositories.NewExchangeRateRepository(database.Db),
+		cacheService,
+		cfg.FxQuoteTTL,
+	)
+	controller := controllers.NewFxController(service)
+
+	idempotency := middleware.NewIdempotencyMiddleware(cacheService, cfg.IdempotencyKeyTTL).Handle()
+
+	route := e.Group("/fx")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	route.GET("/rates", controller.GetRate)
+	route.POST("/quotes", controller.CreateQuote)
+	route.GET("/quotes/:id", controller.GetQuote)
+	route.POST("/conversions", controller.Convert, idempotency)
+	route.GET("/conversions", controller.GetConversions)
+	route.GET("/conversions/:id", controller.GetConversion)
+
+	admin := e.Group("/admin/fx/rates")
+
+	admin.Use(middleware.RoleBasedAuth("admin"))
+
+	admin.GET("/", controller.GetPublishedRates)
+	admin.POST("/", controller.PublishRate)
+}
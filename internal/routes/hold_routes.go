@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterHoldRoutes(e *echo.Group, cfg *config.Config, cacheService *cache.CacheService) {
+	service := services.NewHoldService(repositories.NewHoldRepository(database.Db), cfg.HoldDefaultTTL, cfg.HoldMaxTTL)
+	controller := controllers.NewHoldController(service)
+
+	route := e.Group("/holds")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	idempotency := middleware.NewIdempotencyMiddleware(cacheService, cfg.IdempotencyKeyTTL).Handle()
+
+	route.POST("/", controller.Create, idempotency)
+	route.GET("/", controller.GetAll)
+	route.GET("/:id", controller.GetByID)
+	route.POST("/:id/capture", controller.Capture, idempotency)
+	route.POST("/:id/release", controller.Release)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterJobRoutes(e *echo.Group, jobService services.JobService) {
+	controller := controllers.NewJobController(jobService)
+
+	route := e.Group("/jobs")
+
+	route.GET("/:id", controller.GetByID, middleware.RoleBasedAuth("user"))
+}
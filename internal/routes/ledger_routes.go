@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterLedgerRoutes(e *echo.Group) {
+	service := services.NewLedgerService(repositories.NewLedgerRepository(database.Db))
+	controller := controllers.NewLedgerController(service)
+
+	route := e.Group("/ledger")
+
+	route.GET("/postings", controller.GetPostings, middleware.RoleBasedAuth("user"))
+	route.GET("/check", controller.CheckInvariants, middleware.RoleBasedAuth("admin"))
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterLimitRoutes(e *echo.Group) {
+	service := services.NewLimitService(repositories.NewLimitRepository(database.Db), repositories.NewUserRepository(database.Db))
+	controller := controllers.NewLimitController(service)
+
+	route := e.Group("/limits")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	route.GET("/", controller.GetHeadroom)
+
+	admin := e.Group("/admin/limits")
+
+	admin.Use(middleware.RoleBasedAuth("admin"))
+
+	admin.GET("/", controller.GetLimits)
+	admin.PUT("/roles/:role", controller.SetRoleLimit)
+	admin.GET("/users/:id", controller.GetUserHeadroom)
+	admin.PUT("/users/:id", controller.SetUserLimit)
+	admin.DELETE("/users/:id", controller.DeleteUserLimit)
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterPaymentRequestRoutes(e *echo.Group, cfg *config.Config, cacheService *cache.CacheService, jobService services.JobService) {
+	logService := services.NewAuditLogService(repositories.NewAuditLogRepository(database.Db))
+	userService := services.NewUserServiceWithCache(repositories.NewUserRepository(database.Db), logService, cacheService)
+	service := services.NewPaymentRequestService(
+		repositories.NewPaymentRequestRepository(database.Db),
+		jobService,
+		userService,
+		logService,
+		cfg.PaymentRequestTTL,
+		cfg.PaymentRequestMaxTTL,
+	)
+	controller := controllers.NewPaymentRequestController(service)
+
+	route := e.Group("/payment-requests")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	idempotency := middleware.NewIdempotencyMiddleware(cacheService, cfg.IdempotencyKeyTTL).Handle()
+
+	route.POST("/", controller.Create, idempotency)
+	route.GET("/", controller.GetAll)
+	route.GET("/:id", controller.GetByID)
+	route.GET("/:id/history", controller.GetHistory)
+	route.POST("/:id/accept", controller.Accept, idempotency)
+	route.POST("/:id/decline", controller.Decline)
+	route.DELETE("/:id", controller.Cancel)
+}
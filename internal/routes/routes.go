@@ -3,10 +3,18 @@ package routes
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/yusuffugurlu/go-project/config"
 	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/fx"
+	"github.com/yusuffugurlu/go-project/internal/process"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
 )
 
-func InitRoutes(e *echo.Echo, cacheService *cache.CacheService) {
+func InitRoutes(e *echo.Echo, cfg *config.Config, cacheService *cache.CacheService, workerPool *process.WorkerPool, rateProvider fx.RateProvider) {
+	jobService := services.NewJobService(repositories.NewJobRepository(database.Db), repositories.NewLimitRepository(database.Db), workerPool)
+
 	v1 := e.Group("/api/v1")
 
 	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
@@ -15,5 +23,18 @@ func InitRoutes(e *echo.Echo, cacheService *cache.CacheService) {
 	RegisterUserRoutes(v1, cacheService)
 	RegisterAuthRoutes(v1)
 	RegisterBalanceRoutes(v1)
-	RegisterTransactionRoutes(v1, cacheService)
+	RegisterTransactionRoutes(v1, cfg, cacheService, jobService)
+	RegisterScheduledTransactionRoutes(v1, cfg, cacheService)
+	RegisterStandingOrderRoutes(v1, cfg, cacheService)
+	RegisterHoldRoutes(v1, cfg, cacheService)
+	RegisterPaymentRequestRoutes(v1, cfg, cacheService, jobService)
+	RegisterExpenseGroupRoutes(v1, cfg, cacheService)
+	RegisterFxRoutes(v1, cfg, cacheService, rateProvider)
+	RegisterFeeRoutes(v1)
+	RegisterLimitRoutes(v1)
+	RegisterSavingsRoutes(v1)
+	RegisterLedgerRoutes(v1)
+	RegisterJobRoutes(v1, jobService)
+	RegisterDeadLetterRoutes(v1, workerPool)
+	RegisterWorkerPoolRoutes(v1, workerPool)
 }
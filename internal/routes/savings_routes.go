@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterSavingsRoutes(e *echo.Group) {
+	service := services.NewSavingsService(repositories.NewSavingsRepository(database.Db), repositories.NewBalancesRepository(database.Db))
+	controller := controllers.NewSavingsController(service)
+
+	route := e.Group("/savings")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	route.GET("/products", controller.GetProducts)
+	route.GET("/accounts/:id/interest", controller.GetAccruedInterest)
+
+	admin := e.Group("/admin/savings")
+
+	admin.Use(middleware.RoleBasedAuth("admin"))
+
+	admin.GET("/products", controller.GetAllProducts)
+	admin.POST("/products", controller.CreateProduct)
+	admin.DELETE("/products/:id", controller.DeactivateProduct)
+	admin.GET("/products/:id/rates", controller.GetRates)
+	admin.POST("/products/:id/rates", controller.SetRate)
+}
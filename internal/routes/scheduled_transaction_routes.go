@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterScheduledTransactionRoutes(e *echo.Group, cfg *config.Config, cacheService *cache.CacheService) {
+	service := services.NewScheduledTransactionService(repositories.NewScheduledTransactionRepository(database.Db))
+	controller := controllers.NewScheduledTransactionController(service)
+
+	route := e.Group("/transactions/scheduled")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	idempotency := middleware.NewIdempotencyMiddleware(cacheService, cfg.IdempotencyKeyTTL).Handle()
+
+	route.POST("/", controller.Schedule, idempotency)
+	route.GET("/", controller.GetAll)
+	route.GET("/:id", controller.GetByID)
+	route.DELETE("/:id", controller.Cancel)
+}
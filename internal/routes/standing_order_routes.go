@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterStandingOrderRoutes(e *echo.Group, cfg *config.Config, cacheService *cache.CacheService) {
+	service := services.NewStandingOrderService(repositories.NewStandingOrderRepository(database.Db))
+	controller := controllers.NewStandingOrderController(service)
+
+	route := e.Group("/standing-orders")
+
+	route.Use(middleware.RoleBasedAuth("user"))
+
+	idempotency := middleware.NewIdempotencyMiddleware(cacheService, cfg.IdempotencyKeyTTL).Handle()
+
+	route.POST("/", controller.Create, idempotency)
+	route.GET("/", controller.GetAll)
+	route.GET("/:id", controller.GetByID)
+	route.GET("/:id/occurrences", controller.GetOccurrences)
+	route.POST("/:id/pause", controller.Pause)
+	route.POST("/:id/resume", controller.Resume)
+	route.POST("/:id/skip", controller.Skip)
+	route.DELETE("/:id", controller.Cancel)
+}
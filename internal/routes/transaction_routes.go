@@ -4,15 +4,20 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config"
 	"github.com/yusuffugurlu/go-project/internal/cache"
 	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/database"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
 	"github.com/yusuffugurlu/go-project/internal/services"
 	"github.com/yusuffugurlu/go-project/pkg/middleware"
 )
 
-func RegisterTransactionRoutes(e *echo.Group, cacheService *cache.CacheService) {
+func RegisterTransactionRoutes(e *echo.Group, cfg *config.Config, cacheService *cache.CacheService, jobService services.JobService) {
 	service := services.NewTransactionServiceWithCache(cacheService)
-	controller := controllers.NewTransactionControllerWithService(service)
+	logService := services.NewAuditLogService(repositories.NewAuditLogRepository(database.Db))
+	userService := services.NewUserServiceWithCache(repositories.NewUserRepository(database.Db), logService, cacheService)
+	controller := controllers.NewTransactionControllerWithService(service, jobService, userService)
 	route := e.Group("/transactions")
 
 	cacheConfig := middleware.CacheConfig{
@@ -22,13 +27,18 @@ func RegisterTransactionRoutes(e *echo.Group, cacheService *cache.CacheService)
 	cacheMiddleware := middleware.NewCacheMiddleware(cacheService, cacheConfig)
 	route.Use(cacheMiddleware.Cache())
 
+	idempotency := middleware.NewIdempotencyMiddleware(cacheService, cfg.IdempotencyKeyTTL).Handle()
+
 	// route.POST("/deposit", controller.Deposit)
-	route.POST("/withdraw", controller.Withdraw)
+	route.POST("/withdraw", controller.Withdraw, idempotency)
 
-	route.POST("/debit", controller.Debit, middleware.RoleBasedAuth("user"))
-	route.POST("/transfer", controller.Transfer, middleware.RoleBasedAuth("user"))
+	route.POST("/debit", controller.Debit, middleware.RoleBasedAuth("user"), idempotency)
+	route.POST("/transfer", controller.Transfer, middleware.RoleBasedAuth("user"), idempotency)
+	route.GET("/recipient", controller.PreviewRecipient, middleware.RoleBasedAuth("user"))
 	route.GET("/history", controller.GetHistory, middleware.RoleBasedAuth("user"))
 	route.GET("/:id", controller.GetByID, middleware.RoleBasedAuth("user"))
+	route.POST("/:id/refund", controller.Reverse, middleware.RoleBasedAuth("user"), idempotency)
 
 	route.GET("/all", controller.GetAllTransactions, middleware.RoleBasedAuth("admin"))
+	route.POST("/:id/reverse", controller.Reverse, middleware.RoleBasedAuth("admin"), idempotency)
 }
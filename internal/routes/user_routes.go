@@ -33,6 +33,7 @@ func RegisterUserRoutes(e *echo.Group, cacheService *cache.CacheService) {
 	route.GET("/", controller.GetAllUsers)
 	route.GET("/:id", controller.GetUserById)
 	route.POST("/create", controller.CreateUser)
+	route.PUT("/me/handle", controller.SetHandle, middleware.RoleBasedAuth("user"))
 	route.PUT("/:id", controller.UpdateUser)
 	route.DELETE("/:id", controller.DeleteUser)
 }
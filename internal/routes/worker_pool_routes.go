@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/yusuffugurlu/go-project/internal/controllers"
+	"github.com/yusuffugurlu/go-project/internal/process"
+	"github.com/yusuffugurlu/go-project/internal/services"
+	"github.com/yusuffugurlu/go-project/pkg/middleware"
+)
+
+func RegisterWorkerPoolRoutes(e *echo.Group, workerPool *process.WorkerPool) {
+	controller := controllers.NewWorkerPoolController(services.NewWorkerPoolService(workerPool))
+
+	route := e.Group("/admin/workers")
+
+	route.Use(middleware.RoleBasedAuth("admin"))
+
+	route.GET("/", controller.GetStatus)
+	route.PUT("/", controller.Update)
+}
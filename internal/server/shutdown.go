@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+)
+
+type ShutdownStep struct {
+	Name string
+	Stop func(ctx context.Context) error
+}
+
+// WaitForShutdown blocks until SIGINT or SIGTERM and then runs the steps in order, sharing
+// one deadline. A failing step is logged and does not prevent the following ones.
+func WaitForShutdown(timeout time.Duration, steps ...ShutdownStep) {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	<-quit
+	logger.Log.Info("Shutdown signal received, initiating graceful shutdown...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, step := range steps {
+		logger.Log.Infof("Stopping %s...", step.Name)
+		if err := step.Stop(ctx); err != nil {
+			logger.Log.Errorf("Failed to stop %s gracefully: %v", step.Name, err)
+			continue
+		}
+		logger.Log.Infof("%s stopped.", step.Name)
+	}
+
+	logger.Log.Info("Shutdown complete.")
+}
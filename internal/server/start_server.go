@@ -1,11 +1,7 @@
 package server
 
 import (
-	"context"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
 	echoPrometheus "github.com/globocom/echo-prometheus"
 	"github.com/labstack/echo/v4"
@@ -33,20 +29,4 @@ func StartServer(e *echo.Echo) {
 			}
 		}
 	}()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-
-	<-quit
-	logger.Log.Info("Shutdown signal received, initiating graceful shutdown...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Second)
-	defer cancel()
-
-	logger.Log.Info("Attempting to shut down the server gracefully...")
-	if err := e.Shutdown(ctx); err != nil {
-		logger.Log.Errorf("Server forced to shutdown: %v", err)
-	} else {
-		logger.Log.Info("Server gracefully stopped.")
-	}
-}
\ No newline at end of file
+}
@@ -9,6 +9,7 @@ type AuditLogService interface {
 	GetAllAuditLogs() ([]models.AuditLog, error)
 	CreateAuditLog(entity_id int, entity_type string, action string, details string) error
 	GetAuditLogsByEntityType(entityType string) ([]models.AuditLog, error)
+	GetAuditLogsByEntity(entityType string, entityId uint) ([]models.AuditLog, error)
 	DeleteAuditLog(id int) error
 }
 
@@ -47,6 +48,15 @@ func (a *auditLogService) GetAuditLogsByEntityType(entityType string) ([]models.
 	return logs, nil
 }
 
+func (a *auditLogService) GetAuditLogsByEntity(entityType string, entityId uint) ([]models.AuditLog, error) {
+	logs, err := a.auditLogRepo.GetByEntity(entityType, entityId)
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
 func (a *auditLogService) DeleteAuditLog(id int) error {
 	if err := a.auditLogRepo.Delete(id); err != nil {
 		return err
@@ -1,52 +1,370 @@
 package services
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/yusuffugurlu/go-project/internal/database"
 	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
 	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+const (
+	dateLayout         = "2006-01-02"
+	maxHistoryBuckets  = 1000
+	historyDefaultDays = 30
 )
 
+// historyBucketLengths approximates the bucket length of each interval to bound a query's size.
+var historyBucketLengths = map[string]time.Duration{
+	repositories.SnapshotIntervalDay:   24 * time.Hour,
+	repositories.SnapshotIntervalWeek:  7 * 24 * time.Hour,
+	repositories.SnapshotIntervalMonth: 28 * 24 * time.Hour,
+}
+
 type BalanceService interface {
-	GetUserBalance(userID uint) (*dtos.BalanceResponse, error)
-	GetHistoricalBalances(userID uint) ([]dtos.HistoricalBalanceResponse, error)
+	GetUserBalance(userID uint, currency string, accountID *uint) (*dtos.BalanceResponse, error)
+	ListUserBalances(userID uint) ([]dtos.BalanceResponse, error)
+	GetAccount(id uint, userID uint) (*dtos.BalanceResponse, error)
+	OpenBalance(userID uint, req dtos.OpenBalanceRequest) (*dtos.BalanceResponse, error)
+	SetDefaultAccount(id uint, userID uint) (*dtos.BalanceResponse, error)
+	CloseAccount(id uint, userID uint) (*dtos.BalanceResponse, error)
+	SetOverdraft(id uint, req dtos.OverdraftRequest) (*dtos.BalanceResponse, error)
+	GetHistoricalBalances(userID uint, currency string, accountID *uint, from, to, interval string) ([]dtos.HistoricalBalanceResponse, error)
+	GetBalanceAsOf(userID uint, currency string, accountID *uint, date string) (*dtos.HistoricalBalanceResponse, error)
 }
 
 type balanceService struct {
-	balanceRepo repositories.BalancesRepository
+	balanceRepo  repositories.BalancesRepository
+	snapshotRepo repositories.BalanceSnapshotRepository
+	savingsRepo  repositories.SavingsRepository
 }
 
 func NewBalanceService() BalanceService {
 	return &balanceService{
-		balanceRepo: repositories.NewBalancesRepository(database.Db),
+		balanceRepo:  repositories.NewBalancesRepository(database.Db),
+		snapshotRepo: repositories.NewBalanceSnapshotRepository(database.Db),
+		savingsRepo:  repositories.NewSavingsRepository(database.Db),
 	}
 }
 
-func (b *balanceService) GetUserBalance(userID uint) (*dtos.BalanceResponse, error) {
-	balance, err := b.balanceRepo.GetByUserId(userID)
+// GetUserBalance returns one of the user's accounts, or their default account in currency (the
+// default currency if empty) if no account is given.
+func (b *balanceService) GetUserBalance(userID uint, currency string, accountID *uint) (*dtos.BalanceResponse, error) {
+	balance, err := b.resolveAccount(userID, currency, accountID)
 	if err != nil {
 		return nil, err
 	}
 
-	response := &dtos.BalanceResponse{
-		Amount: balance.Amount,
+	return toBalanceResponse(balance), nil
+}
+
+func (b *balanceService) ListUserBalances(userID uint) ([]dtos.BalanceResponse, error) {
+	balances, err := b.balanceRepo.ListByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.BalanceResponse, 0, len(balances))
+	for i := range balances {
+		response = append(response, *toBalanceResponse(&balances[i]))
 	}
 
 	return response, nil
 }
 
-func (b *balanceService) GetHistoricalBalances(userID uint) ([]dtos.HistoricalBalanceResponse, error) {
-	historicalBalances, err := b.balanceRepo.GetHistoricalBalances(userID)
+func (b *balanceService) GetAccount(id uint, userID uint) (*dtos.BalanceResponse, error) {
+	balance, err := b.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBalanceResponse(balance), nil
+}
+
+// OpenBalance opens a named account, or the default account of the currency if no name is given.
+func (b *balanceService) OpenBalance(userID uint, req dtos.OpenBalanceRequest) (*dtos.BalanceResponse, error) {
+	currency := currencyOrDefault(req.Currency)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		if req.ProductID != nil {
+			return nil, appErrors.NewBadRequest(nil, "a savings account needs a name")
+		}
+
+		balance, err := b.balanceRepo.Open(userID, currency)
+		if err != nil {
+			return nil, err
+		}
+		return toBalanceResponse(balance), nil
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = models.AccountKindChecking
+		if req.ProductID != nil {
+			kind = models.AccountKindSavings
+		}
+	}
+
+	balance := &models.Balance{
+		UserId: userID,
+		Name:   name,
+		Kind:   kind,
+		Amount: money.Zero(currency),
+	}
+
+	if req.ProductID != nil {
+		if kind != models.AccountKindSavings {
+			return nil, appErrors.NewBadRequest(nil, "only savings accounts can have a savings product")
+		}
+
+		product, err := b.savingsRepo.GetProductByID(*req.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if !product.Active {
+			return nil, appErrors.NewConflict(nil, fmt.Sprintf("savings product %d is no longer offered", product.Id))
+		}
+		if product.Currency != currency {
+			return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("savings product %d is for %s accounts", product.Id, product.Currency))
+		}
+
+		// Interest accrues from the day the account is opened.
+		accruedThrough := models.UTCDay(time.Now()).AddDate(0, 0, -1)
+		balance.SavingsProductId = &product.Id
+		balance.InterestAccruedThrough = &accruedThrough
+	}
+
+	if err := b.balanceRepo.OpenAccount(balance); err != nil {
+		return nil, err
+	}
+
+	return toBalanceResponse(balance), nil
+}
+
+// SetDefaultAccount makes one of the user's accounts the default account of its currency,
+// which receives incoming transfers.
+func (b *balanceService) SetDefaultAccount(id uint, userID uint) (*dtos.BalanceResponse, error) {
+	if _, err := b.getOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	balance, err := b.balanceRepo.SetDefault(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBalanceResponse(balance), nil
+}
+
+// CloseAccount closes one of the user's accounts. Only empty accounts can be closed.
+func (b *balanceService) CloseAccount(id uint, userID uint) (*dtos.BalanceResponse, error) {
+	if _, err := b.getOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	balance, err := b.balanceRepo.Close(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBalanceResponse(balance), nil
+}
+
+// SetOverdraft sets the credit line of any user's account. It is meant for admins.
+func (b *balanceService) SetOverdraft(id uint, req dtos.OverdraftRequest) (*dtos.BalanceResponse, error) {
+	account, err := b.balanceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := parseAmountField(req.Limit, account.Amount.Currency, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := b.balanceRepo.SetOverdraft(id, limit, req.AnnualBasisPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBalanceResponse(balance), nil
+}
+
+// GetHistoricalBalances returns the closing balance of every day, week or month between from
+// and to. Dates without a time cover the whole day.
+func (b *balanceService) GetHistoricalBalances(userID uint, currency string, accountID *uint, from, to, interval string) ([]dtos.HistoricalBalanceResponse, error) {
+	if interval == "" {
+		interval = repositories.SnapshotIntervalDay
+	}
+	bucketLength, ok := historyBucketLengths[interval]
+	if !ok {
+		return nil, appErrors.NewBadRequest(nil, "interval must be one of day, week or month")
+	}
+
+	toTime := time.Now()
+	if to != "" {
+		parsed, err := parseHistoryTime(to, true)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, "invalid to date, use YYYY-MM-DD or RFC 3339")
+		}
+		toTime = parsed
+	}
+
+	fromTime := toTime.AddDate(0, 0, -historyDefaultDays)
+	if from != "" {
+		parsed, err := parseHistoryTime(from, false)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, "invalid from date, use YYYY-MM-DD or RFC 3339")
+		}
+		fromTime = parsed
+	}
+
+	if fromTime.After(toTime) {
+		return nil, appErrors.NewBadRequest(nil, "from must not be after to")
+	}
+	if toTime.Sub(fromTime)/bucketLength > maxHistoryBuckets {
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("range is too large, at most %d %ss can be requested", maxHistoryBuckets, interval))
+	}
+
+	balance, err := b.resolveAccount(userID, currency, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := b.snapshotRepo.GetSeries(balance, fromTime, toTime, interval)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []dtos.HistoricalBalanceResponse
-	for _, balance := range historicalBalances {
+	response := make([]dtos.HistoricalBalanceResponse, 0, len(points))
+	for _, point := range points {
 		response = append(response, dtos.HistoricalBalanceResponse{
-			Date:   balance.Date.Format("2006-01-02T15:04:05Z07:00"),
-			Amount: balance.Amount,
+			Date:   point.Bucket.Format(time.RFC3339),
+			Amount: point.Amount,
 		})
 	}
 
 	return response, nil
 }
+
+// GetBalanceAsOf returns the balance at the given time, or at the end of the given day.
+func (b *balanceService) GetBalanceAsOf(userID uint, currency string, accountID *uint, date string) (*dtos.HistoricalBalanceResponse, error) {
+	if date == "" {
+		return nil, appErrors.NewBadRequest(nil, "date is required")
+	}
+
+	at, err := parseHistoryTime(date, true)
+	if err != nil {
+		return nil, appErrors.NewBadRequest(err, "invalid date, use YYYY-MM-DD or RFC 3339")
+	}
+
+	balance, err := b.resolveAccount(userID, currency, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.HistoricalBalanceResponse{
+		Date:   at.Format(time.RFC3339),
+		Amount: money.Zero(balance.Amount.Currency),
+	}
+
+	snapshot, err := b.snapshotRepo.GetAsOf(balance.Id, at)
+	if err != nil {
+		if appErrors.GetStatusCode(err) == appErrors.ErrCodeNotFound {
+			return response, nil
+		}
+		return nil, err
+	}
+
+	response.Amount = snapshot.Amount
+	return response, nil
+}
+
+// resolveAccount returns the given account if it belongs to the user, and the user's default
+// account in currency otherwise.
+func (b *balanceService) resolveAccount(userID uint, currency string, accountID *uint) (*models.Balance, error) {
+	if accountID != nil {
+		return b.getOwned(*accountID, userID)
+	}
+	return b.balanceRepo.GetByUserId(userID, currencyOrDefault(currency))
+}
+
+// getOwned returns an account of the user. Other users' accounts are reported as not found.
+func (b *balanceService) getOwned(id uint, userID uint) (*models.Balance, error) {
+	balance, err := b.balanceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance.UserId != userID {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("account with id %d not found", id))
+	}
+
+	return balance, nil
+}
+
+// requireSupportedCurrency rejects amounts in currencies accounts cannot be held in, before
+// any work is queued for them.
+func requireSupportedCurrency(amount money.Money) error {
+	if !money.Supported(amount.Currency) {
+		return appErrors.NewBadRequest(nil, fmt.Sprintf("currency %s is not supported, use one of %s", amount.Currency, strings.Join(money.SupportedCurrencies(), ", ")))
+	}
+	return nil
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return money.DefaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+func toBalanceResponse(balance *models.Balance) *dtos.BalanceResponse {
+	response := &dtos.BalanceResponse{
+		ID:        balance.Id,
+		Name:      balance.Name,
+		Kind:      balance.Kind,
+		Default:   balance.IsDefault,
+		Status:    balance.Status(),
+		Currency:  balance.Amount.Currency,
+		Amount:    balance.Amount,
+		Available: balance.Available(),
+		Ledger:    balance.Amount,
+		Held:      balance.Held(),
+		ProductID: balance.SavingsProductId,
+	}
+
+	if balance.OverdraftLimitMinor > 0 || balance.OverdraftUsed().IsPositive() {
+		response.Credit = &dtos.CreditResponse{
+			Limit:             balance.OverdraftLimit(),
+			Used:              balance.OverdraftUsed(),
+			Remaining:         balance.OverdraftRemaining(),
+			AnnualBasisPoints: balance.OverdraftBasisPoints,
+		}
+	}
+
+	return response
+}
+
+// parseHistoryTime accepts RFC 3339 timestamps and plain dates. A plain date means the start
+// of that day (UTC), or its last instant if endOfDay is set.
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
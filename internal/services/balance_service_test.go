@@ -0,0 +1,200 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+type stubBalancesRepository struct {
+	repositories.BalancesRepository
+	balance *models.Balance
+}
+
+func (s *stubBalancesRepository) GetByUserId(userId uint, currency string) (*models.Balance, error) {
+	return s.balance, nil
+}
+
+type stubSnapshotRepository struct {
+	snapshot *models.BalanceSnapshot
+	points   []repositories.BalancePoint
+
+	asOf     time.Time
+	from, to time.Time
+	interval string
+}
+
+func (s *stubSnapshotRepository) GetAsOf(balanceId uint, at time.Time) (*models.BalanceSnapshot, error) {
+	s.asOf = at
+	if s.snapshot == nil {
+		return nil, appErrors.NewNotFound(nil, "no balance recorded at that time")
+	}
+	return s.snapshot, nil
+}
+
+func (s *stubSnapshotRepository) GetSeries(balance *models.Balance, from, to time.Time, interval string) ([]repositories.BalancePoint, error) {
+	s.from, s.to, s.interval = from, to, interval
+	return s.points, nil
+}
+
+func newHistoryService(snapshots *stubSnapshotRepository) *balanceService {
+	return &balanceService{
+		balanceRepo:  &stubBalancesRepository{balance: &models.Balance{Id: 1, UserId: 7, Amount: money.New(0, "EUR")}},
+		snapshotRepo: snapshots,
+	}
+}
+
+func TestGetHistoricalBalancesValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		from, to     string
+		interval     string
+		wantErr      bool
+		wantFrom     time.Time
+		wantTo       time.Time
+		wantInterval string
+	}{
+		{
+			name: "dates cover whole days", from: "2024-01-01", to: "2024-01-31",
+			wantFrom:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+			wantInterval: repositories.SnapshotIntervalDay,
+		},
+		{
+			name: "timestamps are kept", from: "2024-01-01T10:00:00Z", to: "2024-01-02T09:30:00Z", interval: "week",
+			wantFrom:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			wantTo:       time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC),
+			wantInterval: repositories.SnapshotIntervalWeek,
+		},
+		{
+			name: "from defaults to 30 days before to", to: "2024-03-31T00:00:00Z", interval: "month",
+			wantFrom:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:       time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+			wantInterval: repositories.SnapshotIntervalMonth,
+		},
+		{
+			name: "single instant", from: "2024-01-01T00:00:00Z", to: "2024-01-01T00:00:00Z",
+			wantFrom:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantInterval: repositories.SnapshotIntervalDay,
+		},
+		{name: "unknown interval", from: "2024-01-01", to: "2024-01-02", interval: "hour", wantErr: true},
+		{name: "bad from", from: "01/01/2024", to: "2024-01-02", wantErr: true},
+		{name: "bad to", from: "2024-01-01", to: "tomorrow", wantErr: true},
+		{name: "from after to", from: "2024-02-01", to: "2024-01-01", wantErr: true},
+		{name: "too many days", from: "2020-01-01", to: "2023-01-01", wantErr: true},
+		{name: "many weeks within bound", from: "2020-01-01", to: "2023-01-01", interval: "week",
+			wantFrom:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:       time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+			wantInterval: repositories.SnapshotIntervalWeek,
+		},
+		{name: "too many months", from: "1900-01-01", to: "2024-01-01", interval: "month", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshots := &stubSnapshotRepository{}
+			_, err := newHistoryService(snapshots).GetHistoricalBalances(7, "", nil, tt.from, tt.to, tt.interval)
+			if tt.wantErr {
+				if appErrors.GetStatusCode(err) != appErrors.ErrCodeBadRequest {
+					t.Fatalf("GetHistoricalBalances() error = %v, want bad request", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetHistoricalBalances() unexpected error: %v", err)
+			}
+			if !snapshots.from.Equal(tt.wantFrom) || !snapshots.to.Equal(tt.wantTo) || snapshots.interval != tt.wantInterval {
+				t.Errorf("GetSeries called with (%s, %s, %q), want (%s, %s, %q)",
+					snapshots.from, snapshots.to, snapshots.interval, tt.wantFrom, tt.wantTo, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestGetHistoricalBalancesSeries(t *testing.T) {
+	snapshots := &stubSnapshotRepository{points: []repositories.BalancePoint{
+		{Bucket: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Amount: money.New(0, "EUR")},
+		{Bucket: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Amount: money.New(1250, "EUR")},
+		{Bucket: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Amount: money.New(-300, "EUR")},
+	}}
+
+	got, err := newHistoryService(snapshots).GetHistoricalBalances(7, "EUR", nil, "2024-01-01", "2024-01-03", "day")
+	if err != nil {
+		t.Fatalf("GetHistoricalBalances() unexpected error: %v", err)
+	}
+
+	want := []struct {
+		date   string
+		amount money.Money
+	}{
+		{date: "2024-01-01T00:00:00Z", amount: money.New(0, "EUR")},
+		{date: "2024-01-02T00:00:00Z", amount: money.New(1250, "EUR")},
+		{date: "2024-01-03T00:00:00Z", amount: money.New(-300, "EUR")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d points, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Date != want[i].date || got[i].Amount != want[i].amount {
+			t.Errorf("point %d = (%s, %s), want (%s, %s)", i, got[i].Date, got[i].Amount, want[i].date, want[i].amount)
+		}
+	}
+}
+
+func TestGetBalanceAsOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		date       string
+		snapshot   *models.BalanceSnapshot
+		wantErr    bool
+		wantAt     time.Time
+		wantAmount money.Money
+	}{
+		{
+			name: "end of plain date", date: "2024-05-10",
+			snapshot:   &models.BalanceSnapshot{Amount: money.New(4200, "EUR")},
+			wantAt:     time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+			wantAmount: money.New(4200, "EUR"),
+		},
+		{
+			name: "exact timestamp", date: "2024-05-10T12:00:00+02:00",
+			snapshot:   &models.BalanceSnapshot{Amount: money.New(-50, "EUR")},
+			wantAt:     time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC),
+			wantAmount: money.New(-50, "EUR"),
+		},
+		{
+			name: "before first snapshot", date: "2020-01-01",
+			wantAt:     time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+			wantAmount: money.New(0, "EUR"),
+		},
+		{name: "missing date", date: "", wantErr: true},
+		{name: "bad date", date: "10.05.2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshots := &stubSnapshotRepository{snapshot: tt.snapshot}
+			got, err := newHistoryService(snapshots).GetBalanceAsOf(7, "EUR", nil, tt.date)
+			if tt.wantErr {
+				if appErrors.GetStatusCode(err) != appErrors.ErrCodeBadRequest {
+					t.Fatalf("GetBalanceAsOf() error = %v, want bad request", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBalanceAsOf() unexpected error: %v", err)
+			}
+			if !snapshots.asOf.Equal(tt.wantAt) {
+				t.Errorf("GetAsOf called at %s, want %s", snapshots.asOf, tt.wantAt)
+			}
+			if got.Amount != tt.wantAmount {
+				t.Errorf("GetBalanceAsOf() amount = %s, want %s", got.Amount, tt.wantAmount)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/process"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type DeadLetterService interface {
+	GetDeadLetters(limit, offset int) ([]dtos.DeadLetterResponse, error)
+	GetDeadLetter(id uint) (*dtos.DeadLetterResponse, error)
+	Requeue(id uint) (*dtos.JobResponse, error)
+	Discard(id uint) error
+}
+
+type deadLetterService struct {
+	deadLetterRepo repositories.DeadLetterRepository
+	jobRepo        repositories.JobRepository
+	logService     AuditLogService
+	workerPool     *process.WorkerPool
+}
+
+func NewDeadLetterService(deadLetterRepo repositories.DeadLetterRepository, jobRepo repositories.JobRepository, logService AuditLogService, workerPool *process.WorkerPool) DeadLetterService {
+	return &deadLetterService{
+		deadLetterRepo: deadLetterRepo,
+		jobRepo:        jobRepo,
+		logService:     logService,
+		workerPool:     workerPool,
+	}
+}
+
+func (d *deadLetterService) GetDeadLetters(limit, offset int) ([]dtos.DeadLetterResponse, error) {
+	deadLetters, err := d.deadLetterRepo.GetAll(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.DeadLetterResponse, 0, len(deadLetters))
+	for i := range deadLetters {
+		response = append(response, *toDeadLetterResponse(&deadLetters[i]))
+	}
+
+	return response, nil
+}
+
+func (d *deadLetterService) GetDeadLetter(id uint) (*dtos.DeadLetterResponse, error) {
+	deadLetter, err := d.deadLetterRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDeadLetterResponse(deadLetter), nil
+}
+
+func (d *deadLetterService) Requeue(id uint) (*dtos.JobResponse, error) {
+	deadLetter, err := d.deadLetterRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadLetter.JobId == nil {
+		return nil, appErrors.NewUnprocessableEntity(nil, "dead letter is not a decodable job and can only be discarded")
+	}
+	jobId := *deadLetter.JobId
+
+	var job process.Transaction
+	if err := json.Unmarshal([]byte(deadLetter.Payload), &job); err != nil {
+		return nil, appErrors.NewInternalServerError(err)
+	}
+
+	if err := d.jobRepo.MarkRequeued(jobId); err != nil {
+		return nil, err
+	}
+
+	if err := d.workerPool.SubmitJob(job); err != nil {
+		if markErr := d.jobRepo.MarkFailed(jobId, deadLetter.Error); markErr != nil {
+			logger.Log.Errorf("Failed to restore job %d after requeue failure: %v", jobId, markErr)
+		}
+		return nil, err
+	}
+
+	if err := d.deadLetterRepo.Delete(id); err != nil {
+		return nil, err
+	}
+
+	// The job is already back on the queue, so a failure here must not fail the request.
+	if err := d.logService.CreateAuditLog(int(jobId), "job", "requeue", "dead-lettered job requeued"); err != nil {
+		logger.Log.Errorf("Failed to audit requeue of job %d: %v", jobId, err)
+	}
+
+	requeued, err := d.jobRepo.GetByID(jobId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toJobResponse(requeued), nil
+}
+
+func (d *deadLetterService) Discard(id uint) error {
+	deadLetter, err := d.deadLetterRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := d.deadLetterRepo.Delete(id); err != nil {
+		return err
+	}
+
+	if deadLetter.JobId == nil {
+		return d.logService.CreateAuditLog(int(deadLetter.Id), "dead_letter", "discard", "undecodable message discarded")
+	}
+	return d.logService.CreateAuditLog(int(*deadLetter.JobId), "job", "discard", "dead-lettered job discarded")
+}
+
+func toDeadLetterResponse(deadLetter *models.DeadLetter) *dtos.DeadLetterResponse {
+	response := &dtos.DeadLetterResponse{
+		ID:        deadLetter.Id,
+		JobID:     deadLetter.JobId,
+		Error:     deadLetter.Error,
+		Attempts:  deadLetter.Attempts,
+		CreatedAt: deadLetter.CreatedAt.Format(time.RFC3339),
+	}
+
+	if deadLetter.Job != nil {
+		response.Job = toJobResponse(deadLetter.Job)
+	}
+
+	return response
+}
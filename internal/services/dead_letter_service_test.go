@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type stubDeadLetterRepository struct {
+	repositories.DeadLetterRepository
+	deadLetter *models.DeadLetter
+	deleted    bool
+}
+
+func (s *stubDeadLetterRepository) GetByID(id uint) (*models.DeadLetter, error) {
+	return s.deadLetter, nil
+}
+
+func (s *stubDeadLetterRepository) Delete(id uint) error {
+	s.deleted = true
+	return nil
+}
+
+type stubJobRepository struct {
+	repositories.JobRepository
+	requeueErr error
+	failed     bool
+}
+
+func (s *stubJobRepository) MarkRequeued(id uint) error {
+	return s.requeueErr
+}
+
+func (s *stubJobRepository) MarkFailed(id uint, reason string) error {
+	s.failed = true
+	return nil
+}
+
+// A job whose owner already settled it as failed must stay in the dead-letter store; the
+// worker pool is nil, so submitting it would panic.
+func TestRequeueSettledJob(t *testing.T) {
+	jobId := uint(42)
+	deadLetters := &stubDeadLetterRepository{deadLetter: &models.DeadLetter{
+		Id:      7,
+		JobId:   &jobId,
+		Payload: `{"job_id":42,"amount":{"value":"10.00","currency":"EUR"},"user_id":1,"to_user_id":2,"type":"transfer"}`,
+		Error:   "insufficient funds",
+	}}
+	jobs := &stubJobRepository{requeueErr: appErrors.NewConflict(nil, "job 42 was already settled as failed by its payment request")}
+
+	service := NewDeadLetterService(deadLetters, jobs, nil, nil)
+	if _, err := service.Requeue(7); appErrors.GetStatusCode(err) != appErrors.ErrCodeConflict {
+		t.Fatalf("Requeue() error = %v, want conflict", err)
+	}
+	if deadLetters.deleted {
+		t.Error("Requeue() deleted the dead letter of a refused job")
+	}
+	if jobs.failed {
+		t.Error("Requeue() touched the status of a refused job")
+	}
+}
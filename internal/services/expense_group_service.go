@@ -0,0 +1,463 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+type ExpenseGroupService interface {
+	Create(userID uint, req dtos.ExpenseGroupRequest) (*dtos.ExpenseGroupResponse, error)
+	GetForUser(id uint, userID uint) (*dtos.ExpenseGroupResponse, error)
+	ListForUser(userID uint, limit, offset int) ([]dtos.ExpenseGroupResponse, error)
+	AddMember(id uint, userID uint, req dtos.GroupMemberRequest) (*dtos.ExpenseGroupResponse, error)
+	RemoveMember(id uint, userID uint, memberID uint) error
+	AddExpense(id uint, userID uint, req dtos.ExpenseRequest) (*dtos.ExpenseResponse, error)
+	ListExpenses(id uint, userID uint, limit, offset int) ([]dtos.ExpenseResponse, error)
+	DeleteExpense(id uint, userID uint, expenseID uint) error
+	GetBalances(id uint, userID uint) (*dtos.GroupBalancesResponse, error)
+	ListSettlements(id uint, userID uint, limit, offset int) ([]dtos.GroupSettlementResponse, error)
+	SettleUp(id uint, userID uint) ([]dtos.GroupSettlementResponse, error)
+}
+
+type expenseGroupService struct {
+	groupRepo          repositories.ExpenseGroupRepository
+	transactionService TransactionService
+	userService        UserService
+	logService         AuditLogService
+}
+
+func NewExpenseGroupService(groupRepo repositories.ExpenseGroupRepository, transactionService TransactionService, userService UserService, logService AuditLogService) ExpenseGroupService {
+	return &expenseGroupService{
+		groupRepo:          groupRepo,
+		transactionService: transactionService,
+		userService:        userService,
+		logService:         logService,
+	}
+}
+
+func (s *expenseGroupService) Create(userID uint, req dtos.ExpenseGroupRequest) (*dtos.ExpenseGroupResponse, error) {
+	currency := currencyOrDefault(req.Currency)
+	if err := requireSupportedCurrency(money.Zero(currency)); err != nil {
+		return nil, err
+	}
+
+	group := &models.ExpenseGroup{
+		Name:      strings.TrimSpace(req.Name),
+		Currency:  currency,
+		CreatedBy: userID,
+	}
+	if group.Name == "" {
+		return nil, appErrors.NewBadRequest(nil, "name must not be blank")
+	}
+
+	if err := s.groupRepo.Create(group); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit(group.Id, "create", fmt.Sprintf("user %d created expense group %d", userID, group.Id)); err != nil {
+		return nil, err
+	}
+
+	return toExpenseGroupResponse(group), nil
+}
+
+func (s *expenseGroupService) GetForUser(id uint, userID uint) (*dtos.ExpenseGroupResponse, error) {
+	group, err := s.getAsMember(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toExpenseGroupResponse(group), nil
+}
+
+func (s *expenseGroupService) ListForUser(userID uint, limit, offset int) ([]dtos.ExpenseGroupResponse, error) {
+	groups, err := s.groupRepo.GetByUserID(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.ExpenseGroupResponse, 0, len(groups))
+	for i := range groups {
+		response = append(response, *toExpenseGroupResponse(&groups[i]))
+	}
+
+	return response, nil
+}
+
+// AddMember adds a user to the group; any member can add others.
+func (s *expenseGroupService) AddMember(id uint, userID uint, req dtos.GroupMemberRequest) (*dtos.ExpenseGroupResponse, error) {
+	if _, err := s.getAsMember(id, userID); err != nil {
+		return nil, err
+	}
+
+	var member *models.User
+	var err error
+	if req.To != "" {
+		if req.UserID != 0 {
+			return nil, appErrors.NewBadRequest(nil, "give either user_id or to, not both")
+		}
+		member, err = s.userService.ResolveRecipient(req.To)
+	} else {
+		member, err = s.userService.GetUserById(int(req.UserID))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.groupRepo.AddMember(id, member.Id); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit(id, "add_member", fmt.Sprintf("user %d added user %d to expense group %d", userID, member.Id, id)); err != nil {
+		return nil, err
+	}
+
+	return s.GetForUser(id, userID)
+}
+
+// RemoveMember lets members leave a group and its creator remove anyone, once the member is
+// settled up.
+func (s *expenseGroupService) RemoveMember(id uint, userID uint, memberID uint) error {
+	group, err := s.getAsMember(id, userID)
+	if err != nil {
+		return err
+	}
+	if memberID != userID && group.CreatedBy != userID {
+		return appErrors.NewForbidden(nil, "only the group's creator can remove other members")
+	}
+
+	if err := s.groupRepo.RemoveMember(id, memberID); err != nil {
+		return err
+	}
+
+	return s.audit(id, "remove_member", fmt.Sprintf("user %d removed user %d from expense group %d", userID, memberID, id))
+}
+
+func (s *expenseGroupService) AddExpense(id uint, userID uint, req dtos.ExpenseRequest) (*dtos.ExpenseResponse, error) {
+	group, err := s.getAsMember(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount.Currency != group.Currency {
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("expenses of this group must be in %s", group.Currency))
+	}
+
+	members := make(map[uint]bool, len(group.Members))
+	for _, member := range group.Members {
+		members[member.UserId] = true
+	}
+
+	paidBy := req.PaidBy
+	if paidBy == 0 {
+		paidBy = userID
+	}
+	if !members[paidBy] {
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("user %d is not a member of the group", paidBy))
+	}
+
+	split := req.Split
+	if split == "" {
+		split = models.SplitTypeEqual
+	}
+
+	shares, err := splitExpense(group, split, req)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint]bool, len(shares))
+	for _, share := range shares {
+		if !members[share.UserId] {
+			return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("user %d is not a member of the group", share.UserId))
+		}
+		if seen[share.UserId] {
+			return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("user %d has more than one share", share.UserId))
+		}
+		seen[share.UserId] = true
+	}
+
+	expense := &models.Expense{
+		GroupId:     id,
+		PaidBy:      paidBy,
+		Amount:      req.Amount,
+		Description: req.Description,
+		SplitType:   split,
+		CreatedBy:   userID,
+		Shares:      shares,
+	}
+	if err := s.groupRepo.CreateExpense(expense); err != nil {
+		return nil, err
+	}
+
+	details := fmt.Sprintf("user %d recorded expense %d of %s paid by user %d, split %s", userID, expense.Id, expense.Amount, paidBy, split)
+	if err := s.audit(id, "add_expense", details); err != nil {
+		return nil, err
+	}
+
+	return toExpenseResponse(expense), nil
+}
+
+func (s *expenseGroupService) ListExpenses(id uint, userID uint, limit, offset int) ([]dtos.ExpenseResponse, error) {
+	if _, err := s.getAsMember(id, userID); err != nil {
+		return nil, err
+	}
+
+	expenses, err := s.groupRepo.GetExpenses(id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.ExpenseResponse, 0, len(expenses))
+	for i := range expenses {
+		response = append(response, *toExpenseResponse(&expenses[i]))
+	}
+
+	return response, nil
+}
+
+// DeleteExpense removes an expense recorded by mistake; only whoever recorded or paid it can.
+func (s *expenseGroupService) DeleteExpense(id uint, userID uint, expenseID uint) error {
+	if _, err := s.getAsMember(id, userID); err != nil {
+		return err
+	}
+
+	expense, err := s.groupRepo.GetExpense(id, expenseID)
+	if err != nil {
+		return err
+	}
+	if expense.CreatedBy != userID && expense.PaidBy != userID {
+		return appErrors.NewForbidden(nil, "only whoever recorded or paid an expense can delete it")
+	}
+
+	if err := s.groupRepo.DeleteExpense(id, expenseID); err != nil {
+		return err
+	}
+
+	return s.audit(id, "delete_expense", fmt.Sprintf("user %d deleted expense %d of %s", userID, expense.Id, expense.Amount))
+}
+
+// GetBalances shows who owes whom in the group, with the debts simplified to as few transfers
+// as possible.
+func (s *expenseGroupService) GetBalances(id uint, userID uint) (*dtos.GroupBalancesResponse, error) {
+	group, err := s.getAsMember(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	net, err := s.groupRepo.NetBalances(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.GroupBalancesResponse{
+		GroupID:  id,
+		Balances: make([]dtos.MemberBalance, 0, len(group.Members)),
+		Debts:    []dtos.GroupDebtResponse{},
+	}
+
+	// Every member is listed, and so is anyone with a balance who is no longer a member.
+	memberIDs := make([]uint, 0, len(net))
+	for memberID := range net {
+		memberIDs = append(memberIDs, memberID)
+	}
+	for _, member := range group.Members {
+		if _, ok := net[member.UserId]; !ok {
+			memberIDs = append(memberIDs, member.UserId)
+		}
+	}
+	sort.Slice(memberIDs, func(a, b int) bool { return memberIDs[a] < memberIDs[b] })
+
+	for _, memberID := range memberIDs {
+		response.Balances = append(response.Balances, dtos.MemberBalance{
+			UserID:  memberID,
+			Balance: money.New(net[memberID], group.Currency),
+		})
+	}
+	for _, debt := range models.SimplifyDebts(net) {
+		response.Debts = append(response.Debts, dtos.GroupDebtResponse{
+			FromUserID: debt.FromUserId,
+			ToUserID:   debt.ToUserId,
+			Amount:     money.New(debt.AmountMinor, group.Currency),
+		})
+	}
+
+	return response, nil
+}
+
+func (s *expenseGroupService) ListSettlements(id uint, userID uint, limit, offset int) ([]dtos.GroupSettlementResponse, error) {
+	if _, err := s.getAsMember(id, userID); err != nil {
+		return nil, err
+	}
+
+	settlements, err := s.groupRepo.GetSettlements(id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.GroupSettlementResponse, 0, len(settlements))
+	for i := range settlements {
+		response = append(response, toGroupSettlementResponse(&settlements[i]))
+	}
+
+	return response, nil
+}
+
+// SettleUp pays what the user owes in the group's simplified debts, one transfer per creditor.
+// Only the caller's own money is moved; other members settle up themselves. Either every
+// transfer goes through or, if one fails, none does and its error is returned.
+func (s *expenseGroupService) SettleUp(id uint, userID uint) ([]dtos.GroupSettlementResponse, error) {
+	if _, err := s.getAsMember(id, userID); err != nil {
+		return nil, err
+	}
+
+	plan := s.groupRepo.SettlementPlan(id, userID)
+	if _, err := s.transactionService.TransferAll(plan); err != nil {
+		return nil, err
+	}
+	if len(plan.Settlements) == 0 {
+		return nil, appErrors.NewConflict(nil, "you do not owe anything in this group")
+	}
+
+	response := make([]dtos.GroupSettlementResponse, 0, len(plan.Settlements))
+	for i := range plan.Settlements {
+		response = append(response, toGroupSettlementResponse(&plan.Settlements[i]))
+	}
+
+	return response, nil
+}
+
+// getAsMember returns a group its members may see; to anyone else it does not exist.
+func (s *expenseGroupService) getAsMember(id uint, userID uint) (*models.ExpenseGroup, error) {
+	group, err := s.groupRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range group.Members {
+		if member.UserId == userID {
+			return group, nil
+		}
+	}
+
+	return nil, appErrors.NewNotFound(nil, fmt.Sprintf("expense group with id %d not found", id))
+}
+
+func (s *expenseGroupService) audit(groupID uint, action, details string) error {
+	return s.logService.CreateAuditLog(int(groupID), models.ExpenseGroupAuditEntity, action, details)
+}
+
+// splitExpense works out the shares of an expense from the request.
+func splitExpense(group *models.ExpenseGroup, split string, req dtos.ExpenseRequest) ([]models.ExpenseShare, error) {
+	switch split {
+	case models.SplitTypeEqual:
+		userIDs := make([]uint, 0, len(req.Shares))
+		for _, share := range req.Shares {
+			userIDs = append(userIDs, share.UserID)
+		}
+		if len(userIDs) == 0 {
+			for _, member := range group.Members {
+				userIDs = append(userIDs, member.UserId)
+			}
+		}
+		return models.SplitEqual(req.Amount.Minor, userIDs), nil
+
+	case models.SplitTypePercentage:
+		if len(req.Shares) == 0 {
+			return nil, appErrors.NewBadRequest(nil, "a percentage split needs shares")
+		}
+		shares := make([]models.ExpenseShare, 0, len(req.Shares))
+		for _, share := range req.Shares {
+			shares = append(shares, models.ExpenseShare{UserId: share.UserID, BasisPoints: share.BasisPoints})
+		}
+		divided, err := models.SplitPercentage(req.Amount.Minor, shares)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, err.Error())
+		}
+		return divided, nil
+
+	case models.SplitTypeExact:
+		if len(req.Shares) == 0 {
+			return nil, appErrors.NewBadRequest(nil, "an exact split needs shares")
+		}
+		shares := make([]models.ExpenseShare, 0, len(req.Shares))
+		for _, share := range req.Shares {
+			if share.Amount == nil {
+				return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("the share of user %d needs an amount", share.UserID))
+			}
+			if share.Amount.Currency != group.Currency {
+				return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("shares of this group must be in %s", group.Currency))
+			}
+			shares = append(shares, models.ExpenseShare{UserId: share.UserID, AmountMinor: share.Amount.Minor})
+		}
+		if err := models.CheckExactSplit(req.Amount.Minor, shares); err != nil {
+			return nil, appErrors.NewBadRequest(err, err.Error())
+		}
+		return shares, nil
+
+	default:
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("unknown split %q", split))
+	}
+}
+
+func toExpenseGroupResponse(group *models.ExpenseGroup) *dtos.ExpenseGroupResponse {
+	response := &dtos.ExpenseGroupResponse{
+		ID:        group.Id,
+		Name:      group.Name,
+		Currency:  group.Currency,
+		CreatedBy: group.CreatedBy,
+		Members:   make([]uint, 0, len(group.Members)),
+		CreatedAt: group.CreatedAt.Format(time.RFC3339),
+	}
+
+	for _, member := range group.Members {
+		response.Members = append(response.Members, member.UserId)
+	}
+
+	return response
+}
+
+func toExpenseResponse(expense *models.Expense) *dtos.ExpenseResponse {
+	response := &dtos.ExpenseResponse{
+		ID:          expense.Id,
+		GroupID:     expense.GroupId,
+		PaidBy:      expense.PaidBy,
+		Amount:      expense.Amount,
+		Description: expense.Description,
+		Split:       expense.SplitType,
+		Shares:      make([]dtos.ExpenseShareResponse, 0, len(expense.Shares)),
+		CreatedBy:   expense.CreatedBy,
+		CreatedAt:   expense.CreatedAt.Format(time.RFC3339),
+	}
+
+	for _, share := range expense.Shares {
+		response.Shares = append(response.Shares, dtos.ExpenseShareResponse{
+			UserID:      share.UserId,
+			Amount:      money.New(share.AmountMinor, expense.Amount.Currency),
+			BasisPoints: share.BasisPoints,
+		})
+	}
+
+	return response
+}
+
+func toGroupSettlementResponse(settlement *models.GroupSettlement) dtos.GroupSettlementResponse {
+	return dtos.GroupSettlementResponse{
+		ID:            settlement.Id,
+		GroupID:       settlement.GroupId,
+		FromUserID:    settlement.FromUserId,
+		ToUserID:      settlement.ToUserId,
+		Amount:        settlement.Amount,
+		Status:        settlement.Status,
+		TransactionID: settlement.TransactionId,
+		CreatedAt:     settlement.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+type FeeService interface {
+	Quote(userID uint, req dtos.FeeQuoteRequest) (*dtos.FeeQuoteResponse, error)
+	CreateRule(req dtos.FeeRuleRequest) (*dtos.FeeRuleResponse, error)
+	ListRules(activeOnly bool, limit, offset int) ([]dtos.FeeRuleResponse, error)
+	DeactivateRule(id uint) (*dtos.FeeRuleResponse, error)
+	GetTotals(from, to string) (map[string][]dtos.FeeTotalResponse, error)
+}
+
+type feeService struct {
+	feeRepo repositories.FeeRepository
+}
+
+func NewFeeService(feeRepo repositories.FeeRepository) FeeService {
+	return &feeService{feeRepo: feeRepo}
+}
+
+// Quote prices a transfer or withdrawal the way the ledger will when it runs, so the user can
+// see the fee before confirming. The schedule can change in between.
+func (f *feeService) Quote(userID uint, req dtos.FeeQuoteRequest) (*dtos.FeeQuoteResponse, error) {
+	if err := requireSupportedCurrency(req.Amount); err != nil {
+		return nil, err
+	}
+
+	fee, rule, err := f.feeRepo.Quote(req.Type, userID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := req.Amount.Add(fee)
+	if err != nil {
+		return nil, appErrors.NewInternalServerError(err)
+	}
+
+	response := &dtos.FeeQuoteResponse{
+		Type:   req.Type,
+		Amount: req.Amount,
+		Fee:    fee,
+		Total:  total,
+	}
+	if rule != nil {
+		response.RuleID = &rule.Id
+	}
+
+	return response, nil
+}
+
+func (f *feeService) CreateRule(req dtos.FeeRuleRequest) (*dtos.FeeRuleResponse, error) {
+	currency := currencyOrDefault(req.Currency)
+	if err := requireSupportedCurrency(money.Zero(currency)); err != nil {
+		return nil, err
+	}
+
+	rule := &models.FeeRule{
+		TransactionType: req.TransactionType,
+		Role:            req.Role,
+		Currency:        currency,
+		Kind:            req.Kind,
+		BasisPoints:     req.BasisPoints,
+		Active:          true,
+	}
+
+	var err error
+	if rule.FlatMinor, err = parseAmountField(req.Flat, currency, "flat"); err != nil {
+		return nil, err
+	}
+	if rule.MinMinor, err = parseAmountField(req.Min, currency, "min"); err != nil {
+		return nil, err
+	}
+	if rule.MaxMinor, err = parseAmountField(req.Max, currency, "max"); err != nil {
+		return nil, err
+	}
+	if rule.MaxMinor > 0 && rule.MinMinor > rule.MaxMinor {
+		return nil, appErrors.NewBadRequest(nil, "min must not be greater than max")
+	}
+
+	switch rule.Kind {
+	case models.FeeKindFlat:
+		if rule.FlatMinor == 0 {
+			return nil, appErrors.NewBadRequest(nil, "a flat fee needs a flat amount")
+		}
+		rule.BasisPoints = 0
+	case models.FeeKindPercentage:
+		if rule.BasisPoints == 0 {
+			return nil, appErrors.NewBadRequest(nil, "a percentage fee needs basis_points")
+		}
+	case models.FeeKindTiered:
+		if rule.Tiers, err = parseFeeTiers(req.Tiers, currency); err != nil {
+			return nil, err
+		}
+		rule.FlatMinor, rule.BasisPoints = 0, 0
+	}
+	if rule.Kind != models.FeeKindTiered && len(req.Tiers) > 0 {
+		return nil, appErrors.NewBadRequest(nil, "tiers can only be given for tiered fees")
+	}
+
+	if err := f.feeRepo.CreateRule(rule); err != nil {
+		return nil, err
+	}
+
+	return toFeeRuleResponse(rule), nil
+}
+
+func (f *feeService) ListRules(activeOnly bool, limit, offset int) ([]dtos.FeeRuleResponse, error) {
+	rules, err := f.feeRepo.ListRules(activeOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.FeeRuleResponse, 0, len(rules))
+	for i := range rules {
+		response = append(response, *toFeeRuleResponse(&rules[i]))
+	}
+
+	return response, nil
+}
+
+func (f *feeService) DeactivateRule(id uint) (*dtos.FeeRuleResponse, error) {
+	rule, err := f.feeRepo.DeactivateRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFeeRuleResponse(rule), nil
+}
+
+// GetTotals reports the fees charged between from and to (the last 30 days by default) per
+// currency and transaction type.
+func (f *feeService) GetTotals(from, to string) (map[string][]dtos.FeeTotalResponse, error) {
+	toTime := time.Now()
+	if to != "" {
+		parsed, err := parseHistoryTime(to, true)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, "invalid to date, use YYYY-MM-DD or RFC 3339")
+		}
+		toTime = parsed
+	}
+
+	fromTime := toTime.AddDate(0, 0, -historyDefaultDays)
+	if from != "" {
+		parsed, err := parseHistoryTime(from, false)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, "invalid from date, use YYYY-MM-DD or RFC 3339")
+		}
+		fromTime = parsed
+	}
+
+	if fromTime.After(toTime) {
+		return nil, appErrors.NewBadRequest(nil, "from must not be after to")
+	}
+
+	totals, err := f.feeRepo.GetTotals(fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(map[string][]dtos.FeeTotalResponse)
+	for _, total := range totals {
+		response[total.Currency] = append(response[total.Currency], dtos.FeeTotalResponse{
+			TransactionType: total.TransactionType,
+			Count:           total.Count,
+			Total:           money.New(total.TotalMinor, total.Currency),
+		})
+	}
+
+	return response, nil
+}
+
+// parseAmountField parses a non-negative decimal amount given in field into minor units. An
+// empty value is zero.
+func parseAmountField(value, currency, field string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	amount, err := money.Parse(value, currency)
+	if err != nil {
+		return 0, appErrors.NewBadRequest(err, fmt.Sprintf("invalid %s amount", field))
+	}
+	if amount.IsNegative() {
+		return 0, appErrors.NewBadRequest(nil, fmt.Sprintf("%s must not be negative", field))
+	}
+
+	return amount.Minor, nil
+}
+
+// parseFeeTiers parses the tiers of a tiered fee, which must have strictly increasing upper
+// bounds and may end with one unbounded tier.
+func parseFeeTiers(requests []dtos.FeeTierRequest, currency string) ([]models.FeeTier, error) {
+	if len(requests) == 0 {
+		return nil, appErrors.NewBadRequest(nil, "a tiered fee needs at least one tier")
+	}
+
+	tiers := make([]models.FeeTier, 0, len(requests))
+	for i, req := range requests {
+		upTo, err := parseAmountField(req.UpTo, currency, fmt.Sprintf("tier %d up_to", i+1))
+		if err != nil {
+			return nil, err
+		}
+		flat, err := parseAmountField(req.Flat, currency, fmt.Sprintf("tier %d flat", i+1))
+		if err != nil {
+			return nil, err
+		}
+
+		if upTo == 0 && i != len(requests)-1 {
+			return nil, appErrors.NewBadRequest(nil, "only the last tier can be unbounded")
+		}
+		if i > 0 && upTo != 0 && upTo <= tiers[i-1].UpToMinor {
+			return nil, appErrors.NewBadRequest(nil, "tier bounds must be increasing")
+		}
+
+		tiers = append(tiers, models.FeeTier{UpToMinor: upTo, FlatMinor: flat, BasisPoints: req.BasisPoints})
+	}
+
+	return tiers, nil
+}
+
+func toFeeRuleResponse(rule *models.FeeRule) *dtos.FeeRuleResponse {
+	response := &dtos.FeeRuleResponse{
+		ID:              rule.Id,
+		TransactionType: rule.TransactionType,
+		Role:            rule.Role,
+		Currency:        rule.Currency,
+		Kind:            rule.Kind,
+		Flat:            money.New(rule.FlatMinor, rule.Currency),
+		BasisPoints:     rule.BasisPoints,
+		Min:             money.New(rule.MinMinor, rule.Currency),
+		Active:          rule.Active,
+		CreatedAt:       rule.CreatedAt.Format(time.RFC3339),
+	}
+
+	if rule.MaxMinor > 0 {
+		max := money.New(rule.MaxMinor, rule.Currency)
+		response.Max = &max
+	}
+
+	for _, tier := range rule.Tiers {
+		tierResponse := dtos.FeeTierResponse{
+			Flat:        money.New(tier.FlatMinor, rule.Currency),
+			BasisPoints: tier.BasisPoints,
+		}
+		if tier.UpToMinor > 0 {
+			upTo := money.New(tier.UpToMinor, rule.Currency)
+			tierResponse.UpTo = &upTo
+		}
+		response.Tiers = append(response.Tiers, tierResponse)
+	}
+
+	return response
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/fx"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+type FxService interface {
+	GetRate(base, quote string) (*dtos.ExchangeRateResponse, error)
+	CreateQuote(userID uint, req dtos.FxQuoteRequest) (*dtos.FxQuoteResponse, error)
+	GetQuote(id uint, userID uint) (*dtos.FxQuoteResponse, error)
+	Convert(userID uint, req dtos.ConversionRequest) (*dtos.ConversionResponse, error)
+	GetConversion(id uint, userID uint) (*dtos.ConversionResponse, error)
+	ListConversions(userID uint, limit, offset int) ([]dtos.ConversionResponse, error)
+	PublishRate(req dtos.ExchangeRateRequest) (*dtos.ExchangeRateResponse, error)
+	ListPublishedRates(limit, offset int) ([]dtos.ExchangeRateResponse, error)
+}
+
+type fxService struct {
+	provider     fx.RateProvider
+	fxRepo       repositories.FxRepository
+	rateRepo     repositories.ExchangeRateRepository
+	cacheService *cache.CacheService
+	quoteTTL     time.Duration
+}
+
+func NewFxService(provider fx.RateProvider, fxRepo repositories.FxRepository, rateRepo repositories.ExchangeRateRepository, cacheService *cache.CacheService, quoteTTL time.Duration) FxService {
+	return &fxService{
+		provider:     provider,
+		fxRepo:       fxRepo,
+		rateRepo:     rateRepo,
+		cacheService: cacheService,
+		quoteTTL:     quoteTTL,
+	}
+}
+
+func (f *fxService) GetRate(base, quote string) (*dtos.ExchangeRateResponse, error) {
+	rate, err := f.lookupRate(currencyOrDefault(base), currencyOrDefault(quote))
+	if err != nil {
+		return nil, err
+	}
+
+	return toExchangeRateResponse(rate), nil
+}
+
+// CreateQuote prices a conversion at the provider's current rate and fixes that price for
+// the quote TTL. Quoting does not reserve any funds.
+func (f *fxService) CreateQuote(userID uint, req dtos.FxQuoteRequest) (*dtos.FxQuoteResponse, error) {
+	toCurrency := currencyOrDefault(req.ToCurrency)
+	if req.Amount.Currency == toCurrency {
+		return nil, appErrors.NewBadRequest(nil, "cannot convert between the same currency")
+	}
+
+	rate, err := f.lookupRate(req.Amount.Currency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := rate.Convert(req.Amount)
+	if err != nil {
+		return nil, appErrors.NewInternalServerError(err)
+	}
+	if !target.IsPositive() {
+		return nil, appErrors.NewUnprocessableEntity(nil, fmt.Sprintf("%s is too small to convert to %s", req.Amount, toCurrency))
+	}
+
+	quote := &models.FxQuote{
+		UserId:    userID,
+		Source:    req.Amount,
+		Target:    target,
+		Rate:      rate.Value,
+		Provider:  rate.Provider,
+		Status:    models.FxQuoteStatusOpen,
+		ExpiresAt: time.Now().Add(f.quoteTTL),
+	}
+
+	if err := f.fxRepo.CreateQuote(quote); err != nil {
+		return nil, err
+	}
+
+	return toFxQuoteResponse(quote), nil
+}
+
+func (f *fxService) GetQuote(id uint, userID uint) (*dtos.FxQuoteResponse, error) {
+	quote, err := f.fxRepo.GetQuoteByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.UserId != userID {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("quote with id %d not found", id))
+	}
+
+	return toFxQuoteResponse(quote), nil
+}
+
+// Convert executes one of the user's quotes at its quoted rate, as long as it has not expired.
+func (f *fxService) Convert(userID uint, req dtos.ConversionRequest) (*dtos.ConversionResponse, error) {
+	if _, err := f.GetQuote(req.QuoteID, userID); err != nil {
+		return nil, err
+	}
+
+	toUserID := userID
+	if req.ToUserID != 0 {
+		toUserID = req.ToUserID
+	}
+
+	conversion, err := f.fxRepo.Execute(req.QuoteID, toUserID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return toConversionResponse(conversion), nil
+}
+
+func (f *fxService) GetConversion(id uint, userID uint) (*dtos.ConversionResponse, error) {
+	conversion, err := f.fxRepo.GetConversionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversion.UserId != userID && conversion.ToUserId != userID {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("conversion with id %d not found", id))
+	}
+
+	return toConversionResponse(conversion), nil
+}
+
+func (f *fxService) ListConversions(userID uint, limit, offset int) ([]dtos.ConversionResponse, error) {
+	conversions, err := f.fxRepo.GetConversionsByUserID(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.ConversionResponse, 0, len(conversions))
+	for i := range conversions {
+		response = append(response, *toConversionResponse(&conversions[i]))
+	}
+
+	return response, nil
+}
+
+// PublishRate records a rate for the database provider and drops the cached rates of the
+// pair, so new quotes use it right away.
+func (f *fxService) PublishRate(req dtos.ExchangeRateRequest) (*dtos.ExchangeRateResponse, error) {
+	if f.provider.Name() != fx.DatabaseRateProvider {
+		return nil, appErrors.NewConflict(nil, fmt.Sprintf("rates are served by the %s provider and cannot be published", f.provider.Name()))
+	}
+
+	value, err := fx.ParseRate(req.Rate)
+	if err != nil {
+		return nil, appErrors.NewBadRequest(err, err.Error())
+	}
+
+	asOf := time.Now()
+	if req.AsOf != "" {
+		asOf, err = time.Parse(time.RFC3339, req.AsOf)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, "invalid as_of, use RFC 3339 (e.g. 2025-01-01T00:00:00Z)")
+		}
+	}
+
+	rate := &models.ExchangeRate{
+		Base:  currencyOrDefault(req.Base),
+		Quote: currencyOrDefault(req.Quote),
+		Rate:  fx.FormatRate(value),
+		AsOf:  asOf,
+	}
+
+	if err := f.rateRepo.Create(rate); err != nil {
+		return nil, err
+	}
+
+	if f.cacheService != nil {
+		ctx := context.Background()
+		for _, key := range []string{fx.CacheKey(rate.Base, rate.Quote), fx.CacheKey(rate.Quote, rate.Base)} {
+			if err := f.cacheService.Delete(ctx, key); err != nil {
+				logger.Log.Warnf("Failed to invalidate cached exchange rate %s: %v", key, err)
+			}
+		}
+	}
+
+	return toExchangeRateResponse(fx.Rate{
+		Base:     rate.Base,
+		Quote:    rate.Quote,
+		Value:    rate.Rate,
+		Provider: fx.DatabaseRateProvider,
+		AsOf:     rate.AsOf,
+	}), nil
+}
+
+func (f *fxService) ListPublishedRates(limit, offset int) ([]dtos.ExchangeRateResponse, error) {
+	rates, err := f.rateRepo.List(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.ExchangeRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		response = append(response, *toExchangeRateResponse(fx.Rate{
+			Base:     rate.Base,
+			Quote:    rate.Quote,
+			Value:    fx.NormalizeRate(rate.Rate),
+			Provider: fx.DatabaseRateProvider,
+			AsOf:     rate.AsOf,
+		}))
+	}
+
+	return response, nil
+}
+
+func (f *fxService) lookupRate(base, quote string) (fx.Rate, error) {
+	for _, currency := range []string{base, quote} {
+		if err := requireSupportedCurrency(money.Zero(currency)); err != nil {
+			return fx.Rate{}, err
+		}
+	}
+
+	rate, err := f.provider.Rate(context.Background(), base, quote)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return fx.Rate{}, appErrors.NewNotFound(err, fmt.Sprintf("no exchange rate available for %s/%s", base, quote))
+		}
+		if appErrors.IsAppError(err) {
+			return fx.Rate{}, err
+		}
+		return fx.Rate{}, appErrors.NewServiceUnavailable(err, "exchange rate provider is unavailable")
+	}
+
+	return rate, nil
+}
+
+func toExchangeRateResponse(rate fx.Rate) *dtos.ExchangeRateResponse {
+	return &dtos.ExchangeRateResponse{
+		Base:     rate.Base,
+		Quote:    rate.Quote,
+		Rate:     rate.Value,
+		Provider: rate.Provider,
+		AsOf:     rate.AsOf.Format(time.RFC3339),
+	}
+}
+
+func toFxQuoteResponse(quote *models.FxQuote) *dtos.FxQuoteResponse {
+	response := &dtos.FxQuoteResponse{
+		ID:        quote.Id,
+		Source:    quote.Source,
+		Target:    quote.Target,
+		Rate:      fx.NormalizeRate(quote.Rate),
+		Provider:  quote.Provider,
+		Status:    quote.CurrentStatus(time.Now()),
+		ExpiresAt: quote.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: quote.CreatedAt.Format(time.RFC3339),
+	}
+
+	if quote.ExecutedAt != nil {
+		response.ExecutedAt = quote.ExecutedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func toConversionResponse(conversion *models.Conversion) *dtos.ConversionResponse {
+	return &dtos.ConversionResponse{
+		ID:            conversion.Id,
+		TransactionID: conversion.TransactionId,
+		QuoteID:       conversion.QuoteId,
+		FromUserID:    conversion.UserId,
+		ToUserID:      conversion.ToUserId,
+		Source:        conversion.Source,
+		Target:        conversion.Target,
+		Rate:          fx.NormalizeRate(conversion.Rate),
+		Provider:      conversion.Provider,
+		CreatedAt:     conversion.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type HoldService interface {
+	Create(userID uint, req dtos.HoldRequest) (*dtos.HoldResponse, error)
+	GetForUser(id uint, userID uint) (*dtos.HoldResponse, error)
+	ListForUser(userID uint, status string, limit, offset int) ([]dtos.HoldResponse, error)
+	Capture(id uint, userID uint, req dtos.CaptureHoldRequest) (*dtos.HoldResponse, error)
+	Release(id uint, userID uint) (*dtos.HoldResponse, error)
+}
+
+type holdService struct {
+	holdRepo   repositories.HoldRepository
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewHoldService(holdRepo repositories.HoldRepository, defaultTTL, maxTTL time.Duration) HoldService {
+	return &holdService{
+		holdRepo:   holdRepo,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+	}
+}
+
+func (h *holdService) Create(userID uint, req dtos.HoldRequest) (*dtos.HoldResponse, error) {
+	if err := requireSupportedCurrency(req.Amount); err != nil {
+		return nil, err
+	}
+
+	ttl := h.defaultTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > h.maxTTL {
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("ttl_seconds must not exceed %d", int(h.maxTTL.Seconds())))
+	}
+
+	hold := &models.Hold{
+		UserId:      userID,
+		BalanceId:   req.AccountID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Status:      models.HoldStatusActive,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+
+	if req.ToUserID != 0 {
+		if req.ToUserID == userID {
+			return nil, appErrors.NewBadRequest(nil, "cannot hold funds for same user")
+		}
+		toUserID := req.ToUserID
+		hold.ToUserId = &toUserID
+	}
+
+	if err := h.holdRepo.Create(hold); err != nil {
+		return nil, err
+	}
+
+	return toHoldResponse(hold), nil
+}
+
+func (h *holdService) GetForUser(id uint, userID uint) (*dtos.HoldResponse, error) {
+	hold, err := h.holdRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.UserId != userID && (hold.ToUserId == nil || *hold.ToUserId != userID) {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("hold with id %d not found", id))
+	}
+
+	return toHoldResponse(hold), nil
+}
+
+func (h *holdService) ListForUser(userID uint, status string, limit, offset int) ([]dtos.HoldResponse, error) {
+	holds, err := h.holdRepo.GetByUserID(userID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.HoldResponse, 0, len(holds))
+	for i := range holds {
+		response = append(response, *toHoldResponse(&holds[i]))
+	}
+
+	return response, nil
+}
+
+func (h *holdService) Capture(id uint, userID uint, req dtos.CaptureHoldRequest) (*dtos.HoldResponse, error) {
+	hold, err := h.getSettleable(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := hold.Amount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	captured, err := h.holdRepo.Capture(id, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return toHoldResponse(captured), nil
+}
+
+func (h *holdService) Release(id uint, userID uint) (*dtos.HoldResponse, error) {
+	if _, err := h.getSettleable(id, userID); err != nil {
+		return nil, err
+	}
+
+	released, err := h.holdRepo.Release(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toHoldResponse(released), nil
+}
+
+// getSettleable loads a hold the user may capture or release: a hold with a payee is settled
+// by the payee, so the payer cannot withdraw the guarantee; any other hold by its owner.
+func (h *holdService) getSettleable(id uint, userID uint) (*models.Hold, error) {
+	hold, err := h.holdRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	settler := hold.UserId
+	if hold.ToUserId != nil {
+		settler = *hold.ToUserId
+	}
+
+	if settler != userID {
+		if hold.UserId == userID {
+			return nil, appErrors.NewForbidden(nil, "only the payee can capture or release this hold")
+		}
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("hold with id %d not found", id))
+	}
+
+	return hold, nil
+}
+
+func toHoldResponse(hold *models.Hold) *dtos.HoldResponse {
+	response := &dtos.HoldResponse{
+		ID:            hold.Id,
+		AccountID:     hold.BalanceId,
+		ToUserID:      hold.ToUserId,
+		Amount:        hold.Amount,
+		Fee:           hold.Fee(),
+		Captured:      hold.Captured(),
+		Description:   hold.Description,
+		Status:        hold.Status,
+		ExpiresAt:     hold.ExpiresAt.Format(time.RFC3339),
+		TransactionID: hold.TransactionId,
+		CreatedAt:     hold.CreatedAt.Format(time.RFC3339),
+	}
+
+	if hold.ClosedAt != nil {
+		response.ClosedAt = hold.ClosedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
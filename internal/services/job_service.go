@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/process"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type JobService interface {
+	Submit(job process.Transaction) (*dtos.JobResponse, error)
+	Enqueue(job *models.Job) error
+	GetJobForUser(id uint, userID uint) (*dtos.JobResponse, error)
+}
+
+type jobService struct {
+	jobRepo    repositories.JobRepository
+	limitRepo  repositories.LimitRepository
+	workerPool *process.WorkerPool
+}
+
+func NewJobService(jobRepo repositories.JobRepository, limitRepo repositories.LimitRepository, workerPool *process.WorkerPool) JobService {
+	return &jobService{
+		jobRepo:    jobRepo,
+		limitRepo:  limitRepo,
+		workerPool: workerPool,
+	}
+}
+
+func (j *jobService) Submit(job process.Transaction) (*dtos.JobResponse, error) {
+	if err := requireSupportedCurrency(job.Amount); err != nil {
+		return nil, err
+	}
+
+	if isLimited(job) {
+		if err := j.limitRepo.Precheck(job.UserId, job.Amount, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	var toUserId *uint
+	if job.ToUserId != 0 {
+		toUserId = &job.ToUserId
+	}
+
+	jobModel := models.NewJob(string(job.Type), job.UserId, toUserId, job.Amount)
+	if job.FromAccountId != 0 {
+		jobModel.FromAccountId = &job.FromAccountId
+	}
+	if job.ToAccountId != 0 {
+		jobModel.ToAccountId = &job.ToAccountId
+	}
+	if err := j.jobRepo.Create(jobModel); err != nil {
+		return nil, err
+	}
+
+	job.JobId = jobModel.Id
+	if err := j.workerPool.SubmitJob(job); err != nil {
+		if markErr := j.jobRepo.MarkFailed(jobModel.Id, failureMessage(err)); markErr != nil {
+			logger.Log.Errorf("Failed to mark rejected job %d as failed: %v", jobModel.Id, markErr)
+		}
+		return nil, err
+	}
+
+	return toJobResponse(jobModel), nil
+}
+
+// Enqueue puts a job that was created together with the record it belongs to on the worker
+// pool. The job is left queued if that fails, so the owner of the record can resubmit it.
+func (j *jobService) Enqueue(job *models.Job) error {
+	return j.workerPool.SubmitStoredJob(job)
+}
+
+func (j *jobService) GetJobForUser(id uint, userID uint) (*dtos.JobResponse, error) {
+	job, err := j.jobRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.UserId != userID {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("job with id %d not found", id))
+	}
+
+	return toJobResponse(job), nil
+}
+
+func toJobResponse(job *models.Job) *dtos.JobResponse {
+	response := &dtos.JobResponse{
+		ID:            job.Id,
+		Type:          job.Type,
+		Status:        job.Status,
+		UserID:        job.UserId,
+		ToUserID:      job.ToUserId,
+		FromAccountID: job.FromAccountId,
+		ToAccountID:   job.ToAccountId,
+		Amount:        job.Amount,
+		FailureReason: job.FailureReason,
+		TransactionID: job.TransactionId,
+		CreatedAt:     job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     job.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if job.StartedAt != nil {
+		startedAt := job.StartedAt.Format(time.RFC3339)
+		response.StartedAt = &startedAt
+	}
+
+	if job.CompletedAt != nil {
+		completedAt := job.CompletedAt.Format(time.RFC3339)
+		response.CompletedAt = &completedAt
+	}
+
+	return response
+}
+
+// isLimited reports whether a job moves money out of the user and counts against their limits.
+func isLimited(job process.Transaction) bool {
+	switch job.Type {
+	case process.WithdrawTransaction:
+		return true
+	case process.TransferTransaction:
+		return job.ToUserId != job.UserId
+	default:
+		return false
+	}
+}
+
+func failureMessage(err error) string {
+	if appErr, ok := appErrors.AsAppError(err); ok {
+		return appErr.Message
+	}
+	return err.Error()
+}
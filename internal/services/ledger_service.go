@@ -0,0 +1,54 @@
+package services
+
+import (
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+)
+
+type LedgerService interface {
+	CheckInvariants() (*repositories.LedgerInvariantReport, error)
+	GetUserPostings(userID uint, limit, offset int) ([]dtos.PostingResponse, error)
+}
+
+type ledgerService struct {
+	ledgerRepo repositories.LedgerRepository
+}
+
+func NewLedgerService(ledgerRepo repositories.LedgerRepository) LedgerService {
+	return &ledgerService{ledgerRepo: ledgerRepo}
+}
+
+func (l *ledgerService) CheckInvariants() (*repositories.LedgerInvariantReport, error) {
+	report, err := l.ledgerRepo.CheckInvariants()
+	if err != nil {
+		return nil, err
+	}
+
+	if !report.Balanced {
+		logger.Log.Errorf("Ledger invariant violated: %d currency imbalances, %d account mismatches", len(report.Imbalances), len(report.Mismatches))
+	}
+
+	return report, nil
+}
+
+func (l *ledgerService) GetUserPostings(userID uint, limit, offset int) ([]dtos.PostingResponse, error) {
+	postings, err := l.ledgerRepo.GetPostingsByAccount(models.UserAccount(userID), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.PostingResponse, 0, len(postings))
+	for _, posting := range postings {
+		response = append(response, dtos.PostingResponse{
+			ID:             posting.Id,
+			JournalEntryID: posting.JournalEntryId,
+			Account:        posting.Account,
+			Amount:         posting.Amount,
+			CreatedAt:      posting.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+// limitRoles are the roles limits can be set for.
+var limitRoles = map[string]bool{"user": true, "admin": true}
+
+type LimitService interface {
+	GetHeadroom(userID uint, currency string) (*dtos.LimitHeadroomResponse, error)
+	ListLimits(limit, offset int) ([]dtos.TransactionLimitResponse, error)
+	SetRoleLimit(role string, req dtos.TransactionLimitRequest) (*dtos.TransactionLimitResponse, error)
+	SetUserLimit(userID uint, req dtos.TransactionLimitRequest) (*dtos.TransactionLimitResponse, error)
+	DeleteUserLimit(userID uint, currency string) error
+}
+
+type limitService struct {
+	limitRepo repositories.LimitRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewLimitService(limitRepo repositories.LimitRepository, userRepo repositories.UserRepository) LimitService {
+	return &limitService{
+		limitRepo: limitRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetHeadroom shows the user's limits in currency and how much they can still move out in
+// the current day and month.
+func (l *limitService) GetHeadroom(userID uint, currency string) (*dtos.LimitHeadroomResponse, error) {
+	currency = currencyOrDefault(currency)
+	if err := requireSupportedCurrency(money.Zero(currency)); err != nil {
+		return nil, err
+	}
+
+	limit, err := l.limitRepo.GetEffective(userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	usage, err := l.limitRepo.GetUsage(userID, currency, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit == nil {
+		limit = &models.TransactionLimit{Currency: currency}
+	}
+
+	dayEnd, monthEnd := models.LimitWindowEnds(now)
+	response := &dtos.LimitHeadroomResponse{
+		Currency:  currency,
+		Source:    limitSource(limit),
+		MaxSingle: optionalMoney(limit.MaxSingleMinor, currency),
+		Daily:     toLimitWindowResponse(usage.DailyMinor, usage.DailyCount, limit.DailyMinor, limit.DailyCount, currency, dayEnd),
+		Monthly:   toLimitWindowResponse(usage.MonthlyMinor, usage.MonthlyCount, limit.MonthlyMinor, limit.MonthlyCount, currency, monthEnd),
+	}
+
+	return response, nil
+}
+
+func (l *limitService) ListLimits(limit, offset int) ([]dtos.TransactionLimitResponse, error) {
+	limits, err := l.limitRepo.List(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.TransactionLimitResponse, 0, len(limits))
+	for i := range limits {
+		response = append(response, *toTransactionLimitResponse(&limits[i]))
+	}
+
+	return response, nil
+}
+
+func (l *limitService) SetRoleLimit(role string, req dtos.TransactionLimitRequest) (*dtos.TransactionLimitResponse, error) {
+	if !limitRoles[role] {
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("unknown role %q", role))
+	}
+
+	limit, err := toTransactionLimit(req)
+	if err != nil {
+		return nil, err
+	}
+	limit.Role = role
+
+	if err := l.limitRepo.Upsert(limit); err != nil {
+		return nil, err
+	}
+
+	return toTransactionLimitResponse(limit), nil
+}
+
+// SetUserLimit overrides the limits of the user's role in one currency for that user alone.
+func (l *limitService) SetUserLimit(userID uint, req dtos.TransactionLimitRequest) (*dtos.TransactionLimitResponse, error) {
+	if _, err := l.userRepo.GetById(int(userID)); err != nil {
+		return nil, err
+	}
+
+	limit, err := toTransactionLimit(req)
+	if err != nil {
+		return nil, err
+	}
+	limit.UserId = userID
+
+	if err := l.limitRepo.Upsert(limit); err != nil {
+		return nil, err
+	}
+
+	return toTransactionLimitResponse(limit), nil
+}
+
+func (l *limitService) DeleteUserLimit(userID uint, currency string) error {
+	return l.limitRepo.DeleteUserLimit(userID, currencyOrDefault(currency))
+}
+
+func toTransactionLimit(req dtos.TransactionLimitRequest) (*models.TransactionLimit, error) {
+	currency := currencyOrDefault(req.Currency)
+	if err := requireSupportedCurrency(money.Zero(currency)); err != nil {
+		return nil, err
+	}
+
+	limit := &models.TransactionLimit{
+		Currency:     currency,
+		DailyCount:   req.DailyCount,
+		MonthlyCount: req.MonthlyCount,
+	}
+
+	var err error
+	if limit.MaxSingleMinor, err = parseAmountField(req.MaxSingle, currency, "max_single"); err != nil {
+		return nil, err
+	}
+	if limit.DailyMinor, err = parseAmountField(req.DailyAmount, currency, "daily_amount"); err != nil {
+		return nil, err
+	}
+	if limit.MonthlyMinor, err = parseAmountField(req.MonthlyAmount, currency, "monthly_amount"); err != nil {
+		return nil, err
+	}
+
+	return limit, nil
+}
+
+func limitSource(limit *models.TransactionLimit) string {
+	switch {
+	case limit.UserId != 0:
+		return "user"
+	case limit.Role != "":
+		return "role"
+	default:
+		return "none"
+	}
+}
+
+func toLimitWindowResponse(usedMinor, usedCount, limitMinor, limitCount int64, currency string, resetsAt time.Time) dtos.LimitWindowResponse {
+	response := dtos.LimitWindowResponse{
+		Used:     money.New(usedMinor, currency),
+		Count:    usedCount,
+		ResetsAt: resetsAt.Format(time.RFC3339),
+	}
+
+	if limitMinor > 0 {
+		limit := money.New(limitMinor, currency)
+		remaining := money.New(max(limitMinor-usedMinor, 0), currency)
+		response.Limit, response.Remaining = &limit, &remaining
+	}
+
+	if limitCount > 0 {
+		remaining := max(limitCount-usedCount, 0)
+		response.CountLimit = &limitCount
+		response.CountRemaining = &remaining
+	}
+
+	return response
+}
+
+func toTransactionLimitResponse(limit *models.TransactionLimit) *dtos.TransactionLimitResponse {
+	response := &dtos.TransactionLimitResponse{
+		ID:            limit.Id,
+		Role:          limit.Role,
+		Currency:      limit.Currency,
+		MaxSingle:     optionalMoney(limit.MaxSingleMinor, limit.Currency),
+		DailyAmount:   optionalMoney(limit.DailyMinor, limit.Currency),
+		MonthlyAmount: optionalMoney(limit.MonthlyMinor, limit.Currency),
+		DailyCount:    limit.DailyCount,
+		MonthlyCount:  limit.MonthlyCount,
+		UpdatedAt:     limit.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if limit.UserId != 0 {
+		userID := limit.UserId
+		response.UserID = &userID
+	}
+
+	return response
+}
+
+// optionalMoney returns minor units of currency, or nil for zero, which means unlimited.
+func optionalMoney(minor int64, currency string) *money.Money {
+	if minor == 0 {
+		return nil
+	}
+	amount := money.New(minor, currency)
+	return &amount
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type PaymentRequestService interface {
+	Create(requesterID uint, req dtos.PaymentRequestRequest) (*dtos.PaymentRequestResponse, error)
+	GetForUser(id uint, userID uint) (*dtos.PaymentRequestResponse, error)
+	ListForUser(userID uint, direction, status string, limit, offset int) ([]dtos.PaymentRequestResponse, error)
+	GetHistory(id uint, userID uint) ([]dtos.PaymentRequestEventResponse, error)
+	Accept(id uint, userID uint, req dtos.AcceptPaymentRequest) (*dtos.PaymentRequestResponse, error)
+	Decline(id uint, userID uint) (*dtos.PaymentRequestResponse, error)
+	Cancel(id uint, userID uint) (*dtos.PaymentRequestResponse, error)
+}
+
+type paymentRequestService struct {
+	requestRepo repositories.PaymentRequestRepository
+	jobService  JobService
+	userService UserService
+	logService  AuditLogService
+	defaultTTL  time.Duration
+	maxTTL      time.Duration
+}
+
+func NewPaymentRequestService(requestRepo repositories.PaymentRequestRepository, jobService JobService, userService UserService, logService AuditLogService, defaultTTL, maxTTL time.Duration) PaymentRequestService {
+	return &paymentRequestService{
+		requestRepo: requestRepo,
+		jobService:  jobService,
+		userService: userService,
+		logService:  logService,
+		defaultTTL:  defaultTTL,
+		maxTTL:      maxTTL,
+	}
+}
+
+func (p *paymentRequestService) Create(requesterID uint, req dtos.PaymentRequestRequest) (*dtos.PaymentRequestResponse, error) {
+	if err := requireSupportedCurrency(req.Amount); err != nil {
+		return nil, err
+	}
+
+	ttl := p.defaultTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > p.maxTTL {
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("ttl_seconds must not exceed %d", int(p.maxTTL.Seconds())))
+	}
+
+	payer, err := p.resolvePayer(req)
+	if err != nil {
+		return nil, err
+	}
+	if payer.Id == requesterID {
+		return nil, appErrors.NewBadRequest(nil, "cannot request money from yourself")
+	}
+
+	request := &models.PaymentRequest{
+		RequesterId: requesterID,
+		PayerId:     payer.Id,
+		Amount:      req.Amount,
+		Note:        req.Note,
+		Status:      models.PaymentRequestStatusPending,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	if err := p.requestRepo.Create(request); err != nil {
+		return nil, err
+	}
+
+	return toPaymentRequestResponse(request), nil
+}
+
+func (p *paymentRequestService) GetForUser(id uint, userID uint) (*dtos.PaymentRequestResponse, error) {
+	request, err := p.getAsParty(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentRequestResponse(request), nil
+}
+
+// ListForUser lists the requests the user received (incoming), sent (outgoing) or, without a
+// direction, both.
+func (p *paymentRequestService) ListForUser(userID uint, direction, status string, limit, offset int) ([]dtos.PaymentRequestResponse, error) {
+	switch direction {
+	case "", repositories.PaymentRequestsIncoming, repositories.PaymentRequestsOutgoing:
+	default:
+		return nil, appErrors.NewBadRequest(nil, "direction must be incoming or outgoing")
+	}
+
+	requests, err := p.requestRepo.GetByUserID(userID, direction, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.PaymentRequestResponse, 0, len(requests))
+	for i := range requests {
+		response = append(response, *toPaymentRequestResponse(&requests[i]))
+	}
+
+	return response, nil
+}
+
+// GetHistory returns the audit-logged state changes of a request, oldest first, to either party.
+func (p *paymentRequestService) GetHistory(id uint, userID uint) ([]dtos.PaymentRequestEventResponse, error) {
+	if _, err := p.getAsParty(id, userID); err != nil {
+		return nil, err
+	}
+
+	logs, err := p.logService.GetAuditLogsByEntity(models.PaymentRequestAuditEntity, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.PaymentRequestEventResponse, 0, len(logs))
+	for _, log := range logs {
+		response = append(response, dtos.PaymentRequestEventResponse{
+			Action:    log.Action,
+			Details:   log.Details,
+			CreatedAt: log.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return response, nil
+}
+
+// Accept creates a transfer of the requested amount from the payer to the requester and puts
+// it on the worker pool. The request is paid once the transfer completes and pending again if
+// it fails. A transfer that cannot be enqueued right away is resubmitted by the scheduler.
+func (p *paymentRequestService) Accept(id uint, userID uint, req dtos.AcceptPaymentRequest) (*dtos.PaymentRequestResponse, error) {
+	request, err := p.getAsParty(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if request.PayerId != userID {
+		return nil, appErrors.NewForbidden(nil, "only the payer can accept a payment request")
+	}
+
+	var fromAccountID *uint
+	if req.FromAccountID != 0 {
+		fromAccountID = &req.FromAccountID
+	}
+
+	request, err = p.requestRepo.Accept(id, fromAccountID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.jobService.Enqueue(request.Job); err != nil {
+		logger.Log.Warnf("Failed to submit transfer of payment request %d, the scheduler will retry: %v", request.Id, err)
+		return toPaymentRequestResponse(request), nil
+	}
+
+	now := time.Now()
+	if err := p.requestRepo.MarkSubmitted(request.Id, now); err != nil {
+		logger.Log.Errorf("Failed to mark payment request %d as submitted: %v", request.Id, err)
+	} else {
+		request.SubmittedAt = &now
+	}
+
+	return toPaymentRequestResponse(request), nil
+}
+
+func (p *paymentRequestService) Decline(id uint, userID uint) (*dtos.PaymentRequestResponse, error) {
+	request, err := p.getAsParty(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if request.PayerId != userID {
+		return nil, appErrors.NewForbidden(nil, "only the payer can decline a payment request")
+	}
+
+	request, err = p.requestRepo.Close(id, models.PaymentRequestStatusDeclined, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentRequestResponse(request), nil
+}
+
+func (p *paymentRequestService) Cancel(id uint, userID uint) (*dtos.PaymentRequestResponse, error) {
+	request, err := p.getAsParty(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterId != userID {
+		return nil, appErrors.NewForbidden(nil, "only the requester can cancel a payment request")
+	}
+
+	request, err = p.requestRepo.Close(id, models.PaymentRequestStatusCancelled, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return toPaymentRequestResponse(request), nil
+}
+
+// resolvePayer finds the user a request is addressed to, by id or by email, username or handle.
+func (p *paymentRequestService) resolvePayer(req dtos.PaymentRequestRequest) (*models.User, error) {
+	if req.To != "" {
+		if req.PayerID != 0 {
+			return nil, appErrors.NewBadRequest(nil, "give either payer_id or to, not both")
+		}
+		return p.userService.ResolveRecipient(req.To)
+	}
+
+	return p.userService.GetUserById(int(req.PayerID))
+}
+
+// getAsParty returns a request its requester or payer may see; to anyone else it does not exist.
+func (p *paymentRequestService) getAsParty(id uint, userID uint) (*models.PaymentRequest, error) {
+	request, err := p.requestRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !request.IsParty(userID) {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("payment request with id %d not found", id))
+	}
+
+	return request, nil
+}
+
+func toPaymentRequestResponse(request *models.PaymentRequest) *dtos.PaymentRequestResponse {
+	response := &dtos.PaymentRequestResponse{
+		ID:            request.Id,
+		RequesterID:   request.RequesterId,
+		PayerID:       request.PayerId,
+		Amount:        request.Amount,
+		Note:          request.Note,
+		Status:        request.Status,
+		ExpiresAt:     request.ExpiresAt.Format(time.RFC3339),
+		FromAccountID: request.FromAccountId,
+		JobID:         request.JobId,
+		TransactionID: request.TransactionId,
+		FailureReason: request.FailureReason,
+		CreatedAt:     request.CreatedAt.Format(time.RFC3339),
+	}
+
+	if request.Job != nil {
+		response.JobStatus = request.Job.Status
+	}
+	if request.ClosedAt != nil {
+		response.ClosedAt = request.ClosedAt.Format(time.RFC3339)
+	}
+
+	return response
+}
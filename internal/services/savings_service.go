@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
+)
+
+type SavingsService interface {
+	CreateProduct(req dtos.SavingsProductRequest) (*dtos.SavingsProductResponse, error)
+	ListProducts(activeOnly bool, limit, offset int) ([]dtos.SavingsProductResponse, error)
+	DeactivateProduct(id uint) (*dtos.SavingsProductResponse, error)
+	SetRate(productID uint, req dtos.InterestRateRequest) (*dtos.InterestRateResponse, error)
+	ListRates(productID uint) ([]dtos.InterestRateResponse, error)
+	GetAccruedInterest(accountID uint, userID uint) (*dtos.AccruedInterestResponse, error)
+}
+
+type savingsService struct {
+	savingsRepo repositories.SavingsRepository
+	balanceRepo repositories.BalancesRepository
+}
+
+func NewSavingsService(savingsRepo repositories.SavingsRepository, balanceRepo repositories.BalancesRepository) SavingsService {
+	return &savingsService{
+		savingsRepo: savingsRepo,
+		balanceRepo: balanceRepo,
+	}
+}
+
+func (s *savingsService) CreateProduct(req dtos.SavingsProductRequest) (*dtos.SavingsProductResponse, error) {
+	currency := currencyOrDefault(req.Currency)
+	if err := requireSupportedCurrency(money.Zero(currency)); err != nil {
+		return nil, err
+	}
+
+	product := &models.SavingsProduct{
+		Name:     strings.TrimSpace(req.Name),
+		Currency: currency,
+		Active:   true,
+	}
+	if product.Name == "" {
+		return nil, appErrors.NewBadRequest(nil, "name must not be blank")
+	}
+
+	rate := &models.InterestRate{
+		AnnualBasisPoints: req.AnnualBasisPoints,
+		EffectiveFrom:     models.UTCDay(time.Now()),
+	}
+	if err := s.savingsRepo.CreateProduct(product, rate); err != nil {
+		return nil, err
+	}
+
+	return toSavingsProductResponse(product, rate.AnnualBasisPoints), nil
+}
+
+// ListProducts returns the products with the rate each of them pays today.
+func (s *savingsService) ListProducts(activeOnly bool, limit, offset int) ([]dtos.SavingsProductResponse, error) {
+	products, err := s.savingsRepo.ListProducts(activeOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.SavingsProductResponse, 0, len(products))
+	for i := range products {
+		basisPoints, err := s.currentRate(products[i].Id)
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, *toSavingsProductResponse(&products[i], basisPoints))
+	}
+
+	return response, nil
+}
+
+func (s *savingsService) DeactivateProduct(id uint) (*dtos.SavingsProductResponse, error) {
+	product, err := s.savingsRepo.DeactivateProduct(id)
+	if err != nil {
+		return nil, err
+	}
+
+	basisPoints, err := s.currentRate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toSavingsProductResponse(product, basisPoints), nil
+}
+
+// SetRate changes a product's rate from a day that has not been accrued yet. Interest already
+// accrued keeps the rate it was accrued at.
+func (s *savingsService) SetRate(productID uint, req dtos.InterestRateRequest) (*dtos.InterestRateResponse, error) {
+	today := models.UTCDay(time.Now())
+
+	effectiveFrom := today
+	if req.EffectiveFrom != "" {
+		parsed, err := time.Parse(dateLayout, req.EffectiveFrom)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, "invalid effective_from date, use YYYY-MM-DD")
+		}
+		effectiveFrom = parsed
+	}
+	if effectiveFrom.Before(today) {
+		return nil, appErrors.NewBadRequest(nil, "effective_from must not be in the past")
+	}
+
+	rate := &models.InterestRate{
+		ProductId:         productID,
+		AnnualBasisPoints: req.AnnualBasisPoints,
+		EffectiveFrom:     effectiveFrom,
+	}
+	if err := s.savingsRepo.SetRate(rate); err != nil {
+		return nil, err
+	}
+
+	return toInterestRateResponse(rate), nil
+}
+
+func (s *savingsService) ListRates(productID uint) ([]dtos.InterestRateResponse, error) {
+	if _, err := s.savingsRepo.GetProductByID(productID); err != nil {
+		return nil, err
+	}
+
+	rates, err := s.savingsRepo.ListRates(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.InterestRateResponse, 0, len(rates))
+	for i := range rates {
+		response = append(response, *toInterestRateResponse(&rates[i]))
+	}
+
+	return response, nil
+}
+
+// GetAccruedInterest shows the interest one of the user's accounts has earned, net of
+// overdraft interest charged, since it was last settled at the start of the month.
+func (s *savingsService) GetAccruedInterest(accountID uint, userID uint) (*dtos.AccruedInterestResponse, error) {
+	account, err := s.balanceRepo.GetByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.UserId != userID {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("account with id %d not found", accountID))
+	}
+	if !account.EarnsInterest() {
+		return nil, appErrors.NewBadRequest(nil, fmt.Sprintf("account %d does not earn interest", accountID))
+	}
+
+	unpaid, err := s.savingsRepo.GetUnpaid(account.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	accrued := models.InterestMinor(unpaid.AmountMicros)
+	if unpaid.AmountMicros < 0 {
+		accrued = -models.InterestMinor(-unpaid.AmountMicros)
+	}
+
+	response := &dtos.AccruedInterestResponse{
+		AccountID:            account.Id,
+		ProductID:            account.SavingsProductId,
+		OverdraftBasisPoints: account.OverdraftBasisPoints,
+		Accrued:              money.New(accrued, account.Amount.Currency),
+		AccruedDays:          unpaid.Days,
+		NextCapitalization:   models.NextCapitalization(time.Now()).Format(time.RFC3339),
+	}
+	if account.SavingsProductId != nil {
+		if response.AnnualBasisPoints, err = s.currentRate(*account.SavingsProductId); err != nil {
+			return nil, err
+		}
+	}
+	if unpaid.Since != nil {
+		response.AccruedSince = unpaid.Since.Format(dateLayout)
+	}
+	if account.InterestAccruedThrough != nil {
+		response.AccruedThrough = account.InterestAccruedThrough.Format(dateLayout)
+	}
+
+	return response, nil
+}
+
+// currentRate returns the annual rate a product pays today, zero if it has none yet.
+func (s *savingsService) currentRate(productID uint) (int64, error) {
+	rate, err := s.savingsRepo.GetRateOn(productID, models.UTCDay(time.Now()))
+	if err != nil {
+		return 0, err
+	}
+	if rate == nil {
+		return 0, nil
+	}
+	return rate.AnnualBasisPoints, nil
+}
+
+func toSavingsProductResponse(product *models.SavingsProduct, annualBasisPoints int64) *dtos.SavingsProductResponse {
+	return &dtos.SavingsProductResponse{
+		ID:                product.Id,
+		Name:              product.Name,
+		Currency:          product.Currency,
+		AnnualBasisPoints: annualBasisPoints,
+		Active:            product.Active,
+		CreatedAt:         product.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toInterestRateResponse(rate *models.InterestRate) *dtos.InterestRateResponse {
+	return &dtos.InterestRateResponse{
+		ID:                rate.Id,
+		ProductID:         rate.ProductId,
+		AnnualBasisPoints: rate.AnnualBasisPoints,
+		EffectiveFrom:     rate.EffectiveFrom.Format(dateLayout),
+		CreatedAt:         rate.CreatedAt.Format(time.RFC3339),
+	}
+}
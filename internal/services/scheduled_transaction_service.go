@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type ScheduledTransactionService interface {
+	Schedule(userID uint, req dtos.ScheduledTransactionRequest) (*dtos.ScheduledTransactionResponse, error)
+	GetScheduledForUser(id uint, userID uint) (*dtos.ScheduledTransactionResponse, error)
+	ListScheduledForUser(userID uint, status string, limit, offset int) ([]dtos.ScheduledTransactionResponse, error)
+	Cancel(id uint, userID uint) (*dtos.ScheduledTransactionResponse, error)
+}
+
+type scheduledTransactionService struct {
+	scheduledRepo repositories.ScheduledTransactionRepository
+}
+
+func NewScheduledTransactionService(scheduledRepo repositories.ScheduledTransactionRepository) ScheduledTransactionService {
+	return &scheduledTransactionService{scheduledRepo: scheduledRepo}
+}
+
+func (s *scheduledTransactionService) Schedule(userID uint, req dtos.ScheduledTransactionRequest) (*dtos.ScheduledTransactionResponse, error) {
+	scheduledAt, err := time.Parse(time.RFC3339, req.Date)
+	if err != nil {
+		return nil, appErrors.NewBadRequest(err, "invalid date, use RFC 3339 (e.g. 2025-01-31T09:00:00Z)")
+	}
+	if !scheduledAt.After(time.Now()) {
+		return nil, appErrors.NewBadRequest(nil, "date must be in the future")
+	}
+	if err := requireSupportedCurrency(req.Amount); err != nil {
+		return nil, err
+	}
+
+	parties, err := scheduledPartiesFor(userID, req.Type, req.ToUserID, req.FromAccountID, req.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := &models.ScheduledTransaction{
+		UserId:        userID,
+		ToUserId:      parties.toUserID,
+		FromAccountId: parties.fromAccountID,
+		ToAccountId:   parties.toAccountID,
+		Type:          req.Type,
+		Amount:        req.Amount,
+		ScheduledAt:   scheduledAt,
+		Status:        models.ScheduledStatusScheduled,
+	}
+
+	if err := s.scheduledRepo.Create(scheduled); err != nil {
+		return nil, err
+	}
+
+	return toScheduledTransactionResponse(scheduled), nil
+}
+
+func (s *scheduledTransactionService) GetScheduledForUser(id uint, userID uint) (*dtos.ScheduledTransactionResponse, error) {
+	scheduled, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toScheduledTransactionResponse(scheduled), nil
+}
+
+func (s *scheduledTransactionService) ListScheduledForUser(userID uint, status string, limit, offset int) ([]dtos.ScheduledTransactionResponse, error) {
+	scheduled, err := s.scheduledRepo.GetByUserID(userID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.ScheduledTransactionResponse, 0, len(scheduled))
+	for i := range scheduled {
+		response = append(response, *toScheduledTransactionResponse(&scheduled[i]))
+	}
+
+	return response, nil
+}
+
+func (s *scheduledTransactionService) Cancel(id uint, userID uint) (*dtos.ScheduledTransactionResponse, error) {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduledRepo.Cancel(id); err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.scheduledRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toScheduledTransactionResponse(scheduled), nil
+}
+
+func (s *scheduledTransactionService) getOwned(id uint, userID uint) (*models.ScheduledTransaction, error) {
+	scheduled, err := s.scheduledRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if scheduled.UserId != userID {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("scheduled transaction with id %d not found", id))
+	}
+
+	return scheduled, nil
+}
+
+func toScheduledTransactionResponse(scheduled *models.ScheduledTransaction) *dtos.ScheduledTransactionResponse {
+	response := &dtos.ScheduledTransactionResponse{
+		ID:            scheduled.Id,
+		Type:          scheduled.Type,
+		ToUserID:      scheduled.ToUserId,
+		FromAccountID: scheduled.FromAccountId,
+		ToAccountID:   scheduled.ToAccountId,
+		Amount:        scheduled.Amount,
+		ScheduledAt:   scheduled.ScheduledAt.Format(time.RFC3339),
+		Status:        scheduled.Status,
+		JobID:         scheduled.JobId,
+		CreatedAt:     scheduled.CreatedAt.Format(time.RFC3339),
+	}
+
+	if scheduled.Job != nil {
+		response.JobStatus = scheduled.Job.Status
+	}
+
+	return response
+}
+
+// scheduledParties is who and which accounts a scheduled transaction or standing order moves
+// money between, besides its owner.
+type scheduledParties struct {
+	toUserID      *uint
+	fromAccountID *uint
+	toAccountID   *uint
+}
+
+// scheduledPartiesFor checks the recipient and accounts of a scheduled transaction or standing
+// order like an immediate one: deposits may name the account they credit, withdrawals the
+// account they debit, and transfers both, where a transfer without a recipient, or to the
+// owner, moves money between the owner's own accounts.
+func scheduledPartiesFor(userID uint, transactionType string, toUserID, fromAccountID, toAccountID uint) (*scheduledParties, error) {
+	parties := &scheduledParties{}
+	if fromAccountID != 0 {
+		parties.fromAccountID = &fromAccountID
+	}
+	if toAccountID != 0 {
+		parties.toAccountID = &toAccountID
+	}
+
+	switch transactionType {
+	case models.TransactionTypeDeposit:
+		if fromAccountID != 0 {
+			return nil, appErrors.NewBadRequest(nil, "from_account_id can only be given for withdrawals and transfers")
+		}
+	case models.TransactionTypeWithdraw:
+		if toAccountID != 0 {
+			return nil, appErrors.NewBadRequest(nil, "to_account_id can only be given for deposits and transfers")
+		}
+	case models.TransactionTypeTransfer:
+		if toUserID == 0 && toAccountID == 0 {
+			return nil, appErrors.NewBadRequest(nil, "to_user_id or to_account_id is required for transfers")
+		}
+		if toUserID == 0 {
+			toUserID = userID
+		}
+		if toUserID != userID && toAccountID != 0 {
+			return nil, appErrors.NewBadRequest(nil, "to_account_id can only be given for transfers between your own accounts")
+		}
+		if toUserID == userID && toAccountID == 0 {
+			return nil, appErrors.NewBadRequest(nil, "to_account_id is required for transfers between your own accounts")
+		}
+		parties.toUserID = &toUserID
+	}
+
+	return parties, nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type StandingOrderService interface {
+	Create(userID uint, req dtos.StandingOrderRequest) (*dtos.StandingOrderResponse, error)
+	GetForUser(id uint, userID uint) (*dtos.StandingOrderResponse, error)
+	ListForUser(userID uint, limit, offset int) ([]dtos.StandingOrderResponse, error)
+	GetOccurrences(id uint, userID uint, limit, offset int) ([]dtos.StandingOrderOccurrenceResponse, error)
+	Pause(id uint, userID uint) (*dtos.StandingOrderResponse, error)
+	Resume(id uint, userID uint) (*dtos.StandingOrderResponse, error)
+	Skip(id uint, userID uint) (*dtos.StandingOrderResponse, error)
+	Cancel(id uint, userID uint) (*dtos.StandingOrderResponse, error)
+}
+
+type standingOrderService struct {
+	orderRepo repositories.StandingOrderRepository
+}
+
+func NewStandingOrderService(orderRepo repositories.StandingOrderRepository) StandingOrderService {
+	return &standingOrderService{orderRepo: orderRepo}
+}
+
+func (s *standingOrderService) Create(userID uint, req dtos.StandingOrderRequest) (*dtos.StandingOrderResponse, error) {
+	if err := requireSupportedCurrency(req.Amount); err != nil {
+		return nil, err
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, appErrors.NewBadRequest(err, fmt.Sprintf("unknown time zone %q", timezone))
+	}
+
+	startAt, err := time.ParseInLocation(time.RFC3339, req.StartAt, loc)
+	if err != nil {
+		return nil, appErrors.NewBadRequest(err, "invalid start_at, use RFC 3339 (e.g. 2025-01-01T09:00:00Z)")
+	}
+
+	parties, err := scheduledPartiesFor(userID, req.Type, req.ToUserID, req.FromAccountID, req.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.StandingOrder{
+		UserId:         userID,
+		ToUserId:       parties.toUserID,
+		FromAccountId:  parties.fromAccountID,
+		ToAccountId:    parties.toAccountID,
+		Type:           req.Type,
+		Amount:         req.Amount,
+		Description:    req.Description,
+		Recurrence:     req.Recurrence,
+		Timezone:       timezone,
+		StartAt:        startAt,
+		MaxOccurrences: req.MaxOccurrences,
+		Status:         models.StandingOrderStatusActive,
+		RetryFailed:    req.RetryFailed,
+		MaxRetries:     req.MaxRetries,
+	}
+
+	if req.EndAt != "" {
+		endAt, err := time.ParseInLocation(time.RFC3339, req.EndAt, loc)
+		if err != nil {
+			return nil, appErrors.NewBadRequest(err, "invalid end_at, use RFC 3339 (e.g. 2025-12-31T23:59:59Z)")
+		}
+		if !endAt.After(startAt) {
+			return nil, appErrors.NewBadRequest(nil, "end_at must be after start_at")
+		}
+		order.EndAt = &endAt
+	}
+
+	next, err := order.NextOccurrence(time.Now())
+	if err != nil {
+		return nil, appErrors.NewBadRequest(err, err.Error())
+	}
+	if next == nil {
+		return nil, appErrors.NewBadRequest(nil, "recurrence has no occurrences between start_at and end_at")
+	}
+	order.NextRunAt = next
+
+	if err := s.orderRepo.Create(order); err != nil {
+		return nil, err
+	}
+
+	return toStandingOrderResponse(order), nil
+}
+
+func (s *standingOrderService) GetForUser(id uint, userID uint) (*dtos.StandingOrderResponse, error) {
+	order, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toStandingOrderResponse(order), nil
+}
+
+func (s *standingOrderService) ListForUser(userID uint, limit, offset int) ([]dtos.StandingOrderResponse, error) {
+	orders, err := s.orderRepo.GetByUserID(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.StandingOrderResponse, 0, len(orders))
+	for i := range orders {
+		response = append(response, *toStandingOrderResponse(&orders[i]))
+	}
+
+	return response, nil
+}
+
+func (s *standingOrderService) GetOccurrences(id uint, userID uint, limit, offset int) ([]dtos.StandingOrderOccurrenceResponse, error) {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	occurrences, err := s.orderRepo.GetOccurrences(id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.StandingOrderOccurrenceResponse, 0, len(occurrences))
+	for i := range occurrences {
+		response = append(response, toStandingOrderOccurrenceResponse(&occurrences[i]))
+	}
+
+	return response, nil
+}
+
+func (s *standingOrderService) Pause(id uint, userID uint) (*dtos.StandingOrderResponse, error) {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderRepo.Pause(id); err != nil {
+		return nil, err
+	}
+
+	return s.GetForUser(id, userID)
+}
+
+// Resume picks the order up from its next due date after now; due dates that passed while it
+// was paused are not made up.
+func (s *standingOrderService) Resume(id uint, userID uint) (*dtos.StandingOrderResponse, error) {
+	order, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := order.NextOccurrence(time.Now())
+	if err != nil {
+		return nil, appErrors.NewInternalServerError(err)
+	}
+
+	if err := s.orderRepo.Resume(id, next); err != nil {
+		return nil, err
+	}
+
+	return s.GetForUser(id, userID)
+}
+
+func (s *standingOrderService) Skip(id uint, userID uint) (*dtos.StandingOrderResponse, error) {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.orderRepo.Skip(id); err != nil {
+		return nil, err
+	}
+
+	return s.GetForUser(id, userID)
+}
+
+func (s *standingOrderService) Cancel(id uint, userID uint) (*dtos.StandingOrderResponse, error) {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderRepo.Cancel(id); err != nil {
+		return nil, err
+	}
+
+	return s.GetForUser(id, userID)
+}
+
+func (s *standingOrderService) getOwned(id uint, userID uint) (*models.StandingOrder, error) {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserId != userID {
+		return nil, appErrors.NewNotFound(nil, fmt.Sprintf("standing order with id %d not found", id))
+	}
+
+	return order, nil
+}
+
+func toStandingOrderResponse(order *models.StandingOrder) *dtos.StandingOrderResponse {
+	response := &dtos.StandingOrderResponse{
+		ID:              order.Id,
+		Type:            order.Type,
+		ToUserID:        order.ToUserId,
+		FromAccountID:   order.FromAccountId,
+		ToAccountID:     order.ToAccountId,
+		Amount:          order.Amount,
+		Description:     order.Description,
+		Recurrence:      order.Recurrence,
+		Timezone:        order.Timezone,
+		StartAt:         order.StartAt.Format(time.RFC3339),
+		MaxOccurrences:  order.MaxOccurrences,
+		OccurrenceCount: order.OccurrenceCount,
+		Status:          order.Status,
+		RetryFailed:     order.RetryFailed,
+		MaxRetries:      order.MaxRetries,
+		CreatedAt:       order.CreatedAt.Format(time.RFC3339),
+	}
+
+	if order.EndAt != nil {
+		response.EndAt = order.EndAt.Format(time.RFC3339)
+	}
+	if order.NextRunAt != nil {
+		response.NextRunAt = order.NextRunAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func toStandingOrderOccurrenceResponse(occurrence *models.StandingOrderOccurrence) dtos.StandingOrderOccurrenceResponse {
+	response := dtos.StandingOrderOccurrenceResponse{
+		ID:            occurrence.Id,
+		Sequence:      occurrence.Sequence,
+		Attempt:       occurrence.Attempt,
+		DueAt:         occurrence.DueAt.Format(time.RFC3339),
+		RunAt:         occurrence.RunAt.Format(time.RFC3339),
+		Status:        occurrence.Status,
+		JobID:         occurrence.JobId,
+		TransactionID: occurrence.TransactionId,
+		FailureReason: occurrence.FailureReason,
+	}
+
+	if occurrence.Job != nil {
+		response.JobStatus = occurrence.Job.Status
+	}
+
+	return response
+}
@@ -12,27 +12,34 @@ import (
 	"github.com/yusuffugurlu/go-project/internal/models"
 	"github.com/yusuffugurlu/go-project/internal/repositories"
 	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/money"
 )
 
 type TransactionService interface {
 	CreateTransaction(req dtos.TransactionRequest) (*models.Transaction, error)
-	DebitFromUser(userID uint, amount float64) error
-	TransferBetweenUsers(fromUserID, toUserID uint, amount float64) error
-	GetTransactionHistory(userID uint, limit, offset int) ([]*dtos.TransactionResponse, error)
+	DebitFromUser(userID uint, amount money.Money) error
+	TransferBetweenUsers(fromUserID, toUserID uint, amount money.Money) error
+	TransferAll(plan repositories.TransferPlan) ([]*models.Transaction, error)
+	GetTransactionHistory(userID uint, accountID *uint, limit, offset int) ([]*dtos.TransactionResponse, error)
 	GetTransactionByID(id uint) (*dtos.TransactionResponse, error)
 	GetAllTransactions(limit, offset int) ([]*dtos.TransactionResponse, error)
+	ReverseTransaction(id uint, actorID uint, isAdmin bool, req dtos.ReversalRequest) (*dtos.TransactionResponse, error)
 }
 
 type transactionService struct {
 	transactionRepo repositories.TransactionRepository
+	ledgerRepo      repositories.LedgerRepository
 	balanceRepo     repositories.BalancesRepository
+	logService      AuditLogService
 	cacheService    *cache.CacheService
 }
 
 func NewTransactionService() TransactionService {
 	return &transactionService{
 		transactionRepo: repositories.NewTransactionRepository(database.Db),
+		ledgerRepo:      repositories.NewLedgerRepository(database.Db),
 		balanceRepo:     repositories.NewBalancesRepository(database.Db),
+		logService:      NewAuditLogService(repositories.NewAuditLogRepository(database.Db)),
 		cacheService:    nil,
 	}
 }
@@ -40,7 +47,9 @@ func NewTransactionService() TransactionService {
 func NewTransactionServiceWithCache(cacheService *cache.CacheService) TransactionService {
 	return &transactionService{
 		transactionRepo: repositories.NewTransactionRepository(database.Db),
+		ledgerRepo:      repositories.NewLedgerRepository(database.Db),
 		balanceRepo:     repositories.NewBalancesRepository(database.Db),
+		logService:      NewAuditLogService(repositories.NewAuditLogRepository(database.Db)),
 		cacheService:    cacheService,
 	}
 }
@@ -53,25 +62,12 @@ func (t *transactionService) CreateTransaction(req dtos.TransactionRequest) (*mo
 	return nil, nil
 }
 
-func (t *transactionService) DebitFromUser(userID uint, amount float64) error {
-	if amount <= 0 {
+func (t *transactionService) DebitFromUser(userID uint, amount money.Money) error {
+	if !amount.IsPositive() {
 		return appErrors.NewBadRequest(nil, "amount must be positive")
 	}
 
-	if err := t.balanceRepo.Deposit(userID, amount); err != nil {
-		return err
-	}
-
-	transaction := &models.Transaction{
-		FromUserId: nil,
-		ToUserId:   &userID,
-		Amount:     amount,
-		Type:       "debit",
-		Status:     "completed",
-		CreatedAt:  time.Now(),
-	}
-
-	if err := t.transactionRepo.Create(transaction); err != nil {
+	if _, err := t.ledgerRepo.Deposit(userID, amount, models.TransactionTypeDebit); err != nil {
 		return err
 	}
 
@@ -83,8 +79,8 @@ func (t *transactionService) DebitFromUser(userID uint, amount float64) error {
 	return nil
 }
 
-func (t *transactionService) TransferBetweenUsers(fromUserID, toUserID uint, amount float64) error {
-	if amount <= 0 {
+func (t *transactionService) TransferBetweenUsers(fromUserID, toUserID uint, amount money.Money) error {
+	if !amount.IsPositive() {
 		return appErrors.NewBadRequest(nil, "amount must be positive")
 	}
 
@@ -92,7 +88,7 @@ func (t *transactionService) TransferBetweenUsers(fromUserID, toUserID uint, amo
 		return appErrors.NewBadRequest(nil, "cannot transfer to same user")
 	}
 
-	if err := t.transactionRepo.Transfer(fromUserID, toUserID, amount); err != nil {
+	if _, err := t.ledgerRepo.Transfer(fromUserID, toUserID, amount); err != nil {
 		return err
 	}
 
@@ -105,10 +101,50 @@ func (t *transactionService) TransferBetweenUsers(fromUserID, toUserID uint, amo
 	return nil
 }
 
-func (t *transactionService) GetTransactionHistory(userID uint, limit, offset int) ([]*dtos.TransactionResponse, error) {
+// TransferAll makes the transfers plan decides in one database transaction with the plan's
+// own reads and writes, so either all of them go through or none does. Each transfer is
+// checked like TransferBetweenUsers before it is made.
+func (t *transactionService) TransferAll(plan repositories.TransferPlan) ([]*models.Transaction, error) {
+	transactions, err := t.ledgerRepo.TransferAll(plan, checkTransfer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transaction := range transactions {
+		details := fmt.Sprintf("%s transferred from user %d to user %d", transaction.Amount, *transaction.FromUserId, *transaction.ToUserId)
+		// The money has already moved, so a failure here must not fail the request.
+		if err := t.logService.CreateAuditLog(int(transaction.Id), "transaction", "transfer", details); err != nil {
+			logger.Log.Errorf("Failed to audit transfer %d: %v", transaction.Id, err)
+		}
+
+		if t.cacheService != nil {
+			ctx := context.Background()
+			t.invalidateUserTransactionCaches(ctx, *transaction.FromUserId)
+			t.invalidateUserTransactionCaches(ctx, *transaction.ToUserId)
+		}
+	}
+
+	return transactions, nil
+}
+
+// GetTransactionHistory returns the user's transactions, or only those of one of their
+// accounts if accountID is set.
+func (t *transactionService) GetTransactionHistory(userID uint, accountID *uint, limit, offset int) ([]*dtos.TransactionResponse, error) {
+	scope := "all"
+	if accountID != nil {
+		account, err := t.balanceRepo.GetByID(*accountID)
+		if err != nil {
+			return nil, err
+		}
+		if account.UserId != userID {
+			return nil, appErrors.NewNotFound(nil, fmt.Sprintf("account with id %d not found", *accountID))
+		}
+		scope = fmt.Sprintf("account-%d", *accountID)
+	}
+
 	if t.cacheService != nil {
 		ctx := context.Background()
-		cacheKey := t.cacheService.GenerateCacheKey("transactions:user", fmt.Sprintf("%d:%d:%d", userID, limit, offset))
+		cacheKey := t.cacheService.GenerateCacheKey("transactions:user", fmt.Sprintf("%d:%s:%d:%d", userID, scope, limit, offset))
 
 		var transactions []*dtos.TransactionResponse
 		if err := t.cacheService.GetJSON(ctx, cacheKey, &transactions); err == nil {
@@ -117,7 +153,13 @@ func (t *transactionService) GetTransactionHistory(userID uint, limit, offset in
 		}
 	}
 
-	transactions, err := t.transactionRepo.GetHistoryByUserID(userID, limit, offset)
+	var transactions []*models.Transaction
+	var err error
+	if accountID != nil {
+		transactions, err = t.transactionRepo.GetHistoryByAccountID(*accountID, limit, offset)
+	} else {
+		transactions, err = t.transactionRepo.GetHistoryByUserID(userID, limit, offset)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -125,13 +167,18 @@ func (t *transactionService) GetTransactionHistory(userID uint, limit, offset in
 	var response []*dtos.TransactionResponse
 	for _, tx := range transactions {
 		txResponse := &dtos.TransactionResponse{
-			ID:         tx.Id,
-			FromUserID: tx.FromUserId,
-			ToUserID:   tx.ToUserId,
-			Amount:     tx.Amount,
-			Type:       tx.Type,
-			Status:     tx.Status,
-			CreatedAt:  tx.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:             tx.Id,
+			FromUserID:     tx.FromUserId,
+			ToUserID:       tx.ToUserId,
+			FromAccountID:  tx.FromAccountId,
+			ToAccountID:    tx.ToAccountId,
+			Amount:         tx.Amount,
+			Type:           tx.Type,
+			Status:         tx.Status,
+			ReversalOfID:   tx.ReversalOfId,
+			FeeOfID:        tx.FeeOfId,
+			RefundedAmount: refundedAmount(tx),
+			CreatedAt:      tx.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
 
 		if tx.FromUser != nil {
@@ -140,10 +187,8 @@ func (t *transactionService) GetTransactionHistory(userID uint, limit, offset in
 				Username: tx.FromUser.Username,
 				Email:    tx.FromUser.Email,
 			}
-			if tx.FromUser.Balance != nil {
-				fromUserResponse.Balance = &dtos.BalanceResponse{
-					Amount: tx.FromUser.Balance.Amount,
-				}
+			if balance := tx.FromUser.BalanceIn(tx.Amount.Currency); balance != nil {
+				fromUserResponse.Balance = toBalanceResponse(balance)
 			}
 			txResponse.FromUser = fromUserResponse
 		}
@@ -154,10 +199,8 @@ func (t *transactionService) GetTransactionHistory(userID uint, limit, offset in
 				Username: tx.ToUser.Username,
 				Email:    tx.ToUser.Email,
 			}
-			if tx.ToUser.Balance != nil {
-				toUserResponse.Balance = &dtos.BalanceResponse{
-					Amount: tx.ToUser.Balance.Amount,
-				}
+			if balance := tx.ToUser.BalanceIn(tx.Amount.Currency); balance != nil {
+				toUserResponse.Balance = toBalanceResponse(balance)
 			}
 			txResponse.ToUser = toUserResponse
 		}
@@ -167,7 +210,7 @@ func (t *transactionService) GetTransactionHistory(userID uint, limit, offset in
 
 	if t.cacheService != nil {
 		ctx := context.Background()
-		cacheKey := t.cacheService.GenerateCacheKey("transactions:user", fmt.Sprintf("%d:%d:%d", userID, limit, offset))
+		cacheKey := t.cacheService.GenerateCacheKey("transactions:user", fmt.Sprintf("%d:%s:%d:%d", userID, scope, limit, offset))
 		if err := t.cacheService.SetJSON(ctx, cacheKey, response, 2*time.Minute); err != nil {
 			logger.Log.Error("Failed to cache transaction history", err)
 		}
@@ -194,13 +237,18 @@ func (t *transactionService) GetTransactionByID(id uint) (*dtos.TransactionRespo
 	}
 
 	response := &dtos.TransactionResponse{
-		ID:         transaction.Id,
-		FromUserID: transaction.FromUserId,
-		ToUserID:   transaction.ToUserId,
-		Amount:     transaction.Amount,
-		Type:       transaction.Type,
-		Status:     transaction.Status,
-		CreatedAt:  transaction.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:             transaction.Id,
+		FromUserID:     transaction.FromUserId,
+		ToUserID:       transaction.ToUserId,
+		FromAccountID:  transaction.FromAccountId,
+		ToAccountID:    transaction.ToAccountId,
+		Amount:         transaction.Amount,
+		Type:           transaction.Type,
+		Status:         transaction.Status,
+		ReversalOfID:   transaction.ReversalOfId,
+		FeeOfID:        transaction.FeeOfId,
+		RefundedAmount: refundedAmount(transaction),
+		CreatedAt:      transaction.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
 	if transaction.FromUser != nil {
@@ -209,10 +257,8 @@ func (t *transactionService) GetTransactionByID(id uint) (*dtos.TransactionRespo
 			Username: transaction.FromUser.Username,
 			Email:    transaction.FromUser.Email,
 		}
-		if transaction.FromUser.Balance != nil {
-			fromUserResponse.Balance = &dtos.BalanceResponse{
-				Amount: transaction.FromUser.Balance.Amount,
-			}
+		if balance := transaction.FromUser.BalanceIn(transaction.Amount.Currency); balance != nil {
+			fromUserResponse.Balance = toBalanceResponse(balance)
 		}
 		response.FromUser = fromUserResponse
 	}
@@ -223,10 +269,8 @@ func (t *transactionService) GetTransactionByID(id uint) (*dtos.TransactionRespo
 			Username: transaction.ToUser.Username,
 			Email:    transaction.ToUser.Email,
 		}
-		if transaction.ToUser.Balance != nil {
-			toUserResponse.Balance = &dtos.BalanceResponse{
-				Amount: transaction.ToUser.Balance.Amount,
-			}
+		if balance := transaction.ToUser.BalanceIn(transaction.Amount.Currency); balance != nil {
+			toUserResponse.Balance = toBalanceResponse(balance)
 		}
 		response.ToUser = toUserResponse
 	}
@@ -262,13 +306,18 @@ func (t *transactionService) GetAllTransactions(limit, offset int) ([]*dtos.Tran
 	var response []*dtos.TransactionResponse
 	for _, tx := range transactions {
 		txResponse := &dtos.TransactionResponse{
-			ID:         tx.Id,
-			FromUserID: tx.FromUserId,
-			ToUserID:   tx.ToUserId,
-			Amount:     tx.Amount,
-			Type:       tx.Type,
-			Status:     tx.Status,
-			CreatedAt:  tx.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:             tx.Id,
+			FromUserID:     tx.FromUserId,
+			ToUserID:       tx.ToUserId,
+			FromAccountID:  tx.FromAccountId,
+			ToAccountID:    tx.ToAccountId,
+			Amount:         tx.Amount,
+			Type:           tx.Type,
+			Status:         tx.Status,
+			ReversalOfID:   tx.ReversalOfId,
+			FeeOfID:        tx.FeeOfId,
+			RefundedAmount: refundedAmount(tx),
+			CreatedAt:      tx.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
 
 		if tx.FromUser != nil {
@@ -277,10 +326,8 @@ func (t *transactionService) GetAllTransactions(limit, offset int) ([]*dtos.Tran
 				Username: tx.FromUser.Username,
 				Email:    tx.FromUser.Email,
 			}
-			if tx.FromUser.Balance != nil {
-				fromUserResponse.Balance = &dtos.BalanceResponse{
-					Amount: tx.FromUser.Balance.Amount,
-				}
+			if balance := tx.FromUser.BalanceIn(tx.Amount.Currency); balance != nil {
+				fromUserResponse.Balance = toBalanceResponse(balance)
 			}
 			txResponse.FromUser = fromUserResponse
 		}
@@ -291,10 +338,8 @@ func (t *transactionService) GetAllTransactions(limit, offset int) ([]*dtos.Tran
 				Username: tx.ToUser.Username,
 				Email:    tx.ToUser.Email,
 			}
-			if tx.ToUser.Balance != nil {
-				toUserResponse.Balance = &dtos.BalanceResponse{
-					Amount: tx.ToUser.Balance.Amount,
-				}
+			if balance := tx.ToUser.BalanceIn(tx.Amount.Currency); balance != nil {
+				toUserResponse.Balance = toBalanceResponse(balance)
 			}
 			txResponse.ToUser = toUserResponse
 		}
@@ -313,6 +358,56 @@ func (t *transactionService) GetAllTransactions(limit, offset int) ([]*dtos.Tran
 	return response, nil
 }
 
+// ReverseTransaction refunds all or part of a transaction. Admins can reverse any transaction;
+// users can only refund transfers they received.
+func (t *transactionService) ReverseTransaction(id uint, actorID uint, isAdmin bool, req dtos.ReversalRequest) (*dtos.TransactionResponse, error) {
+	original, err := t.transactionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAdmin {
+		if !isParty(original, actorID) {
+			return nil, appErrors.NewNotFound(nil, fmt.Sprintf("transaction with id %d not found", id))
+		}
+		if original.Type != models.TransactionTypeTransfer || original.ToUserId == nil || *original.ToUserId != actorID {
+			return nil, appErrors.NewForbidden(nil, "only the recipient of a transfer can refund it")
+		}
+	}
+
+	amount := original.Refundable()
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	reversal, err := t.ledgerRepo.Reverse(id, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := "user"
+	if isAdmin {
+		actor = "admin"
+	}
+	details := fmt.Sprintf("%s refunded by %s %d in transaction %d: %s", amount, actor, actorID, reversal.Id, req.Reason)
+	// The money has already moved, so a failure here must not fail the request.
+	if err := t.logService.CreateAuditLog(int(id), "transaction", "reverse", details); err != nil {
+		logger.Log.Errorf("Failed to audit reversal of transaction %d: %v", id, err)
+	}
+
+	if t.cacheService != nil {
+		ctx := context.Background()
+		t.cacheService.Delete(ctx, t.cacheService.GenerateCacheKey("transaction", fmt.Sprintf("%d", id)))
+		for _, userID := range []*uint{original.FromUserId, original.ToUserId} {
+			if userID != nil {
+				t.invalidateUserTransactionCaches(ctx, *userID)
+			}
+		}
+	}
+
+	return t.GetTransactionByID(reversal.Id)
+}
+
 func (t *transactionService) invalidateUserTransactionCaches(ctx context.Context, userID uint) {
 	userTransactionsPattern := fmt.Sprintf("transactions:user:%d:*", userID)
 	t.cacheService.DeletePattern(ctx, userTransactionsPattern)
@@ -322,3 +417,30 @@ func (t *transactionService) invalidateUserTransactionCaches(ctx context.Context
 
 	logger.Log.Debug("Transaction caches invalidated for user", "userID", userID)
 }
+
+// checkTransfer rejects what TransferBetweenUsers rejects, and currencies that are not
+// supported.
+func checkTransfer(transfer repositories.Transfer) error {
+	if !transfer.Amount.IsPositive() {
+		return appErrors.NewBadRequest(nil, "amount must be positive")
+	}
+
+	if transfer.FromUserId == transfer.ToUserId {
+		return appErrors.NewBadRequest(nil, "cannot transfer to same user")
+	}
+
+	return requireSupportedCurrency(transfer.Amount)
+}
+
+func isParty(transaction *models.Transaction, userID uint) bool {
+	return (transaction.FromUserId != nil && *transaction.FromUserId == userID) ||
+		(transaction.ToUserId != nil && *transaction.ToUserId == userID)
+}
+
+func refundedAmount(transaction *models.Transaction) *money.Money {
+	if transaction.RefundedMinor == 0 {
+		return nil
+	}
+	refunded := money.New(transaction.RefundedMinor, transaction.Amount.Currency)
+	return &refunded
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/yusuffugurlu/go-project/config/logger"
@@ -22,8 +24,14 @@ type UserService interface {
 	CreateUser(user *models.User) (*models.User, error)
 	UpdateUser(id int, user *dtos.UpdateUserRequest) (*models.User, error)
 	DeleteUser(userId int) error
+	SetHandle(id int, handle string) (*models.User, error)
+	ResolveRecipient(to string) (*models.User, error)
+	PreviewRecipient(to string, senderID uint) (*dtos.RecipientPreviewResponse, error)
 }
 
+// handlePattern is what payment handles may look like once lower-cased and without the @.
+var handlePattern = regexp.MustCompile(`^[a-z0-9_.]{3,30}$`)
+
 type userService struct {
 	userRepo     repositories.UserRepository
 	logService   AuditLogService
@@ -234,6 +242,110 @@ func (u *userService) UpdateUser(id int, userData *dtos.UpdateUserRequest) (*mod
 	return existingUser, nil
 }
 
+// SetHandle sets the user's payment handle, which others can send money to. An empty handle
+// removes it.
+func (u *userService) SetHandle(id int, handle string) (*models.User, error) {
+	user, err := u.userRepo.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var newHandle *string
+	if handle = normalizeHandle(handle); handle != "" {
+		if !handlePattern.MatchString(handle) {
+			return nil, appErrors.NewBadRequest(nil, "a handle must be 3 to 30 letters, digits, dots or underscores")
+		}
+
+		owner, err := u.userRepo.GetByHandle(handle)
+		if err == nil && owner.Id != user.Id {
+			return nil, appErrors.NewConflict(nil, fmt.Sprintf("handle @%s is already taken", handle))
+		} else if err != nil && appErrors.GetStatusCode(err) != appErrors.ErrCodeNotFound {
+			return nil, err
+		}
+		newHandle = &handle
+	}
+
+	if err := u.userRepo.SetHandle(user.Id, newHandle); err != nil {
+		return nil, err
+	}
+	user.Handle = newHandle
+
+	if err := u.logService.CreateAuditLog(id, "user", "update", fmt.Sprintf("user %d changed their handle", id)); err != nil {
+		return nil, err
+	}
+
+	if u.cacheService != nil {
+		ctx := context.Background()
+		u.invalidateUserCache(ctx, id, user.Email)
+	}
+
+	return user, nil
+}
+
+// ResolveRecipient finds the user a transfer is addressed to: "@handle" is a payment handle,
+// anything else with an @ an email address, and the rest a username, or a handle if no user
+// has that username. A username shared by several users is rejected as ambiguous.
+func (u *userService) ResolveRecipient(to string) (*models.User, error) {
+	to = strings.TrimSpace(to)
+	notFound := appErrors.NewNotFound(nil, fmt.Sprintf("no user found for %q", to))
+
+	switch {
+	case to == "":
+		return nil, appErrors.NewBadRequest(nil, "a recipient is required")
+	case strings.HasPrefix(to, "@"):
+		user, err := u.userRepo.GetByHandle(normalizeHandle(to))
+		if err != nil && appErrors.GetStatusCode(err) == appErrors.ErrCodeNotFound {
+			return nil, notFound
+		}
+		return user, err
+	case strings.Contains(to, "@"):
+		user, err := u.GetUserByEmail(to)
+		if err != nil && appErrors.GetStatusCode(err) == appErrors.ErrCodeNotFound {
+			return nil, notFound
+		}
+		return user, err
+	}
+
+	users, err := u.userRepo.FindByUsername(to, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(users) {
+	case 1:
+		return &users[0], nil
+	case 0:
+		user, err := u.userRepo.GetByHandle(normalizeHandle(to))
+		if err != nil && appErrors.GetStatusCode(err) == appErrors.ErrCodeNotFound {
+			return nil, notFound
+		}
+		return user, err
+	default:
+		return nil, appErrors.NewConflict(nil, fmt.Sprintf("more than one user is called %q, use their email or payment handle", to))
+	}
+}
+
+// PreviewRecipient shows the masked name and email of the user a transfer addressed to to
+// would reach, so the sender can check it before sending.
+func (u *userService) PreviewRecipient(to string, senderID uint) (*dtos.RecipientPreviewResponse, error) {
+	user, err := u.ResolveRecipient(to)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.RecipientPreviewResponse{
+		To:    strings.TrimSpace(to),
+		Name:  maskName(user.Username),
+		Email: maskEmail(user.Email),
+		Self:  user.Id == senderID,
+	}
+	if user.Handle != nil {
+		response.Handle = "@" + *user.Handle
+	}
+
+	return response, nil
+}
+
 func (u *userService) invalidateUserCache(ctx context.Context, userID int, email string) {
 	userCacheKey := u.cacheService.GenerateCacheKey("user", fmt.Sprintf("%d", userID))
 	u.cacheService.Delete(ctx, userCacheKey)
@@ -246,3 +358,26 @@ func (u *userService) invalidateUserCache(ctx context.Context, userID int, email
 
 	logger.Log.Debug("User cache invalidated", "userID", userID, "email", email)
 }
+
+func normalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// maskName keeps the first letter of every word of name and masks the rest.
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
+
+// maskEmail keeps the first letter of the local part of an email address and its domain.
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return string([]rune(local)[0]) + "***@" + domain
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yusuffugurlu/go-project/internal/models"
+	"github.com/yusuffugurlu/go-project/internal/repositories"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+// stubUserRepository looks users up in memory the way userRepository does in the database.
+type stubUserRepository struct {
+	repositories.UserRepository
+	users []models.User
+}
+
+func (s *stubUserRepository) GetByEmail(email string) (*models.User, error) {
+	for i := range s.users {
+		if s.users[i].Email == email {
+			return &s.users[i], nil
+		}
+	}
+	return nil, appErrors.NewNotFound(nil, fmt.Sprintf("user with email %s not found", email))
+}
+
+func (s *stubUserRepository) GetByHandle(handle string) (*models.User, error) {
+	for i := range s.users {
+		if s.users[i].Handle != nil && *s.users[i].Handle == handle {
+			return &s.users[i], nil
+		}
+	}
+	return nil, appErrors.NewNotFound(nil, fmt.Sprintf("user with handle @%s not found", handle))
+}
+
+func (s *stubUserRepository) FindByUsername(username string, limit int) ([]models.User, error) {
+	var users []models.User
+	for _, user := range s.users {
+		if user.Username == username && len(users) < limit {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func TestResolveRecipient(t *testing.T) {
+	handle := func(handle string) *string { return &handle }
+	service := NewUserService(&stubUserRepository{users: []models.User{
+		{Id: 1, Username: "alice", Email: "alice@example.com", Handle: handle("alice_pay")},
+		{Id: 2, Username: "bob", Email: "bob@example.com"},
+		{Id: 3, Username: "sam", Email: "sam.one@example.com"},
+		{Id: 4, Username: "sam", Email: "sam.two@example.com", Handle: handle("sam")},
+		{Id: 5, Username: "carol", Email: "carol@example.com", Handle: handle("carol.b")},
+	}}, nil)
+
+	tests := []struct {
+		name     string
+		to       string
+		wantId   uint
+		wantCode int
+	}{
+		{name: "email", to: "bob@example.com", wantId: 2},
+		{name: "unknown email", to: "nobody@example.com", wantCode: appErrors.ErrCodeNotFound},
+		{name: "handle", to: "@alice_pay", wantId: 1},
+		{name: "handle in any case", to: "@Alice_Pay", wantId: 1},
+		{name: "unknown handle", to: "@nobody", wantCode: appErrors.ErrCodeNotFound},
+		{name: "username", to: "bob", wantId: 2},
+		{name: "surrounding spaces", to: "  bob ", wantId: 2},
+		{name: "handle without @", to: "carol.b", wantId: 5},
+		{name: "ambiguous username", to: "sam", wantCode: appErrors.ErrCodeConflict},
+		{name: "handle of an ambiguous username", to: "@sam", wantId: 4},
+		{name: "unknown username", to: "nobody", wantCode: appErrors.ErrCodeNotFound},
+		{name: "empty", to: "", wantCode: appErrors.ErrCodeBadRequest},
+		{name: "blank", to: "   ", wantCode: appErrors.ErrCodeBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := service.ResolveRecipient(tt.to)
+			if tt.wantCode != 0 {
+				if code := appErrors.GetStatusCode(err); code != tt.wantCode {
+					t.Fatalf("ResolveRecipient(%q) = %+v, %v, want status %d", tt.to, user, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveRecipient(%q) unexpected error: %v", tt.to, err)
+			}
+			if user.Id != tt.wantId {
+				t.Errorf("ResolveRecipient(%q) = user %d, want user %d", tt.to, user.Id, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "alice", want: "a****"},
+		{name: "Alice Smith", want: "A**** S****"},
+		{name: "Al", want: "A*"},
+		{name: "a", want: "a"},
+		{name: "J R R Tolkien", want: "J R R T******"},
+		{name: "Özgür Çelik", want: "Ö**** Ç****"},
+		{name: "李雷", want: "李*"},
+		{name: "  spaced   out ", want: "s***** o**"},
+		{name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskName(tt.name); got != tt.want {
+				t.Errorf("maskName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "alice@example.com", want: "a***@example.com"},
+		{email: "a@example.com", want: "a***@example.com"},
+		{email: "özgür@example.com.tr", want: "ö***@example.com.tr"},
+		{email: "alice@", want: "a***@"},
+		{email: "@example.com", want: "***"},
+		{email: "alice", want: "***"},
+		{email: "", want: "***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := maskEmail(tt.email); got != tt.want {
+				t.Errorf("maskEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
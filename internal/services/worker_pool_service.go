@@ -0,0 +1,53 @@
+package services
+
+import (
+	"github.com/yusuffugurlu/go-project/internal/dtos"
+	"github.com/yusuffugurlu/go-project/internal/process"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+)
+
+type WorkerPoolService interface {
+	GetStatus() *dtos.WorkerPoolResponse
+	Update(req dtos.UpdateWorkerPoolRequest) (*dtos.WorkerPoolResponse, error)
+}
+
+type workerPoolService struct {
+	workerPool *process.WorkerPool
+}
+
+func NewWorkerPoolService(workerPool *process.WorkerPool) WorkerPoolService {
+	return &workerPoolService{workerPool: workerPool}
+}
+
+func (w *workerPoolService) GetStatus() *dtos.WorkerPoolResponse {
+	status := w.workerPool.Status()
+
+	return &dtos.WorkerPoolResponse{
+		Workers:    status.Workers,
+		Busy:       status.Busy,
+		QueueDepth: status.QueueDepth,
+		Pacing:     status.Pacing.String(),
+		Autoscale:  status.Autoscale,
+		MinWorkers: status.MinWorkers,
+		MaxWorkers: status.MaxWorkers,
+	}
+}
+
+// Update applies the autoscale switch before the worker count, so both can be changed in one request.
+func (w *workerPoolService) Update(req dtos.UpdateWorkerPoolRequest) (*dtos.WorkerPoolResponse, error) {
+	if req.Workers == nil && req.Autoscale == nil {
+		return nil, appErrors.NewBadRequest(nil, "nothing to update, provide workers or autoscale")
+	}
+
+	if req.Autoscale != nil {
+		w.workerPool.SetAutoscale(*req.Autoscale)
+	}
+
+	if req.Workers != nil {
+		if err := w.workerPool.Resize(*req.Workers); err != nil {
+			return nil, err
+		}
+	}
+
+	return w.GetStatus(), nil
+}
@@ -100,6 +100,23 @@ func NewValidationError(err error, details interface{}) *AppError {
 	}
 }
 
+func NewUnprocessableEntity(err error, message string) *AppError {
+	return &AppError{
+		Code:    ErrCodeValidation,
+		Message: message,
+		Err:     err,
+	}
+}
+
+func NewUnprocessableEntityWithDetails(err error, message string, details interface{}) *AppError {
+	return &AppError{
+		Code:    ErrCodeValidation,
+		Message: message,
+		Details: details,
+		Err:     err,
+	}
+}
+
 func NewUnauthorized(err error, message string) *AppError {
 	if message == "" {
 		message = "Unauthorized access"
@@ -130,6 +147,17 @@ func NewInternalServerError(err error) *AppError {
 	}
 }
 
+func NewServiceUnavailable(err error, message string) *AppError {
+	if message == "" {
+		message = "Service temporarily unavailable"
+	}
+	return &AppError{
+		Code:    ErrCodeServiceUnavailable,
+		Message: message,
+		Err:     err,
+	}
+}
+
 func NewDatabaseError(err error, message string) *AppError {
 	if message == "" {
 		message = "Database operation failed"
@@ -46,6 +46,38 @@ var (
 		Name: "error_total",
 		Help: "Total number of errors",
 	})
+
+	JobQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "job_queue_depth",
+		Help: "Number of transaction jobs waiting in the queue",
+	})
+
+	JobProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "job_processing_duration_seconds",
+		Help:    "Time a worker spent processing a transaction job",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type"})
+
+	JobQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "job_queue_wait_seconds",
+		Help:    "Time between a transaction job being submitted and a worker picking it up",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+	}, []string{"type"})
+
+	WorkerPoolSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "worker_pool_size",
+		Help: "Number of running transaction workers",
+	})
+
+	WorkerPoolBusy = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "worker_pool_busy",
+		Help: "Number of transaction workers currently processing a job",
+	})
+
+	WorkerPoolUtilization = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "worker_pool_utilization",
+		Help: "Fraction of transaction workers currently processing a job",
+	})
 )
 
 func IncrementUserRegistration() {
@@ -79,3 +111,25 @@ func SetActiveUsers(count float64) {
 func IncrementError() {
 	ErrorRate.Inc()
 }
+
+func SetJobQueueDepth(depth float64) {
+	JobQueueDepth.Set(depth)
+}
+
+func ObserveJobProcessingDuration(jobType string, seconds float64) {
+	JobProcessingDuration.WithLabelValues(jobType).Observe(seconds)
+}
+
+func ObserveJobQueueWait(jobType string, seconds float64) {
+	JobQueueWait.WithLabelValues(jobType).Observe(seconds)
+}
+
+func SetWorkerPoolUsage(size, busy int) {
+	WorkerPoolSize.Set(float64(size))
+	WorkerPoolBusy.Set(float64(busy))
+	if size > 0 {
+		WorkerPoolUtilization.Set(float64(busy) / float64(size))
+	} else {
+		WorkerPoolUtilization.Set(0)
+	}
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/config/logger"
+	"github.com/yusuffugurlu/go-project/internal/cache"
+	appErrors "github.com/yusuffugurlu/go-project/pkg/errors"
+	"github.com/yusuffugurlu/go-project/pkg/jwt"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyProcessingLease bounds how long a key stays locked by a request that never
+	// finished (e.g. the instance crashed), so the client can retry it. The full TTL only
+	// applies once the final response is stored.
+	idempotencyProcessingLease = time.Minute
+
+	idempotencyStatusProcessing = "processing"
+	idempotencyStatusCompleted  = "completed"
+)
+
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      string `json:"status"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type IdempotencyMiddleware struct {
+	cacheService *cache.CacheService
+	ttl          time.Duration
+}
+
+func NewIdempotencyMiddleware(cacheService *cache.CacheService, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		cacheService: cacheService,
+		ttl:          ttl,
+	}
+}
+
+func (im *IdempotencyMiddleware) Handle() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			idempotencyKey := c.Request().Header.Get(IdempotencyKeyHeader)
+			if idempotencyKey == "" {
+				return next(c)
+			}
+
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				return appErrors.NewBadRequest(nil, fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return appErrors.NewBadRequest(err, "failed to read request body")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			fingerprint := requestFingerprint(c, body)
+			key := im.cacheService.GenerateCacheKey("idempotency", fmt.Sprintf("%s:%s", idempotencyScope(c), idempotencyKey))
+
+			ctx := context.Background()
+			acquired, err := im.cacheService.SetJSONNX(ctx, key, idempotencyRecord{
+				Fingerprint: fingerprint,
+				Status:      idempotencyStatusProcessing,
+			}, min(idempotencyProcessingLease, im.ttl))
+			if err != nil {
+				return appErrors.NewInternalServerError(err)
+			}
+
+			if !acquired {
+				return im.replay(c, key, fingerprint)
+			}
+
+			rec := &responseRecorder{
+				ResponseWriter: c.Response().Writer,
+				body:           make([]byte, 0),
+			}
+			c.Response().Writer = rec
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				// Server errors are not final, so the client may retry with the same key.
+				if err := im.cacheService.Delete(ctx, key); err != nil {
+					logger.Log.Error("Failed to release idempotency key", err)
+				}
+				return nil
+			}
+
+			if err := im.cacheService.SetJSON(ctx, key, idempotencyRecord{
+				Fingerprint: fingerprint,
+				Status:      idempotencyStatusCompleted,
+				StatusCode:  status,
+				Body:        rec.body,
+			}, im.ttl); err != nil {
+				logger.Log.Error("Failed to store idempotent response", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+func (im *IdempotencyMiddleware) replay(c echo.Context, key string, fingerprint string) error {
+	var record idempotencyRecord
+	if err := im.cacheService.GetJSON(context.Background(), key, &record); err != nil {
+		return appErrors.NewConflict(err, "request with this idempotency key is being processed, please retry")
+	}
+
+	if record.Fingerprint != fingerprint {
+		return appErrors.NewUnprocessableEntity(nil, fmt.Sprintf("%s was already used with a different request", IdempotencyKeyHeader))
+	}
+
+	if record.Status != idempotencyStatusCompleted {
+		return appErrors.NewConflict(nil, "request with this idempotency key is being processed, please retry")
+	}
+
+	c.Response().Header().Set(IdempotencyReplayedHeader, "true")
+	return c.Blob(record.StatusCode, echo.MIMEApplicationJSONCharsetUTF8, record.Body)
+}
+
+func idempotencyScope(c echo.Context) string {
+	if userClaims, ok := c.Get("user").(*jwt.UserClaims); ok {
+		return fmt.Sprintf("user:%d", userClaims.Id)
+	}
+	return fmt.Sprintf("ip:%s", c.RealIP())
+}
+
+func requestFingerprint(c echo.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request().Method))
+	hash.Write([]byte(c.Request().URL.Path))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
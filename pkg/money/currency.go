@@ -0,0 +1,105 @@
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// symbols are the display symbols of common currencies; other currencies are shown with
+// their ISO 4217 code.
+var symbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"TRY": "₺",
+}
+
+var (
+	supportedMu sync.RWMutex
+	supported   = map[string]bool{"USD": true, "EUR": true, "TRY": true}
+)
+
+// SetSupportedCurrencies replaces the currencies accounts can be held and moved in.
+func SetSupportedCurrencies(currencies []string) error {
+	set := make(map[string]bool, len(currencies))
+	for _, currency := range currencies {
+		currency = normalizeCurrency(currency)
+		if !ValidCurrency(currency) {
+			return fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+		}
+		set[currency] = true
+	}
+	if len(set) == 0 {
+		return fmt.Errorf("%w: at least one currency must be supported", ErrInvalidCurrency)
+	}
+
+	supportedMu.Lock()
+	supported = set
+	supportedMu.Unlock()
+	return nil
+}
+
+func Supported(currency string) bool {
+	supportedMu.RLock()
+	defer supportedMu.RUnlock()
+	return supported[normalizeCurrency(currency)]
+}
+
+// SupportedCurrencies returns the supported currency codes in alphabetical order.
+func SupportedCurrencies() []string {
+	supportedMu.RLock()
+	defer supportedMu.RUnlock()
+
+	currencies := make([]string, 0, len(supported))
+	for currency := range supported {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// Format renders the amount for display with thousands separators and the currency symbol,
+// e.g. "$1,234.50" or "-€3.00". Currencies without a symbol get their code appended, as in
+// "1,000.000 KWD".
+func (m Money) Format() string {
+	currency := normalizeCurrency(m.Currency)
+	plain := Money{Minor: m.Minor, Currency: currency}.String()
+
+	sign := ""
+	if strings.HasPrefix(plain, "-") {
+		sign, plain = "-", plain[1:]
+	}
+
+	whole, fraction, hasFraction := strings.Cut(plain, ".")
+	grouped := groupThousands(whole)
+	if hasFraction {
+		grouped += "." + fraction
+	}
+
+	if symbol, ok := symbols[currency]; ok {
+		return sign + symbol + grouped
+	}
+	return sign + grouped + " " + currency
+}
+
+func groupThousands(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	lead := len(digits) % 3
+	if lead > 0 {
+		b.WriteString(digits[:lead])
+	}
+	for i := lead; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
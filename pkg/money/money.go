@@ -0,0 +1,313 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "USD"
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrOverflow         = errors.New("amount overflow")
+)
+
+// minorUnits lists currencies whose minor unit exponent differs from the ISO 4217 default of 2.
+var minorUnits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+// Money is an exact monetary amount stored as an integer number of minor units (e.g. cents).
+type Money struct {
+	Minor    int64  `gorm:"not null;default:0"`
+	Currency string `gorm:"type:varchar(3);not null;default:'USD'"`
+}
+
+func New(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: normalizeCurrency(currency)}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+func Exponent(currency string) int {
+	if exp, ok := minorUnits[normalizeCurrency(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+func ValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse converts a decimal string such as "12.34" into Money without going through binary floats.
+func Parse(value string, currency string) (Money, error) {
+	currency = normalizeCurrency(currency)
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Money{}, fmt.Errorf("%w: empty value", ErrInvalidAmount)
+	}
+
+	negative := false
+	switch value[0] {
+	case '-':
+		negative = true
+		value = value[1:]
+	case '+':
+		value = value[1:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(value, ".")
+	if whole == "" && (!hasFrac || frac == "") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	exp := Exponent(currency)
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > exp {
+		return Money{}, fmt.Errorf("%w: %s supports at most %d decimal places", ErrInvalidAmount, currency, exp)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrOverflow, value)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+func MustParse(value string, currency string) Money {
+	m, err := Parse(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) String() string {
+	exp := Exponent(m.Currency)
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(absUint(minor), 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Minor < 0
+}
+
+func (m Money) Negate() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+func (m Money) SameCurrency(other Money) bool {
+	return normalizeCurrency(m.Currency) == normalizeCurrency(other.Currency)
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.Minor + other.Minor
+	if (other.Minor > 0 && sum < m.Minor) || (other.Minor < 0 && sum > m.Minor) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{Minor: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(other.Negate())
+}
+
+// Cmp returns -1, 0 or 1 depending on whether m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Minor < other.Minor:
+		return -1, nil
+	case m.Minor > other.Minor:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) LessThan(other Money) (bool, error) {
+	cmp, err := m.Cmp(other)
+	return cmp < 0, err
+}
+
+type jsonMoney struct {
+	Value     string `json:"value"`
+	Currency  string `json:"currency"`
+	Formatted string `json:"formatted"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	normalized := Money{Minor: m.Minor, Currency: currency}
+	return json.Marshal(jsonMoney{Value: normalized.String(), Currency: currency, Formatted: normalized.Format()})
+}
+
+// UnmarshalJSON accepts {"value":"12.34","currency":"EUR"} as well as a bare string or
+// number, in which case the default currency is assumed. Numbers are parsed from their
+// literal text so no precision is lost.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var (
+		value    string
+		currency = DefaultCurrency
+	)
+
+	switch {
+	case len(data) > 0 && data[0] == '{':
+		var raw struct {
+			Value    json.RawMessage `json:"value"`
+			Currency string          `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if raw.Currency != "" {
+			currency = raw.Currency
+		}
+		v, err := rawDecimal(raw.Value)
+		if err != nil {
+			return err
+		}
+		value = v
+	default:
+		v, err := rawDecimal(data)
+		if err != nil {
+			return err
+		}
+		value = v
+	}
+
+	parsed, err := Parse(value, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+func (m Money) assertSameCurrency(other Money) error {
+	if !m.SameCurrency(other) {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func rawDecimal(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w: missing value", ErrInvalidAmount)
+	}
+
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidAmount, string(data))
+	}
+	if strings.ContainsAny(n.String(), "eE") {
+		return "", fmt.Errorf("%w: exponent notation is not supported", ErrInvalidAmount)
+	}
+	return n.String(), nil
+}
+
+func normalizeCurrency(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
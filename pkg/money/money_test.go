@@ -0,0 +1,190 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     Money
+		wantErr  error
+	}{
+		{name: "whole", value: "12", currency: "USD", want: Money{Minor: 1200, Currency: "USD"}},
+		{name: "fraction", value: "12.34", currency: "USD", want: Money{Minor: 1234, Currency: "USD"}},
+		{name: "short fraction", value: "12.3", currency: "EUR", want: Money{Minor: 1230, Currency: "EUR"}},
+		{name: "leading dot", value: ".5", currency: "USD", want: Money{Minor: 50, Currency: "USD"}},
+		{name: "trailing zeros", value: "1.2300", currency: "USD", want: Money{Minor: 123, Currency: "USD"}},
+		{name: "negative", value: "-0.01", currency: "USD", want: Money{Minor: -1, Currency: "USD"}},
+		{name: "plus sign", value: "+7", currency: "USD", want: Money{Minor: 700, Currency: "USD"}},
+		{name: "whitespace", value: " 3.50 ", currency: "usd", want: Money{Minor: 350, Currency: "USD"}},
+		{name: "default currency", value: "1", currency: "", want: Money{Minor: 100, Currency: "USD"}},
+		{name: "zero exponent", value: "1500", currency: "JPY", want: Money{Minor: 1500, Currency: "JPY"}},
+		{name: "three exponent", value: "1.234", currency: "KWD", want: Money{Minor: 1234, Currency: "KWD"}},
+		{name: "too many places", value: "1.234", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "fraction on zero exponent", value: "1.5", currency: "JPY", wantErr: ErrInvalidAmount},
+		{name: "empty", value: "", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "lone dot", value: ".", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "letters", value: "12a", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "exponent", value: "1e3", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "double sign", value: "--1", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "bad currency", value: "1", currency: "US", wantErr: ErrInvalidCurrency},
+		{name: "overflow", value: "92233720368547758.08", currency: "USD", wantErr: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.currency)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse(%q, %q) error = %v, want %v", tt.value, tt.currency, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q, %q) unexpected error: %v", tt.value, tt.currency, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q, %q) = %+v, want %+v", tt.value, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringAndFormat(t *testing.T) {
+	tests := []struct {
+		money      Money
+		wantString string
+		wantFormat string
+	}{
+		{money: New(0, "USD"), wantString: "0.00", wantFormat: "$0.00"},
+		{money: New(5, "USD"), wantString: "0.05", wantFormat: "$0.05"},
+		{money: New(-5, "EUR"), wantString: "-0.05", wantFormat: "-€0.05"},
+		{money: New(123450, "USD"), wantString: "1234.50", wantFormat: "$1,234.50"},
+		{money: New(123456789, "TRY"), wantString: "1234567.89", wantFormat: "₺1,234,567.89"},
+		{money: New(1500, "JPY"), wantString: "1500", wantFormat: "¥1,500"},
+		{money: New(1000000, "KWD"), wantString: "1000.000", wantFormat: "1,000.000 KWD"},
+		{money: New(math.MinInt64, "USD"), wantString: "-92233720368547758.08", wantFormat: "-$92,233,720,368,547,758.08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantString+" "+tt.money.Currency, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+			if got := tt.money.Format(); got != tt.wantFormat {
+				t.Errorf("Format() = %q, want %q", got, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		want    Money
+		wantErr error
+	}{
+		{name: "sum", a: New(150, "USD"), b: New(250, "USD"), want: New(400, "USD")},
+		{name: "negative", a: New(150, "USD"), b: New(-250, "USD"), want: New(-100, "USD")},
+		{name: "mismatch", a: New(1, "USD"), b: New(1, "EUR"), wantErr: ErrCurrencyMismatch},
+		{name: "overflow", a: New(math.MaxInt64, "USD"), b: New(1, "USD"), wantErr: ErrOverflow},
+		{name: "underflow", a: New(math.MinInt64, "USD"), b: New(-1, "USD"), wantErr: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Add() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Add() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Add() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: New(1234, "USD"), want: `{"value":"12.34","currency":"USD","formatted":"$12.34"}`},
+		{money: Money{Minor: 100}, want: `{"value":"1.00","currency":"USD","formatted":"$1.00"}`},
+		{money: New(-250000, "EUR"), want: `{"value":"-2500.00","currency":"EUR","formatted":"-€2,500.00"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := json.Marshal(tt.money)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Money
+		wantErr bool
+	}{
+		{name: "object string", data: `{"value":"12.34","currency":"EUR"}`, want: New(1234, "EUR")},
+		{name: "object number", data: `{"value":12.34,"currency":"eur"}`, want: New(1234, "EUR")},
+		{name: "object default currency", data: `{"value":"5"}`, want: New(500, "USD")},
+		{name: "bare string", data: `"0.10"`, want: New(10, "USD")},
+		{name: "bare number", data: `0.1`, want: New(10, "USD")},
+		{name: "large number keeps precision", data: `90071992547409.93`, want: New(9007199254740993, "USD")},
+		{name: "exponent", data: `1e2`, wantErr: true},
+		{name: "too many places", data: `"1.001"`, wantErr: true},
+		{name: "missing value", data: `{"currency":"USD"}`, wantErr: true},
+		{name: "boolean", data: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %+v, want error", tt.data, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) unexpected error: %v", tt.data, err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.data, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("null leaves value", func(t *testing.T) {
+		got := New(1, "USD")
+		if err := json.Unmarshal([]byte(`null`), &got); err != nil {
+			t.Fatalf("Unmarshal(null) unexpected error: %v", err)
+		}
+		if got != New(1, "USD") {
+			t.Errorf("Unmarshal(null) changed value to %+v", got)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds the search for the next match of an expression such as "0 0 30 2 *".
+const cronSearchYears = 5
+
+var cronShortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronField struct {
+	bits       uint64
+	restricted bool
+}
+
+func (f cronField) has(value int) bool {
+	return f.bits&(1<<uint(value)) != 0
+}
+
+// cron is a standard "minute hour day-of-month month day-of-week" expression. As in Vixie cron,
+// when both day fields are restricted a day matches if either of them does.
+type cron struct {
+	minute, hour, dayOfMonth, month, dayOfWeek cronField
+	loc                                        *time.Location
+}
+
+func parseCron(expr string, loc *time.Location) (*cron, error) {
+	if shortcut, ok := cronShortcuts[strings.ToLower(expr)]; ok {
+		expr = shortcut
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron expressions need 5 fields, got %d", ErrInvalidRule, len(fields))
+	}
+
+	c := &cron{loc: loc}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dayOfMonth, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dayOfWeek, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// Both 0 and 7 mean Sunday.
+	if c.dayOfWeek.has(7) {
+		c.dayOfWeek.bits |= 1
+	}
+
+	return c, nil
+}
+
+func parseCronField(field string, min, max int) (cronField, error) {
+	result := cronField{restricted: !strings.HasPrefix(field, "*")}
+
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed < 1 {
+				return cronField{}, fmt.Errorf("%w: invalid step in %q", ErrInvalidRule, item)
+			}
+			step = parsed
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(lowPart); err != nil {
+				return cronField{}, fmt.Errorf("%w: invalid value in %q", ErrInvalidRule, item)
+			}
+			if high, err = strconv.Atoi(highPart); err != nil {
+				return cronField{}, fmt.Errorf("%w: invalid value in %q", ErrInvalidRule, item)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return cronField{}, fmt.Errorf("%w: invalid value in %q", ErrInvalidRule, item)
+			}
+			low = value
+			if hasStep {
+				high = max
+			} else {
+				high = value
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return cronField{}, fmt.Errorf("%w: %q is outside %d-%d", ErrInvalidRule, item, min, max)
+		}
+
+		for value := low; value <= high; value += step {
+			result.bits |= 1 << uint(value)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *cron) Next(after time.Time) time.Time {
+	t := after.In(c.loc)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, c.loc)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !c.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+		case !c.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth.has(t.Day())
+	dayOfWeek := c.dayOfWeek.has(int(t.Weekday()))
+
+	switch {
+	case c.dayOfMonth.restricted && c.dayOfWeek.restricted:
+		return dayOfMonth || dayOfWeek
+	case c.dayOfMonth.restricted:
+		return dayOfMonth
+	case c.dayOfWeek.restricted:
+		return dayOfWeek
+	default:
+		return true
+	}
+}
@@ -0,0 +1,41 @@
+// Package recurrence evaluates recurrence rules for standing orders. It understands a subset
+// of iCalendar RRULEs (FREQ, INTERVAL, BYDAY for weekly and BYMONTHDAY for monthly rules) and
+// standard five-field cron expressions, including the @daily style shortcuts.
+package recurrence
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Schedule yields the occurrences of a recurrence rule.
+type Schedule interface {
+	// Next returns the first occurrence strictly after the given time, or the zero time if
+	// there is none.
+	Next(after time.Time) time.Time
+}
+
+// Parse parses an RRULE (with or without the "RRULE:" prefix) or a cron expression. RRULEs are
+// anchored at start, which also supplies the time of day and the time zone; cron expressions
+// are evaluated in the time zone of start.
+func Parse(expr string, start time.Time) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.Join(ErrInvalidRule, errors.New("empty rule"))
+	}
+
+	upper := strings.ToUpper(expr)
+	if strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"), start)
+	}
+
+	return parseCron(expr, start.Location())
+}
+
+// First returns the first occurrence at or after from.
+func First(schedule Schedule, from time.Time) time.Time {
+	return schedule.Next(from.Add(-time.Nanosecond))
+}
@@ -0,0 +1,170 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	istanbul := time.FixedZone("TRT", 3*60*60)
+
+	tests := []struct {
+		name  string
+		expr  string
+		start time.Time
+		after time.Time
+		want  []time.Time
+	}{
+		{
+			name: "cron weekdays", expr: "0 9 * * 1-5",
+			start: date(2024, 1, 5, 10, 0), after: date(2024, 1, 5, 10, 0),
+			want: []time.Time{date(2024, 1, 8, 9, 0), date(2024, 1, 9, 9, 0), date(2024, 1, 10, 9, 0)},
+		},
+		{
+			name: "cron shortcut", expr: "@monthly",
+			start: date(2024, 1, 1, 0, 0), after: date(2024, 1, 15, 12, 0),
+			want: []time.Time{date(2024, 2, 1, 0, 0), date(2024, 3, 1, 0, 0), date(2024, 4, 1, 0, 0)},
+		},
+		{
+			name: "cron step", expr: "*/15 * * * *",
+			start: date(2024, 1, 1, 0, 0), after: date(2024, 1, 1, 10, 7),
+			want: []time.Time{date(2024, 1, 1, 10, 15), date(2024, 1, 1, 10, 30), date(2024, 1, 1, 10, 45)},
+		},
+		{
+			name: "cron skips short months", expr: "0 0 31 * *",
+			start: date(2024, 1, 1, 0, 0), after: date(2024, 1, 31, 0, 0),
+			want: []time.Time{date(2024, 3, 31, 0, 0), date(2024, 5, 31, 0, 0), date(2024, 7, 31, 0, 0)},
+		},
+		{
+			name: "cron day of month or day of week", expr: "0 12 13 * 5",
+			start: date(2024, 1, 1, 0, 0), after: date(2024, 10, 10, 0, 0),
+			want: []time.Time{date(2024, 10, 11, 12, 0), date(2024, 10, 13, 12, 0), date(2024, 10, 18, 12, 0)},
+		},
+		{
+			name: "cron sunday as 7", expr: "0 0 * * 7",
+			start: date(2024, 1, 1, 0, 0), after: date(2024, 1, 1, 0, 0),
+			want: []time.Time{date(2024, 1, 7, 0, 0), date(2024, 1, 14, 0, 0), date(2024, 1, 21, 0, 0)},
+		},
+		{
+			name: "cron in start time zone", expr: "0 9 * * *",
+			start: time.Date(2024, 1, 1, 0, 0, 0, 0, istanbul), after: date(2024, 1, 1, 7, 0),
+			want: []time.Time{date(2024, 1, 2, 6, 0), date(2024, 1, 3, 6, 0), date(2024, 1, 4, 6, 0)},
+		},
+		{
+			name: "cron never matches", expr: "0 0 30 2 *",
+			start: date(2024, 1, 1, 0, 0), after: date(2024, 1, 1, 0, 0),
+			want: []time.Time{{}},
+		},
+		{
+			name: "daily interval", expr: "FREQ=DAILY;INTERVAL=2",
+			start: date(2024, 1, 1, 8, 30), after: date(2024, 1, 1, 8, 30),
+			want: []time.Time{date(2024, 1, 3, 8, 30), date(2024, 1, 5, 8, 30), date(2024, 1, 7, 8, 30)},
+		},
+		{
+			name: "daily before start", expr: "FREQ=DAILY",
+			start: date(2024, 1, 10, 9, 0), after: date(2024, 1, 1, 0, 0),
+			want: []time.Time{date(2024, 1, 10, 9, 0), date(2024, 1, 11, 9, 0), date(2024, 1, 12, 9, 0)},
+		},
+		{
+			name: "weekly by day", expr: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+			start: date(2024, 1, 1, 9, 0), after: date(2024, 1, 1, 9, 0),
+			want: []time.Time{date(2024, 1, 3, 9, 0), date(2024, 1, 8, 9, 0), date(2024, 1, 10, 9, 0)},
+		},
+		{
+			name: "every other week on start day", expr: "freq=weekly;interval=2",
+			start: date(2024, 1, 3, 10, 0), after: date(2024, 1, 3, 10, 0),
+			want: []time.Time{date(2024, 1, 17, 10, 0), date(2024, 1, 31, 10, 0), date(2024, 2, 14, 10, 0)},
+		},
+		{
+			name: "monthly on the 31st", expr: "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: date(2024, 1, 31, 0, 0), after: date(2024, 1, 31, 0, 0),
+			want: []time.Time{date(2024, 3, 31, 0, 0), date(2024, 5, 31, 0, 0), date(2024, 7, 31, 0, 0)},
+		},
+		{
+			name: "monthly on the last day", expr: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(2024, 1, 31, 0, 0), after: date(2024, 1, 31, 0, 0),
+			want: []time.Time{date(2024, 2, 29, 0, 0), date(2024, 3, 31, 0, 0), date(2024, 4, 30, 0, 0)},
+		},
+		{
+			name: "monthly long after start", expr: "FREQ=MONTHLY",
+			start: date(2024, 1, 15, 12, 0), after: date(2025, 6, 20, 0, 0),
+			want: []time.Time{date(2025, 7, 15, 12, 0), date(2025, 8, 15, 12, 0), date(2025, 9, 15, 12, 0)},
+		},
+		{
+			name: "yearly on leap day", expr: "FREQ=YEARLY",
+			start: date(2024, 2, 29, 0, 0), after: date(2024, 2, 29, 0, 0),
+			want: []time.Time{date(2028, 2, 29, 0, 0), date(2032, 2, 29, 0, 0), date(2036, 2, 29, 0, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr, tt.start)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.expr, err)
+			}
+
+			after := tt.after
+			for i, want := range tt.want {
+				got := schedule.Next(after)
+				if !got.Equal(want) {
+					t.Fatalf("occurrence %d = %s, want %s", i, got, want)
+				}
+				after = got
+			}
+		})
+	}
+}
+
+func TestFirst(t *testing.T) {
+	schedule, err := Parse("0 9 * * *", date(2024, 1, 1, 0, 0))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if got, want := First(schedule, date(2024, 1, 1, 9, 0)), date(2024, 1, 1, 9, 0); !got.Equal(want) {
+		t.Errorf("First() on an occurrence = %s, want %s", got, want)
+	}
+	if got, want := First(schedule, date(2024, 1, 1, 9, 1)), date(2024, 1, 2, 9, 0); !got.Equal(want) {
+		t.Errorf("First() after an occurrence = %s, want %s", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"0 0 * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ=DAILY;UNTIL=20250101T000000Z",
+		"FREQ=DAILY;WKST=MO",
+		"FREQ=DAILY;INTERVAL",
+		"RRULE:INTERVAL=2",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr, date(2024, 1, 1, 0, 0)); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Parse(%q) error = %v, want %v", expr, err, ErrInvalidRule)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	freqDaily   = "DAILY"
+	freqWeekly  = "WEEKLY"
+	freqMonthly = "MONTHLY"
+	freqYearly  = "YEARLY"
+
+	// maxPeriods bounds the search for the next occurrence, e.g. for a rule on the 31st of
+	// every other month.
+	maxPeriods = 1000
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type rrule struct {
+	freq       string
+	interval   int
+	byDay      []time.Weekday
+	byMonthDay []int
+	start      time.Time
+}
+
+func parseRRule(expr string, start time.Time) (*rrule, error) {
+	rule := &rrule{interval: 1, start: start}
+
+	for _, part := range strings.Split(expr, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not a KEY=VALUE pair", ErrInvalidRule, part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case freqDaily, freqWeekly, freqMonthly, freqYearly:
+				rule.freq = value
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRule)
+			}
+			rule.interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported BYDAY value %q", ErrInvalidRule, day)
+				}
+				rule.byDay = append(rule.byDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("%w: invalid BYMONTHDAY value %q", ErrInvalidRule, day)
+				}
+				rule.byMonthDay = append(rule.byMonthDay, monthDay)
+			}
+		case "COUNT", "UNTIL":
+			return nil, fmt.Errorf("%w: %s is not supported, set the occurrence limit or end date on the order instead", ErrInvalidRule, key)
+		default:
+			return nil, fmt.Errorf("%w: unsupported rule part %s", ErrInvalidRule, key)
+		}
+	}
+
+	if rule.freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if len(rule.byDay) > 0 && rule.freq != freqWeekly {
+		return nil, fmt.Errorf("%w: BYDAY is only supported for weekly rules", ErrInvalidRule)
+	}
+	if len(rule.byMonthDay) > 0 && rule.freq != freqMonthly {
+		return nil, fmt.Errorf("%w: BYMONTHDAY is only supported for monthly rules", ErrInvalidRule)
+	}
+
+	if rule.freq == freqWeekly && len(rule.byDay) == 0 {
+		rule.byDay = []time.Weekday{start.Weekday()}
+	}
+	if rule.freq == freqMonthly && len(rule.byMonthDay) == 0 {
+		rule.byMonthDay = []int{start.Day()}
+	}
+
+	return rule, nil
+}
+
+func (r *rrule) Next(after time.Time) time.Time {
+	first := r.firstPeriod(after)
+	for period := first; period < first+maxPeriods; period++ {
+		for _, candidate := range r.occurrences(period) {
+			if candidate.After(after) && !candidate.Before(r.start) {
+				return candidate
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// firstPeriod estimates the index of the period containing after, erring on the early side.
+func (r *rrule) firstPeriod(after time.Time) int {
+	after = after.In(r.start.Location())
+	if !after.After(r.start) {
+		return 0
+	}
+
+	var periods int
+	switch r.freq {
+	case freqDaily:
+		periods = int(after.Sub(r.start).Hours()/24) / r.interval
+	case freqWeekly:
+		periods = int(after.Sub(r.start).Hours()/24/7) / r.interval
+	case freqMonthly:
+		months := (after.Year()-r.start.Year())*12 + int(after.Month()-r.start.Month())
+		periods = months / r.interval
+	case freqYearly:
+		periods = (after.Year() - r.start.Year()) / r.interval
+	}
+
+	if periods > 0 {
+		periods--
+	}
+	return periods
+}
+
+// occurrences returns the occurrences of the given period in chronological order.
+func (r *rrule) occurrences(period int) []time.Time {
+	start := r.start
+	hour, minute, second := start.Clock()
+	loc := start.Location()
+	step := period * r.interval
+
+	switch r.freq {
+	case freqDaily:
+		return []time.Time{start.AddDate(0, 0, step)}
+
+	case freqWeekly:
+		// Weeks start on Monday.
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := time.Date(start.Year(), start.Month(), start.Day()-offset+7*step, hour, minute, second, 0, loc)
+
+		occurrences := make([]time.Time, 0, len(r.byDay))
+		for _, day := range r.byDay {
+			occurrences = append(occurrences, monday.AddDate(0, 0, (int(day)+6)%7))
+		}
+		sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+		return occurrences
+
+	case freqMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, hour, minute, second, 0, loc)
+		daysInMonth := first.AddDate(0, 1, -1).Day()
+
+		occurrences := make([]time.Time, 0, len(r.byMonthDay))
+		for _, monthDay := range r.byMonthDay {
+			day := monthDay
+			if day < 0 {
+				day = daysInMonth + day + 1
+			}
+			// Days that do not exist in this month are skipped, as in RFC 5545.
+			if day < 1 || day > daysInMonth {
+				continue
+			}
+			occurrences = append(occurrences, first.AddDate(0, 0, day-1))
+		}
+		sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+		return occurrences
+
+	case freqYearly:
+		occurrence := time.Date(start.Year()+step, start.Month(), start.Day(), hour, minute, second, 0, loc)
+		// February 29th only occurs in leap years.
+		if occurrence.Day() != start.Day() {
+			return nil
+		}
+		return []time.Time{occurrence}
+	}
+
+	return nil
+}
@@ -64,6 +64,13 @@ func Created(c echo.Context, data interface{}) error {
 	return Success(c, http.StatusCreated, data)
 }
 
+func Accepted(c echo.Context, location string, data interface{}) error {
+	if location != "" {
+		c.Response().Header().Set(echo.HeaderLocation, location)
+	}
+	return Success(c, http.StatusAccepted, data)
+}
+
 func NoContent(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
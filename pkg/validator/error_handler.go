@@ -34,6 +34,12 @@ func getValidationErrorMessage(e validator.FieldError) string {
 		return "Value is less than minimum"
 	case "max":
 		return "Value is greater than maximum"
+	case "gt":
+		return "Value must be greater than " + e.Param()
+	case "iso4217":
+		return "Invalid currency code"
+	case "oneof":
+		return "Value must be one of: " + e.Param()
 	default:
 		return "Invalid value"
 	}
@@ -1,8 +1,11 @@
 package validator
 
 import (
+	"reflect"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/yusuffugurlu/go-project/pkg/money"
 )
 
 type CustomValidator struct {
@@ -10,7 +13,10 @@ type CustomValidator struct {
 }
 
 func New() *CustomValidator {
-	return &CustomValidator{validator: validator.New()}
+	v := validator.New()
+	v.RegisterCustomTypeFunc(moneyMinorUnits, money.Money{})
+
+	return &CustomValidator{validator: v}
 }
 
 func (cv *CustomValidator) Validate(i interface{}) error {
@@ -19,4 +25,12 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 
 func RegisterValidator(e *echo.Echo) {
 	e.Validator = New()
-}
\ No newline at end of file
+}
+
+// moneyMinorUnits lets numeric tags such as gt=0 apply to money.Money fields.
+func moneyMinorUnits(field reflect.Value) interface{} {
+	if m, ok := field.Interface().(money.Money); ok {
+		return m.Minor
+	}
+	return nil
+}